@@ -0,0 +1,72 @@
+package assignment
+
+import "sort"
+
+// Policies for picking the connector of a reservation that only names a chargepoint or site
+const (
+	// LeastUsed picks the connector with the fewest recent sessions, balancing usage day to day
+	LeastUsed = "LeastUsed"
+	// HighestPower picks the most powerful connector, the least used one among equally powerful ones
+	HighestPower = "HighestPower"
+	// SpreadWear picks the connector that delivered the least energy over its lifetime, so hardware ages evenly
+	SpreadWear = "SpreadWear"
+)
+
+// Candidate is an available connector that meets the reservation's constraints
+type Candidate struct {
+	Chargepoint string
+	Connector   int
+	// In kW
+	MaxPower float64
+	// Sessions charged on the connector recently
+	RecentSessions int
+	// Energy the connector delivered over its lifetime, in kWh
+	TotalEnergy float64
+}
+
+// Policy tells whether connector a is a better pick than connector b
+type Policy func(a, b Candidate) bool
+
+var policies = map[string]Policy{
+	LeastUsed: func(a, b Candidate) bool {
+		if a.RecentSessions != b.RecentSessions {
+			return a.RecentSessions < b.RecentSessions
+		}
+		return a.TotalEnergy < b.TotalEnergy
+	},
+	HighestPower: func(a, b Candidate) bool {
+		if a.MaxPower != b.MaxPower {
+			return a.MaxPower > b.MaxPower
+		}
+		return a.RecentSessions < b.RecentSessions
+	},
+	SpreadWear: func(a, b Candidate) bool {
+		if a.TotalEnergy != b.TotalEnergy {
+			return a.TotalEnergy < b.TotalEnergy
+		}
+		return a.RecentSessions < b.RecentSessions
+	},
+}
+
+// Register adds a policy under the name, replacing any policy already registered with it. Policies should be registered before the server starts.
+func Register(name string, policy Policy) {
+	policies[name] = policy
+}
+
+// Valid tells whether a policy is registered under the name
+func Valid(name string) bool {
+	_, ok := policies[name]
+	return ok
+}
+
+// Rank orders the candidates from the best pick to the worst. Candidates the policy can't tell apart keep their order. Unknown policies fall back to LeastUsed.
+func Rank(name string, candidates []Candidate) []Candidate {
+	better, ok := policies[name]
+	if !ok {
+		better = policies[LeastUsed]
+	}
+
+	ranked := append([]Candidate{}, candidates...)
+	sort.SliceStable(ranked, func(i, j int) bool { return better(ranked[i], ranked[j]) })
+	return ranked
+}
@@ -0,0 +1,43 @@
+package assignment
+
+import "testing"
+
+func TestRank(t *testing.T) {
+	candidates := []Candidate{
+		{Chargepoint: "busy", Connector: 1, MaxPower: 22, RecentSessions: 9, TotalEnergy: 100},
+		{Chargepoint: "fast", Connector: 1, MaxPower: 50, RecentSessions: 4, TotalEnergy: 900},
+		{Chargepoint: "quiet", Connector: 2, MaxPower: 11, RecentSessions: 1, TotalEnergy: 500},
+	}
+
+	tests := []struct {
+		name   string
+		policy string
+		first  string
+	}{
+		{name: "LeastUsed", policy: LeastUsed, first: "quiet"},
+		{name: "HighestPower", policy: HighestPower, first: "fast"},
+		{name: "SpreadWear", policy: SpreadWear, first: "busy"},
+		{name: "Unknown", policy: "Random", first: "quiet"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ranked := Rank(test.policy, candidates)
+			if len(ranked) != len(candidates) || ranked[0].Chargepoint != test.first {
+				t.Errorf("Expected %s to be picked first, but received %v", test.first, ranked)
+			}
+		})
+	}
+
+	t.Run("Register", func(t *testing.T) {
+		Register("LowestPower", func(a, b Candidate) bool { return a.MaxPower < b.MaxPower })
+
+		if !Valid("LowestPower") || Valid("Random") {
+			t.Fatalf("Expected only registered policies to be valid")
+		}
+
+		if ranked := Rank("LowestPower", candidates); ranked[0].Chargepoint != "quiet" || ranked[2].Chargepoint != "fast" {
+			t.Errorf("Expected the registered policy to be used, but received %v", ranked)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package availability
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Interval is a period of time, from Start up to End
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Overlaps tells whether the intervals share any time
+func (i Interval) Overlaps(other Interval) bool {
+	return i.Start.Before(other.End) && other.Start.Before(i.End)
+}
+
+// Free returns the parts of the window that none of the busy intervals cover, in order, leaving out those shorter than the minimum
+func Free(window Interval, busy []Interval, minimum time.Duration) []Interval {
+	sorted := append([]Interval{}, busy...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start.Before(sorted[j].Start) })
+
+	free := []Interval{}
+	start := window.Start
+	for _, interval := range sorted {
+		if !interval.End.After(start) {
+			continue
+		}
+		if !interval.Start.Before(window.End) {
+			break
+		}
+
+		if interval.Start.After(start) && interval.Start.Sub(start) >= minimum {
+			free = append(free, Interval{Start: start, End: interval.Start})
+		}
+		start = interval.End
+	}
+
+	if window.End.After(start) && window.End.Sub(start) >= minimum {
+		free = append(free, Interval{Start: start, End: window.End})
+	}
+
+	return free
+}
+
+// Distance is the great-circle distance between two points given in degrees, in km
+func Distance(latitude1, longitude1, latitude2, longitude2 float64) float64 {
+	const earthRadius = 6371.0
+
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+	dLatitude := toRadians(latitude2 - latitude1)
+	dLongitude := toRadians(longitude2 - longitude1)
+
+	a := math.Sin(dLatitude/2)*math.Sin(dLatitude/2) + math.Cos(toRadians(latitude1))*math.Cos(toRadians(latitude2))*math.Sin(dLongitude/2)*math.Sin(dLongitude/2)
+	return earthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,49 @@
+package availability
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFree(t *testing.T) {
+	start := time.Date(2023, 6, 1, 17, 0, 0, 0, time.Local)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	window := Interval{Start: at(0), End: at(120)}
+
+	tests := []struct {
+		name    string
+		busy    []Interval
+		minimum time.Duration
+		free    []Interval
+	}{
+		{name: "Empty", busy: nil, free: []Interval{window}},
+		{name: "OutsideWindow", busy: []Interval{{Start: at(-60), End: at(-10)}, {Start: at(130), End: at(200)}}, free: []Interval{window}},
+		{name: "Overlapping", busy: []Interval{{Start: at(60), End: at(90)}, {Start: at(-30), End: at(20)}, {Start: at(70), End: at(100)}}, free: []Interval{{Start: at(20), End: at(60)}, {Start: at(100), End: at(120)}}},
+		{name: "TooShort", busy: []Interval{{Start: at(10), End: at(100)}}, minimum: 30 * time.Minute, free: []Interval{}},
+		{name: "Covered", busy: []Interval{{Start: at(-10), End: at(150)}}, free: []Interval{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			free := Free(window, test.busy, test.minimum)
+			if len(free) != len(test.free) {
+				t.Fatalf("Expected %d free intervals, but received %v", len(test.free), free)
+			}
+
+			for i := range free {
+				if !free[i].Start.Equal(test.free[i].Start) || !free[i].End.Equal(test.free[i].End) {
+					t.Errorf("Expected %v, but received %v", test.free[i], free[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDistance(t *testing.T) {
+	// Amsterdam Centraal to Utrecht Centraal
+	distance := Distance(52.3791, 4.9003, 52.0894, 5.1101)
+	if math.Abs(distance-35.2) > 0.5 {
+		t.Errorf("Expected about 35.2 km, but received %.1f", distance)
+	}
+}
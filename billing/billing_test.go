@@ -0,0 +1,127 @@
+package billing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reservations/models"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testInvoice() models.Invoice {
+	invoice := models.Invoice{
+		Number:      "INV-2026-000001",
+		UserID:      "billedUser",
+		PeriodStart: time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Currency:    "EUR",
+		TaxRate:     21,
+		IssuedAt:    time.Date(2026, 10, 1, 3, 0, 0, 0, time.UTC),
+		Lines: []models.InvoiceLine{
+			{Date: time.Date(2026, 9, 3, 14, 0, 0, 0, time.UTC), Description: "Charging time (Standard), chargepoint (1)", Quantity: 45, Unit: "minute", UnitPrice: 10, Amount: 450},
+			{Date: time.Date(2026, 9, 3, 14, 0, 0, 0, time.UTC), Description: "Energy (Standard), chargepoint (1)", Quantity: 20.5, Unit: "kWh", UnitPrice: 30, Amount: 615},
+			{Date: time.Date(2026, 9, 9, 8, 0, 0, 0, time.UTC), Description: "Reservation fee", Quantity: 1, Unit: "reservation", UnitPrice: 99, Amount: 99},
+		},
+	}
+	Total(&invoice)
+
+	return invoice
+}
+
+func TestTotal(t *testing.T) {
+	invoice := testInvoice()
+
+	if invoice.Lines[2].Number != 3 {
+		t.Errorf("Expected the lines to be numbered from 1, but the last one is %d", invoice.Lines[2].Number)
+	}
+
+	// 21% of 11.64 is 2.4444, rounded once over the subtotal
+	if invoice.Subtotal != 1164 || invoice.Tax != 244 || invoice.Total != 1408 {
+		t.Errorf("Expected a subtotal of 1164, tax of 244 and total of 1408, but received %d, %d and %d", invoice.Subtotal, invoice.Tax, invoice.Total)
+	}
+}
+
+func TestTotalPrepaid(t *testing.T) {
+	invoice := testInvoice()
+	invoice.Payments = []models.InvoiceLine{
+		{Date: time.Date(2026, 9, 3, 15, 0, 0, 0, time.UTC), Description: "Paid from wallet", Quantity: 1, Unit: "payment", UnitPrice: 1408, Amount: 1408},
+	}
+	Total(&invoice)
+
+	// Payments don't lower the taxed subtotal, only what is still due
+	if invoice.Subtotal != 1164 || invoice.Tax != 244 || invoice.Total != 1408 {
+		t.Errorf("Expected a subtotal of 1164, tax of 244 and total of 1408, but received %d, %d and %d", invoice.Subtotal, invoice.Tax, invoice.Total)
+	}
+
+	if invoice.Payments[0].Number != 1 || invoice.AmountPaid != 1408 || invoice.BalanceDue != 0 {
+		t.Errorf("Expected 1408 to be paid with nothing due, but received %d paid and %d due", invoice.AmountPaid, invoice.BalanceDue)
+	}
+
+	rendered, err := RenderCSV(invoice)
+	if err != nil {
+		t.Fatalf("Could not render the invoice:\n%v", err)
+	}
+
+	rows, _ := csv.NewReader(bytes.NewReader(rendered)).ReadAll()
+	if last := rows[len(rows)-1]; last[3] != "Balance due" || last[7] != "0.00" {
+		t.Errorf("Expected the last row to be the balance due of 0.00, but received %v", last)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	tests := map[int64]string{0: "0.00", 5: "0.05", 1408: "14.08", -250: "-2.50"}
+
+	for cents, expected := range tests {
+		if formatted := FormatAmount(cents); formatted != expected {
+			t.Errorf("Expected %d cents to be formatted as %s, but received %s", cents, expected, formatted)
+		}
+	}
+}
+
+func TestRenderCSV(t *testing.T) {
+	rendered, err := RenderCSV(testInvoice())
+	if err != nil {
+		t.Fatalf("Could not render the invoice:\n%v", err)
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(rendered)).ReadAll()
+	if err != nil {
+		t.Fatalf("Could not parse the rendered CSV:\n%v", err)
+	}
+
+	// A header, three lines, subtotal, tax and total
+	if len(rows) != 7 {
+		t.Fatalf("Expected 7 rows, but received %d", len(rows))
+	}
+
+	if rows[2][3] != "Energy (Standard), chargepoint (1)" || rows[2][4] != "20.5" || rows[2][7] != "6.15" {
+		t.Errorf("Expected the energy line to keep its description, quantity and amount, but received %v", rows[2])
+	}
+
+	if rows[6][3] != "Total" || rows[6][7] != "14.08" {
+		t.Errorf("Expected the last row to be the total of 14.08, but received %v", rows[6])
+	}
+}
+
+func TestRenderPDF(t *testing.T) {
+	invoice := testInvoice()
+	for len(invoice.Lines) < 100 {
+		invoice.Lines = append(invoice.Lines, invoice.Lines[0])
+	}
+	Total(&invoice)
+
+	rendered := string(RenderPDF(invoice))
+
+	if !strings.HasPrefix(rendered, "%PDF-1.4") || !strings.HasSuffix(rendered, "%%EOF\n") {
+		t.Errorf("Expected a PDF document, but received %q", rendered[:20])
+	}
+
+	if !strings.Contains(rendered, "/Count 2") {
+		t.Errorf("Expected 100 lines to need two pages")
+	}
+
+	if !strings.Contains(rendered, "INVOICE INV-2026-000001") || !strings.Contains(rendered, "chargepoint \\(1\\)") {
+		t.Errorf("Expected the invoice number and escaped line descriptions in the document")
+	}
+}
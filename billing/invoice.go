@@ -0,0 +1,25 @@
+package billing
+
+import (
+	"math"
+	"reservations/models"
+)
+
+// Total numbers the invoice's lines and payments and computes its subtotal, tax and total, and the balance due after the payments. Tax is rounded to the cent once, over the whole subtotal of the charges.
+func Total(invoice *models.Invoice) {
+	invoice.Subtotal = 0
+	for i := range invoice.Lines {
+		invoice.Lines[i].Number = i + 1
+		invoice.Subtotal += invoice.Lines[i].Amount
+	}
+
+	invoice.Tax = int64(math.Round(float64(invoice.Subtotal) * invoice.TaxRate / 100))
+	invoice.Total = invoice.Subtotal + invoice.Tax
+
+	invoice.AmountPaid = 0
+	for i := range invoice.Payments {
+		invoice.Payments[i].Number = i + 1
+		invoice.AmountPaid += invoice.Payments[i].Amount
+	}
+	invoice.BalanceDue = invoice.Total - invoice.AmountPaid
+}
@@ -0,0 +1,190 @@
+package billing
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"reservations/models"
+	"strconv"
+	"strings"
+)
+
+// FormatAmount formats cents like "12.34"
+func FormatAmount(cents int64) string {
+	sign := ""
+	if cents < 0 {
+		sign, cents = "-", -cents
+	}
+
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func billedTo(invoice models.Invoice) string {
+	if invoice.OrganizationID != "" {
+		return "Organization " + invoice.OrganizationID
+	}
+
+	return "User " + invoice.UserID
+}
+
+// RenderCSV writes one row per line item, followed by the subtotal, tax and total rows. Payments come after them with the amount paid and the balance due.
+func RenderCSV(invoice models.Invoice) ([]byte, error) {
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+
+	rows := [][]string{{"Invoice", "Line", "Date", "Description", "Quantity", "Unit", "Unit price", "Amount", "Currency"}}
+	for _, line := range invoice.Lines {
+		rows = append(rows, []string{
+			invoice.Number,
+			strconv.Itoa(line.Number),
+			line.Date.Format("2006-01-02 15:04"),
+			line.Description,
+			strconv.FormatFloat(line.Quantity, 'f', -1, 64),
+			line.Unit,
+			FormatAmount(line.UnitPrice),
+			FormatAmount(line.Amount),
+			invoice.Currency,
+		})
+	}
+
+	rows = append(rows,
+		[]string{invoice.Number, "", "", "Subtotal", "", "", "", FormatAmount(invoice.Subtotal), invoice.Currency},
+		[]string{invoice.Number, "", "", fmt.Sprintf("Tax (%s%%)", strconv.FormatFloat(invoice.TaxRate, 'f', -1, 64)), "", "", "", FormatAmount(invoice.Tax), invoice.Currency},
+		[]string{invoice.Number, "", "", "Total", "", "", "", FormatAmount(invoice.Total), invoice.Currency},
+	)
+
+	if len(invoice.Payments) > 0 {
+		for _, payment := range invoice.Payments {
+			rows = append(rows, []string{invoice.Number, "", payment.Date.Format("2006-01-02 15:04"), payment.Description, "", "", "", FormatAmount(payment.Amount), invoice.Currency})
+		}
+		rows = append(rows,
+			[]string{invoice.Number, "", "", "Amount paid", "", "", "", FormatAmount(invoice.AmountPaid), invoice.Currency},
+			[]string{invoice.Number, "", "", "Balance due", "", "", "", FormatAmount(invoice.BalanceDue), invoice.Currency},
+		)
+	}
+
+	if err := writer.WriteAll(rows); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// Lines of text that fit on an A4 page in 8 point Courier
+const pdfLinesPerPage = 60
+
+// pdfText escapes text for a PDF string literal. Only ASCII is kept, since the standard fonts don't cover the rest without an encoding.
+func pdfText(text string) string {
+	var builder strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			builder.WriteRune('\\')
+			builder.WriteRune(r)
+		case r >= 32 && r < 127:
+			builder.WriteRune(r)
+		default:
+			builder.WriteRune('?')
+		}
+	}
+
+	return builder.String()
+}
+
+func truncate(text string, length int) string {
+	if len(text) <= length {
+		return text
+	}
+
+	return text[:length-3] + "..."
+}
+
+// RenderPDF lays the invoice out as plain text on A4 pages. It only uses PDF's built-in Courier font, so it doesn't need any font files or external services.
+func RenderPDF(invoice models.Invoice) []byte {
+	text := []string{
+		"INVOICE " + invoice.Number,
+		"",
+		"Billed to: " + billedTo(invoice),
+		"Issued:    " + invoice.IssuedAt.Format("2006-01-02"),
+		"Period:    " + invoice.PeriodStart.Format("2006-01-02") + " - " + invoice.PeriodEnd.AddDate(0, 0, -1).Format("2006-01-02"),
+		"Currency:  " + invoice.Currency,
+		"",
+		fmt.Sprintf("%-4s %-10s %-44s %10s %10s %10s", "#", "Date", "Description", "Quantity", "Price", "Amount"),
+		strings.Repeat("-", 93),
+	}
+
+	for _, line := range invoice.Lines {
+		quantity := strconv.FormatFloat(line.Quantity, 'f', -1, 64) + " " + line.Unit
+		text = append(text, fmt.Sprintf("%-4d %-10s %-44s %10s %10s %10s", line.Number, line.Date.Format("2006-01-02"), truncate(line.Description, 44), truncate(quantity, 10), FormatAmount(line.UnitPrice), FormatAmount(line.Amount)))
+	}
+
+	text = append(text,
+		strings.Repeat("-", 93),
+		fmt.Sprintf("%82s %10s", "Subtotal", FormatAmount(invoice.Subtotal)),
+		fmt.Sprintf("%82s %10s", fmt.Sprintf("Tax (%s%%)", strconv.FormatFloat(invoice.TaxRate, 'f', -1, 64)), FormatAmount(invoice.Tax)),
+		fmt.Sprintf("%82s %10s", "Total", FormatAmount(invoice.Total)),
+	)
+
+	if len(invoice.Payments) > 0 {
+		text = append(text, "", "Payments", strings.Repeat("-", 93))
+		for _, payment := range invoice.Payments {
+			text = append(text, fmt.Sprintf("%-4d %-10s %-66s %10s", payment.Number, payment.Date.Format("2006-01-02"), truncate(payment.Description, 66), FormatAmount(payment.Amount)))
+		}
+		text = append(text,
+			strings.Repeat("-", 93),
+			fmt.Sprintf("%82s %10s", "Amount paid", FormatAmount(invoice.AmountPaid)),
+			fmt.Sprintf("%82s %10s", "Balance due", FormatAmount(invoice.BalanceDue)),
+		)
+	}
+
+	var pages [][]string
+	for len(text) > pdfLinesPerPage {
+		pages = append(pages, text[:pdfLinesPerPage])
+		text = text[pdfLinesPerPage:]
+	}
+	pages = append(pages, text)
+
+	// Objects 1 and 2 are the catalog and the page tree, 3 is the font, then every page is followed by its content stream
+	var objects []string
+	var kids []string
+	for i, page := range pages {
+		pageObject := 4 + i*2
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObject))
+
+		var content strings.Builder
+		content.WriteString("BT /F1 8 Tf 12 TL 40 800 Td\n")
+		for _, line := range page {
+			content.WriteString("(" + pdfText(line) + ") Tj T*\n")
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 595 842] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", pageObject+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+
+	objects = append([]string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier >>",
+	}, objects...)
+
+	var buffer bytes.Buffer
+	buffer.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = buffer.Len()
+		fmt.Fprintf(&buffer, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := buffer.Len()
+	fmt.Fprintf(&buffer, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buffer, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buffer, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return buffer.Bytes()
+}
@@ -0,0 +1,51 @@
+package bookingcode
+
+import (
+	"crypto/rand"
+	"math/big"
+	"strings"
+)
+
+// Alphabet leaves out 0, 1, I, L and O, which are easily mistaken for each other when read out or typed in
+const Alphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+
+// Length is the number of characters in a code, without the separator
+const Length = 6
+
+// New returns a random code like "7KQ-4MZ". There are about 887 million of them, so callers should still check that it isn't in use yet.
+func New() (string, error) {
+	code := make([]byte, Length)
+	for i := range code {
+		index, err := rand.Int(rand.Reader, big.NewInt(int64(len(Alphabet))))
+		if err != nil {
+			return "", err
+		}
+		code[i] = Alphabet[index.Int64()]
+	}
+
+	return format(string(code)), nil
+}
+
+// Normalize turns a code as typed in, like "7kq 4mz" or "7KQ4MZ", into the form New returns. It returns false when it can't be a code.
+func Normalize(input string) (string, bool) {
+	var code strings.Builder
+	for _, character := range strings.ToUpper(input) {
+		switch {
+		case character == '-' || character == ' ':
+			continue
+		case !strings.ContainsRune(Alphabet, character):
+			return "", false
+		}
+		code.WriteRune(character)
+	}
+
+	if code.Len() != Length {
+		return "", false
+	}
+
+	return format(code.String()), true
+}
+
+func format(code string) string {
+	return code[:Length/2] + "-" + code[Length/2:]
+}
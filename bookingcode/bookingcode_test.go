@@ -0,0 +1,51 @@
+package bookingcode
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		code, err := New()
+		if err != nil {
+			t.Fatalf("Unable to generate a code:\n%v", err)
+		}
+
+		normalized, ok := Normalize(code)
+		if !ok || normalized != code || len(code) != Length+1 || code[Length/2] != '-' {
+			t.Errorf("Expected a code like 7KQ-4MZ, but received %q", code)
+		}
+		seen[code] = true
+	}
+
+	if len(seen) < 99 {
+		t.Errorf("Expected the codes to be random, but only %d of 100 were different", len(seen))
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		input string
+		code  string
+		ok    bool
+	}{
+		{input: "7KQ-4MZ", code: "7KQ-4MZ", ok: true},
+		{input: "7kq4mz", code: "7KQ-4MZ", ok: true},
+		{input: " 7kq 4mz ", code: "7KQ-4MZ", ok: true},
+		{input: "7KQ-4M", ok: false},
+		{input: "7KQ-4MZZ", ok: false},
+		{input: "0KQ-4MZ", ok: false},
+		{input: "", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.TrimSpace(test.input), func(t *testing.T) {
+			code, ok := Normalize(test.input)
+			if ok != test.ok || code != test.code {
+				t.Errorf("Expected %q and %v, but received %q and %v", test.code, test.ok, code, ok)
+			}
+		})
+	}
+}
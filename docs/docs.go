@@ -16,6 +16,164 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/availability": {
+            "get": {
+                "description": "Lists when connectors are free between from and to (at most 7 days apart, the part of the window that already passed is left out), for example to fill a booking calendar. The search can be narrowed down to a chargepoint, a site, the sites within the radius of a location, a plug type and a minimum power. Connectors are taken by their reservations (including the buffer of the connector's reservation policy before and after them), their maintenance windows and connectors offered from a waitlist. Unavailable connectors, and connectors in use without a reservation, are taken for the whole window. Every matching connector is listed, those that aren't free for at least the minimum minutes have no slots. Results are ordered by distance when a location is given.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Search for free connectors in a time window",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Start of the window (RFC 3339)",
+                        "name": "from",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the window (RFC 3339)",
+                        "name": "to",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepoint",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "site",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Latitude of the location, in degrees",
+                        "name": "latitude",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Longitude of the location, in degrees",
+                        "name": "longitude",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Search radius around the location in km, 10 by default",
+                        "name": "radius",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Plug type",
+                        "name": "plugType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum power in kW",
+                        "name": "minPower",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Shortest slot in minutes, 30 by default",
+                        "name": "minutes",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ConnectorAvailability"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/cancel/{id}": {
+            "post": {
+                "description": "Operators can cancel any reservation that hasn't finished, for example when the connector breaks down. An active charging session is stopped, and the user gets back everything they paid for the reservation, from their wallet or card.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Cancel a reservation as an operator",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.OperatorCancelRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
         "/changestate/{chargepointID}/{connectorID}": {
             "post": {
                 "description": "Experimental feature that allows use of all the possible connector states. This is useful when debugging, but can create possible edge cases (Suggestion: only use it on \"Available\" connectors since those will never have a reservation). The body parameter \"state\" can be either \"Available\", \"Unavailable\", \"Charging\" or \"Reserved\". A possible use case for this endpoint would be maintenance on a connector, setting it to \"Unavailable\".",
@@ -78,7 +236,7 @@ const docTemplate = `{
         },
         "/charge/{chargepointID}/{connectorID}": {
             "post": {
-                "description": "For a user to begin charging, they need to have an open reservation for the chargepoint and connector. They need to connect in the 10 minute \"expiry\" time period (time of reservation + 10 minutes), otherwise the reservation ends. If the user does connect in time, then they charge for the remainder of the \"charging\" time period specified in the reservation.",
+                "description": "For a user to begin charging, they need to have an open reservation for the chargepoint and connector. They need to connect in the \"expiry\" time period (start of the reservation + the grace period of the connector's reservation policy, 10 minutes by default), otherwise the reservation ends. If the user does connect in time, then they charge for the remainder of the \"charging\" time period specified in the reservation, and the reservation's deposit is given back. Charging starts a charging session, which records what actually happened.\nUsers can also charge on an available connector without a reservation, for the requested minutes. The charging time is shortened so the vehicle leaves before someone else's later reservation on the connector (a reservation that started but whose user hasn't arrived yet counts too), minus the buffer of the connector's reservation policy, and charging is refused when that leaves less than 10 minutes. Charging is refused with a 409 while the connector is still in use, for instance by the vehicle before a reservation booked right after it. The user needs access to the connector like for a reservation: its reservation policy and hold, the user's quotas and organization limits, and no-show bans all apply. The estimated price is held on the user's wallet or pre-authorized on their card, like a reservation's.",
                 "consumes": [
                     "application/json"
                 ],
@@ -112,6 +270,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/endpoints.ChargeRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional, retries with the same key get the first response instead of handling the request again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -127,6 +291,30 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -198,6 +386,7 @@ const docTemplate = `{
                 }
             },
             "post": {
+                "description": "Connector details are optional. When given, the plug type can be \"Type1\", \"Type2\", \"CCS1\", \"CCS2\", \"CHAdeMO\", \"NACS\" or \"GBT\", the current type either \"AC\" or \"DC\", and the maximum power is in kW.",
                 "consumes": [
                     "application/json"
                 ],
@@ -224,6 +413,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/endpoints.CreateChargepointRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional, retries with the same key get the first response instead of handling the request again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -239,32 +434,16 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/reservations": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Reservations"
-                ],
-                "summary": "Get all reservations",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Reservation"
-                            }
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
@@ -276,8 +455,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/reservations/{chargepointID}/{connectorID}": {
+        "/chargepoints/{id}/holds": {
             "post": {
+                "description": "Only users of the priority class, or of a class with a higher rank, can reserve the connector for slots starting more than the release minutes later. Closer to the slot, anyone can reserve it. Without a priority class, the hold is removed.",
                 "consumes": [
                     "application/json"
                 ],
@@ -285,21 +465,14 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Reservations"
+                    "Operators"
                 ],
-                "summary": "Create a reservation",
+                "summary": "Hold a connector for a priority class",
                 "parameters": [
                     {
                         "type": "string",
                         "description": "Chargepoint ID",
-                        "name": "chargepointID",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Connector ID",
-                        "name": "connectorID",
+                        "name": "id",
                         "in": "path",
                         "required": true
                     },
@@ -309,7 +482,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/endpoints.ReservationRequest"
+                            "$ref": "#/definitions/endpoints.ConnectorHoldRequest"
                         }
                     }
                 ],
@@ -326,33 +499,11 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/users": {
-            "get": {
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "Users"
-                ],
-                "summary": "Get all users",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.User"
-                            }
-                        }
                     },
                     "500": {
                         "description": "Internal Server Error",
@@ -363,19 +514,20 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/chargepoints/{id}/maintenance": {
             "get": {
+                "description": "Lists the ongoing and upcoming maintenance of the chargepoint.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Operators"
                 ],
-                "summary": "Get information about a user by their ID",
+                "summary": "Get a chargepoint's maintenance windows",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Chargepoint ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -385,11 +537,14 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.User"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MaintenanceWindow"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
@@ -397,6 +552,7 @@ const docTemplate = `{
                 }
             },
             "post": {
+                "description": "Takes a connector, or all of the chargepoint's connectors when none is given, out of service for a period. Reservations can't be made during it and it doesn't show up as free in the availability search. Reservations that were already made for the period aren't cancelled, they're returned as warnings so they can be moved or cancelled.",
                 "consumes": [
                     "application/json"
                 ],
@@ -404,13 +560,13 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "Users"
+                    "Operators"
                 ],
-                "summary": "Create a new user",
+                "summary": "Schedule maintenance on a chargepoint",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "User ID",
+                        "description": "Chargepoint ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -421,7 +577,7 @@ const docTemplate = `{
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/endpoints.CreateUserRequest"
+                            "$ref": "#/definitions/endpoints.MaintenanceRequest"
                         }
                     }
                 ],
@@ -438,6 +594,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -446,133 +608,5578 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "endpoints.ChangeConnectorStateRequest": {
-            "type": "object",
-            "properties": {
-                "state": {
-                    "type": "string"
-                }
-            }
-        },
-        "endpoints.ChargeRequest": {
-            "type": "object",
-            "properties": {
-                "userId": {
-                    "type": "string"
-                }
-            }
-        },
-        "endpoints.CreateChargepointRequest": {
-            "type": "object",
-            "properties": {
-                "connectors": {
-                    "type": "integer"
-                }
-            }
-        },
-        "endpoints.CreateUserRequest": {
-            "type": "object",
-            "properties": {
-                "name": {
-                    "type": "string"
-                }
-            }
+        },
+        "/compensations": {
+            "get": {
+                "description": "Lists what is owed to users whose reservations were bumped by priority users, newest first. The user filter is optional.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Get compensations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Compensation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/fees": {
+            "get": {
+                "description": "Lists the no-show and idle fees applied by the background worker, newest first. The user and reservation filters are optional.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Fees"
+                ],
+                "summary": "Get fees",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "reservationId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Fee"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices": {
+            "get": {
+                "description": "Lists invoices, newest first. The user and organization filters are optional.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Get invoices",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organizationId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Invoice"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/run": {
+            "post": {
+                "description": "Issues invoices for a calendar month that has ended. With a user or organization, only that party is billed, otherwise every party with billable sessions or reservations is. Invoices bill completed sessions, the fees of reservations that were never charged on and the no-show and idle fees, with tax (TAX_RATE) added over the subtotal. What users paid from their wallet or by card in the month is listed below the total, followed by the amount paid and the balance due. Members of an organization are billed through it. Parties that were already invoiced for the month are skipped.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Run billing for a month",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.BillingRunRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Invoice"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Get an invoice by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Invoice"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/csv": {
+            "get": {
+                "produces": [
+                    "text/csv"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Download an invoice as CSV",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/invoices/{id}/pdf": {
+            "get": {
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "Invoices"
+                ],
+                "summary": "Download an invoice as PDF",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invoice ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/metervalues/{chargepointID}/{connectorID}": {
+            "post": {
+                "description": "Records periodic readings of the connector's active charging session, like the sampled values of an OCPP MeterValues message. Energy is the meter's register in Wh, power is in kW, the state of charge in percent and voltage in V. Readings without a timestamp are taken now. The highest energy reading becomes the session's last known meter value.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Push meter values of a connector",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Connector ID",
+                        "name": "connectorID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.MeterValuesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/noshows/{id}/forgive": {
+            "post": {
+                "description": "Forgiven no-shows no longer count towards the user's penalties or reliability score. Meant for operators, for example when the connector was broken.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Forgive a no-show",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "No-show ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.ForgiveNoShowRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get all organizations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Organization"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get information about an organization by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Organization"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Admins must be existing users, and they become members of the organization. Reserved capacity holds a number of a chargepoint's connectors for the organization's members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Create a new organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.CreateOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/members/{userID}": {
+            "post": {
+                "description": "Only the organization's admins can add members, and a user can only belong to one organization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Add a user to an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.OrganizationAdminRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/reservations": {
+            "get": {
+                "description": "Only the organization's admins can view its reservations.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get all reservations of an organization's members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin's user ID",
+                        "name": "adminId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Reservation"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{id}/sessions": {
+            "get": {
+                "description": "Only the organization's admins can view its sessions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Organizations"
+                ],
+                "summary": "Get all charging sessions of an organization's members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Admin's user ID",
+                        "name": "adminId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ChargingSession"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/payments/webhook": {
+            "post": {
+                "description": "Called by the payment gateway when a payment changes, signed with the webhook secret (in the Stripe-Signature header for Stripe, X-Signature for the fake gateway). Gateways can deliver an event more than once, events that were already processed are acknowledged without being applied again.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Receive a payment gateway webhook",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies": {
+            "post": {
+                "description": "Give either a site ID, a chargepoint ID, or a chargepoint ID with a connector ID. The fields a connector's policy sets take precedence over its chargepoint's, which take precedence over its site's, the ones left out are inherited, and 0 overrides like any other value. The combined policy of every connector the change reaches is checked, so a minimum above an inherited maximum is rejected. Connectors without any policy can be reserved for 30 to 180 minutes, up to BOOKING_HORIZON_DAYS ahead, with a 10 minute grace period, no buffer and by everyone. Without a policy, the existing one is removed. The grace period is locked in when a reservation is made.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Set the reservation policy of a site, chargepoint or connector",
+                "parameters": [
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.SetReservationPolicyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/policies/{chargepointID}/{connectorID}": {
+            "get": {
+                "description": "Combines the policies of the connector, its chargepoint and its site with the defaults, so every field is filled in.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Get the reservation policy that applies to a connector",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Connector ID",
+                        "name": "connectorID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ConnectorPolicy"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/priorityclasses": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Get all priority classes",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.PriorityClass"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/priorityclasses/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Get information about a priority class by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Priority class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.PriorityClass"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Priority classes rank users, for example emergency services above fleets. Members of a class that can bump may take the place of reservations of users with a lower rank that haven't started charging, as long as they start at least the bump notice later. A reservation that already holds its connector can be taken over once its user hasn't plugged in for the class' take-over minutes after it started. The bumped users are refunded, notified and owed the class' compensation (in cents). Connectors can also be held for a class, see POST /chargepoints/{id}/holds.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Create a new priority class",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Priority class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.CreatePriorityClassRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/priorityclasses/{id}/assign": {
+            "post": {
+                "description": "Give either a user ID or an organization ID. A user's own class takes precedence over their organization's. Reservations keep the class they were made with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Put a user or organization in a priority class",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Priority class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.AssignPriorityClassRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Give either a user ID or an organization ID. Nothing changes when they're in another class.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Operators"
+                ],
+                "summary": "Take a user or organization out of a priority class",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Priority class ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Organization ID",
+                        "name": "organizationId",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/quotes/{chargepointID}/{connectorID}": {
+            "post": {
+                "description": "Prices a reservation of the connector starting now, or at the start time when it's booked ahead, without making it. The energy (in kWh) is optional: when a vehicle is given instead, the energy is estimated from the power the vehicle can draw from the connector, capped at its battery capacity. The site's pricing policy adjustments are included as components, and are honoured when the same user makes a reservation with the same connector, start time and minutes with the quote's ID before it expires (after QUOTE_VALID_MINUTES). A quote can only be used for one reservation.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Estimate the price of a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Connector ID",
+                        "name": "connectorID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.QuoteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Quote"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Get all reservations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Reservation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/code/{code}": {
+            "get": {
+                "description": "For the cashier or a tablet at the site. The code can be typed in any case, with or without the dash.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Look up a reservation by its booking code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Booking code, like 7KQ-4MZ",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{chargepointID}": {
+            "post": {
+                "description": "Like POST /reservations/{chargepointID}/{connectorID}, but the server picks the connector: the best available one that has the plug type and at least the minimum power (and fits the vehicle, if given), according to the policy. \"LeastUsed\" picks the connector with the fewest sessions in the last 30 days, \"HighestPower\" the most powerful one and \"SpreadWear\" the one that delivered the least energy overall. Connectors that can't be reserved for the request, like ones booked during it, are skipped. Without a policy, ASSIGNMENT_POLICY is used. The chosen connector is returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Reserve any suitable connector of a chargepoint",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.AutoReservationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional, retries with the same key get the first response instead of handling the request again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{chargepointID}/{connectorID}": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Create a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Connector ID",
+                        "name": "connectorID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.ReservationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional, retries with the same key get the first response instead of handling the request again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Get information about a reservation by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reservation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Moves a reservation that hasn't started charging to another connector or chargepoint, or changes its start time or reservation time. Fields that are left out keep their value, a reservation that already started keeps its start unless it's moved to later. The new connector and time are checked like a new reservation's, and the change is all or nothing: when they can't be taken, the reservation stays as it was. The reservation is priced again for its new connector and time, and longer reservations count against the user's quotas, organization and no-show limits again. What the wallet holds or the card authorized for it is resized to the new estimated price. The old connector becomes available again once the reservation no longer holds it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Modify a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.ModifyReservationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Users can cancel their reservations until they start charging. The connector becomes available again and any amount held from the user's wallet is released.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Cancel a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/payment": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Payments"
+                ],
+                "summary": "Get the card payment of a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Payment"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/price": {
+            "get": {
+                "description": "Prices the reservation and its charging session under the tariff that applied when the reservation was made, with the pricing policy adjustments locked in then. Active sessions are priced up to now.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Get the price of a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Price"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/reservations/{id}/schedule": {
+            "get": {
+                "description": "Plans the power the connector should deliver from now (or the start of the reservation) until the reservation ends or the vehicle departs, whichever is first. The energy the vehicle still needs is charged when the tariff's energy price is lowest, without going over the connector's and vehicle's power or what's left of the site's capacity. Without a target energy, the schedule charges at full power. The schedule is shaped like an OCPP SetChargingProfile schedule with limits in W, so it can be pushed to chargers as-is.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Get the charging schedule of a reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChargingSchedule"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{chargepointID}/{connectorID}": {
+            "post": {
+                "description": "Reserves the connector repeatedly, following an iCalendar RRULE with a DAILY or WEEKLY frequency, an optional INTERVAL and BYDAY, and an UNTIL date or COUNT. For example \"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR\" reserves it every weekday at the time of the first occurrence. Occurrences are booked as ordinary reservations once they come within the booking horizon of the connector's reservation policy, each of them is checked on its own, and the series lists which ones couldn't be booked and why.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Create a recurring reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Connector ID",
+                        "name": "connectorID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.SeriesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReservationSeries"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}": {
+            "get": {
+                "description": "Includes every occurrence booked so far, with its reservation or why it couldn't be booked.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Get a recurring reservation by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReservationSeries"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Changes the rule, the start of the first occurrence or the reservation time of the whole series. The occurrences that haven't started yet are cancelled and booked again following the new series, past occurrences are kept. Fields that are left out keep their value.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Edit a recurring reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.UpdateSeriesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReservationSeries"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancels every occurrence that hasn't started yet, and stops booking new ones. A single occurrence is cancelled like any other reservation, with DELETE /reservations/{id}.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Cancel a recurring reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/series/{id}/occurrences/{reservationID}": {
+            "put": {
+                "description": "Moves a single occurrence that hasn't started yet to another time on the same connector, for example when the user leaves later one day, see PUT /reservations/{id}. The rest of the series is left as it is.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Move an occurrence of a recurring reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Series ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reservation ID of the occurrence",
+                        "name": "reservationID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.UpdateOccurrenceRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions": {
+            "get": {
+                "description": "Lists every charging session, newest first. The user, reservation and status filters are optional.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get charging sessions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Reservation ID",
+                        "name": "reservationId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Status (Charging or Completed)",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ChargingSession"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get information about a charging session by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ChargingSession"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/powercurve": {
+            "get": {
+                "description": "Lists the meter values pushed during the session, oldest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get the power curve of a charging session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MeterValue"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sessions/{id}/price": {
+            "get": {
+                "description": "Prices the session under the tariff that applied when its reservation was made. Active sessions are priced up to now.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Get the price of a charging session",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Session ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Price"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Get all sites",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Site"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Get information about a site by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Site"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "A site groups chargepoints at the same location. Chargepoints are added to a site when they are created.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Create a new site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.CreateSiteRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites/{id}/capacity": {
+            "post": {
+                "description": "Limits the power (in kW) the site's connectors can draw together, 0 removes the limit. The load manager shares the power between charging sessions with the strategy: \"EqualShare\" (the default) splits it evenly, \"Priority\" serves users with a higher priority first and \"FirstCome\" serves sessions in the order they started. Reservations are downgraded to the power that is left during their time, or refused when less than MIN_CHARGING_POWER is left.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Set a site's power capacity",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.SiteCapacityRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites/{id}/load": {
+            "get": {
+                "description": "Shares the site's capacity between its charging sessions with its load strategy. Every connector gets a power limit in kW, connectors that aren't charging get none. A session can't draw more than its connector's maximum power, or the power its reservation was downgraded to.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Get the power limits of a site's connectors",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.SiteLoad"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites/{id}/pricing": {
+            "post": {
+                "description": "Adjusts the price of reservations at the site on top of its connectors' tariffs. Peak windows are \"HH:MM\" times in the server's time zone (a window can wrap past midnight) and apply to the share of the reservation inside them. Utilization tiers apply from the given percentage of the site's connectors in use, now or as forecast by the reservations overlapping the reservation, whichever is higher. Percentages are surcharges, negative ones are discounts. Adjustments are locked in when a reservation is quoted or made. An empty policy removes all adjustments.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sites"
+                ],
+                "summary": "Set a site's pricing policy",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.PricingPolicy"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/sites/{id}/reservations": {
+            "post": {
+                "description": "Picks the connector among all of the site's chargepoints, the same way as POST /reservations/{chargepointID}. The chosen chargepoint and connector are returned.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Reservations"
+                ],
+                "summary": "Reserve any suitable connector at a site",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Site ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.AutoReservationRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional, retries with the same key get the first response instead of handling the request again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReservationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment Required",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/models.LimitErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/stop/{chargepointID}/{connectorID}": {
+            "post": {
+                "description": "Ends the user's active charging session on the connector, finishing their reservation and making the connector available again. The meter value (in Wh) is optional.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Stop charging",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Connector ID",
+                        "name": "connectorID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.StopChargingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tariffs": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tariffs"
+                ],
+                "summary": "Get all tariffs",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Tariff"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tariffs/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tariffs"
+                ],
+                "summary": "Get information about a tariff by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tariff ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Tariff"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "All prices are in cents. Bands override the per-minute and per-kWh prices during part of the day, with \"HH:MM\" start and end times in the server's time zone (a band can wrap past midnight, like 22:00 to 06:00). The idle fee is charged per minute the vehicle stays plugged in after its reservation ends (or charging stops after it), and the no-show fee once when a reservation expires without charging.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tariffs"
+                ],
+                "summary": "Create a new tariff",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tariff ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.CreateTariffRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/tariffs/{id}/attach": {
+            "post": {
+                "description": "Give either a site ID, a chargepoint ID, or a chargepoint ID with a connector ID. A connector's own tariff takes precedence over its chargepoint's, which takes precedence over its site's. Connectors without any tariff are free.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Tariffs"
+                ],
+                "summary": "Attach a tariff to a site, chargepoint or connector",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Tariff ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.AttachTariffRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/unplug/{chargepointID}/{connectorID}": {
+            "post": {
+                "description": "Called by the chargepoint when the cable is removed from the connector. A vehicle left plugged in after its reservation ended and charging stopped is charged the tariff's idle fee for every minute until then (up to IDLE_FEE_MAX_MINUTES, in case the unplugging is never reported). Starting a new session on the connector counts as unplugging too.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Sessions"
+                ],
+                "summary": "Report that a vehicle was unplugged",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Connector ID",
+                        "name": "connectorID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get all users",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get information about a user by their ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Create a new user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.CreateUserRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional, retries with the same key get the first response instead of handling the request again",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "Unprocessable Entity",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/noshows": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get all of a user's no-shows",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NoShow"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/notifications": {
+            "get": {
+                "description": "Lists what happened to the user's reservations, newest first. For example, the user is warned when the vehicle before them is still plugged in shortly before their reservation starts.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get a user's notifications",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Notification"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/reliability": {
+            "get": {
+                "description": "The score is the percentage of the user's finished reservations they actually charged on. The penalty is based on the no-shows in the configured window that haven't been forgiven, and is either \"None\", \"Warning\", \"Ban\" or \"Deposit\".",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get a user's reliability",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Reliability"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/series": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Get a user's recurring reservations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.ReservationSeries"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/vehicles": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "Get all of a user's vehicles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Vehicle"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "The plug types can be \"Type1\", \"Type2\", \"CCS1\", \"CCS2\", \"CHAdeMO\", \"NACS\" or \"GBT\". Charge rates are in kW and the battery capacity is in kWh.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "Add a vehicle to a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.CreateVehicleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Vehicle"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/waitlist": {
+            "get": {
+                "description": "Lists the user's places in queues, with their position while waiting, and the connectors offered to them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waitlist"
+                ],
+                "summary": "Get the waitlists a user is on",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WaitlistEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/wallet": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallets"
+                ],
+                "summary": "Get a user's wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Wallet"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/wallet/reconcile": {
+            "get": {
+                "description": "Adds up every ledger entry of the wallet and compares the totals with its balance and held amount.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallets"
+                ],
+                "summary": "Reconcile a user's wallet with its ledger",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WalletReconciliation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/wallet/topup": {
+            "post": {
+                "description": "Adds the amount (in cents) to the user's prepaid balance, opening the wallet on the first top-up. Once a user has a wallet, reservations hold their estimated price from it and completed sessions are paid from it. Members of an organization are billed through the organization instead.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallets"
+                ],
+                "summary": "Top up a user's wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.TopUpRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Wallet"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/wallet/transactions": {
+            "get": {
+                "description": "Lists the wallet's ledger entries, newest first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Wallets"
+                ],
+                "summary": "Get the transaction history of a user's wallet",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.LedgerEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "Get information about a vehicle by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Vehicle"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/vehicles/{id}/connectors": {
+            "get": {
+                "description": "Lists every available connector the vehicle is compatible with, fastest first. Connectors without details (plug type, power) are left out since their compatibility is unknown.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Vehicles"
+                ],
+                "summary": "Suggest available connectors for a vehicle",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Vehicle ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.CompatibleConnector"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/waitlist/{chargepointID}": {
+            "get": {
+                "description": "Lists the users waiting for the chargepoint or holding an offer, in the order they joined.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waitlist"
+                ],
+                "summary": "Get a chargepoint's waitlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.WaitlistEntry"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Queues the user for the chargepoint when all of its connectors (with the plug type, if given) are taken. When one frees up, it's offered to the longest waiting user: the connector is held for them for WAITLIST_OFFER_MINUTES, during which they can reserve it as usual. Offers that aren't taken in time go to the next user in line.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waitlist"
+                ],
+                "summary": "Join a chargepoint's waitlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Request body",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/endpoints.JoinWaitlistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.WaitlistEntry"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "A connector that was offered to the user is offered to the next user in line.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Waitlist"
+                ],
+                "summary": "Leave a chargepoint's waitlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Chargepoint ID",
+                        "name": "chargepointID",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "userId",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MessageResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "endpoints.AssignPriorityClassRequest": {
+            "type": "object",
+            "properties": {
+                "organizationId": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.AttachTariffRequest": {
+            "type": "object",
+            "properties": {
+                "chargepointId": {
+                    "type": "string"
+                },
+                "connectorId": {
+                    "type": "integer"
+                },
+                "siteId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.AutoReservationRequest": {
+            "type": "object",
+            "properties": {
+                "bump": {
+                    "description": "Optional, for users of a priority class that can bump: takes the place of the overlapping reservations of users with a lower rank, or takes over the connector from a late one, see POST /priorityclasses/{id}",
+                    "type": "boolean"
+                },
+                "departure": {
+                    "type": "string"
+                },
+                "deposit": {
+                    "description": "Deposit in cents, only required from users with too many no-shows (see GET /users/{id}/reliability). It is held on the wallet or pre-authorized on the card with the estimated price, kept on a no-show and given back once the user charges.",
+                    "type": "integer"
+                },
+                "minPower": {
+                    "description": "Optional, only connectors with at least this power (in kW) are picked",
+                    "type": "number"
+                },
+                "minutes": {
+                    "type": "integer"
+                },
+                "paymentMethod": {
+                    "description": "The gateway's payment method, required when card payments are enabled and the user has no wallet or organization",
+                    "type": "string"
+                },
+                "plugType": {
+                    "description": "Optional, only connectors with this plug type are picked",
+                    "type": "string"
+                },
+                "policy": {
+                    "description": "Optional, either \"LeastUsed\", \"HighestPower\" or \"SpreadWear\"",
+                    "type": "string"
+                },
+                "quoteId": {
+                    "description": "Optional, locks in the price of a quote (see POST /quotes/{chargepointID}/{connectorID}) that hasn't expired",
+                    "type": "string"
+                },
+                "startTime": {
+                    "description": "Optional, books the connector ahead (up to the booking horizon of the connector's reservation policy) instead of reserving it right away",
+                    "type": "string"
+                },
+                "targetEnergy": {
+                    "description": "Optional smart charging: the energy (in kWh) needed by the departure, see GET /reservations/{id}/schedule. Without minutes, the connector is reserved until the departure.",
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "vehicleId": {
+                    "description": "Optional, the reservation is refused when the connector doesn't fit the vehicle",
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.BillingRunRequest": {
+            "type": "object",
+            "properties": {
+                "organizationId": {
+                    "type": "string"
+                },
+                "period": {
+                    "description": "Calendar month like \"2026-01\"",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "Optional, bills only this user or organization",
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.ChangeConnectorStateRequest": {
+            "type": "object",
+            "properties": {
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.ChargeRequest": {
+            "type": "object",
+            "properties": {
+                "meterStart": {
+                    "description": "Optional meter value in Wh when charging starts",
+                    "type": "number"
+                },
+                "minutes": {
+                    "description": "Only used when charging without a reservation, defaults to 180 minutes",
+                    "type": "integer"
+                },
+                "paymentMethod": {
+                    "description": "Only used when charging without a reservation, the gateway's payment method is required when card payments are enabled and the user has no wallet or organization",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.ConnectorDetailsRequest": {
+            "type": "object",
+            "properties": {
+                "currentType": {
+                    "type": "string"
+                },
+                "maxPower": {
+                    "type": "number"
+                },
+                "plugType": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.ConnectorHoldRequest": {
+            "type": "object",
+            "properties": {
+                "connectorId": {
+                    "type": "integer"
+                },
+                "priorityClass": {
+                    "description": "Optional, the hold is removed without it",
+                    "type": "string"
+                },
+                "releaseMinutes": {
+                    "description": "For example 10 to release the connector 10 minutes before each slot",
+                    "type": "integer"
+                }
+            }
+        },
+        "endpoints.CreateChargepointRequest": {
+            "type": "object",
+            "properties": {
+                "connectorDetails": {
+                    "description": "Optional, but when given there must be details for every connector",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/endpoints.ConnectorDetailsRequest"
+                    }
+                },
+                "connectors": {
+                    "type": "integer"
+                },
+                "siteId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.CreateOrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "admins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "monthlyMinutes": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "reservedCapacity": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReservedCapacity"
+                    }
+                }
+            }
+        },
+        "endpoints.CreatePriorityClassRequest": {
+            "type": "object",
+            "properties": {
+                "bumpNoticeMinutes": {
+                    "type": "integer"
+                },
+                "canBump": {
+                    "type": "boolean"
+                },
+                "compensation": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rank": {
+                    "type": "integer"
+                },
+                "takeOverMinutes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "endpoints.CreateSiteRequest": {
+            "type": "object",
+            "properties": {
+                "location": {
+                    "description": "Optional, lets drivers search for sites near them",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.Location"
+                        }
+                    ]
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.CreateTariffRequest": {
+            "type": "object",
+            "properties": {
+                "bands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TariffBand"
+                    }
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "idleFee": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "noShowFee": {
+                    "type": "integer"
+                },
+                "perKWh": {
+                    "type": "integer"
+                },
+                "perMinute": {
+                    "type": "integer"
+                },
+                "reservationFee": {
+                    "type": "integer"
+                },
+                "sessionFee": {
+                    "type": "integer"
+                }
+            }
+        },
+        "endpoints.CreateUserRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "Optional, sessions of users with a higher priority get power first at sites that share it by priority",
+                    "type": "integer"
+                }
+            }
+        },
+        "endpoints.CreateVehicleRequest": {
+            "type": "object",
+            "properties": {
+                "batteryCapacity": {
+                    "type": "number"
+                },
+                "make": {
+                    "type": "string"
+                },
+                "maxACPower": {
+                    "type": "number"
+                },
+                "maxDCPower": {
+                    "type": "number"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "plugTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "endpoints.ForgiveNoShowRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.JoinWaitlistRequest": {
+            "type": "object",
+            "properties": {
+                "plugType": {
+                    "description": "Optional, only connectors with this plug type are offered",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.MaintenanceRequest": {
+            "type": "object",
+            "properties": {
+                "connector": {
+                    "description": "Optional, all of the chargepoint's connectors when 0",
+                    "type": "integer"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.MeterValueRequest": {
+            "type": "object",
+            "properties": {
+                "energy": {
+                    "type": "number"
+                },
+                "power": {
+                    "type": "number"
+                },
+                "soc": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "voltage": {
+                    "type": "number"
+                }
+            }
+        },
+        "endpoints.MeterValuesRequest": {
+            "type": "object",
+            "properties": {
+                "values": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/endpoints.MeterValueRequest"
+                    }
+                }
+            }
+        },
+        "endpoints.ModifyReservationRequest": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "description": "Optional, moves the reservation to another chargepoint, which needs the connector as well",
+                    "type": "string"
+                },
+                "connector": {
+                    "description": "Optional, moves the reservation to another connector",
+                    "type": "integer"
+                },
+                "minutes": {
+                    "description": "Optional, changes the reservation time",
+                    "type": "integer"
+                },
+                "startTime": {
+                    "description": "Optional, moves the reservation to another time in the future, up to the connector's booking horizon",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.OperatorCancelRequest": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.OrganizationAdminRequest": {
+            "type": "object",
+            "properties": {
+                "adminId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.QuoteRequest": {
+            "type": "object",
+            "properties": {
+                "energy": {
+                    "type": "number"
+                },
+                "minutes": {
+                    "type": "integer"
+                },
+                "startTime": {
+                    "description": "Optional, prices a reservation booked ahead instead of one starting now",
+                    "type": "string"
+                },
+                "userId": {
+                    "description": "The user the quote is for, only they can reserve with it",
+                    "type": "string"
+                },
+                "vehicleId": {
+                    "type": "string"
+                }
+            }
         },
         "endpoints.ReservationRequest": {
             "type": "object",
             "properties": {
+                "bump": {
+                    "description": "Optional, for users of a priority class that can bump: takes the place of the overlapping reservations of users with a lower rank, or takes over the connector from a late one, see POST /priorityclasses/{id}",
+                    "type": "boolean"
+                },
+                "departure": {
+                    "type": "string"
+                },
+                "deposit": {
+                    "description": "Deposit in cents, only required from users with too many no-shows (see GET /users/{id}/reliability). It is held on the wallet or pre-authorized on the card with the estimated price, kept on a no-show and given back once the user charges.",
+                    "type": "integer"
+                },
+                "minutes": {
+                    "type": "integer"
+                },
+                "paymentMethod": {
+                    "description": "The gateway's payment method, required when card payments are enabled and the user has no wallet or organization",
+                    "type": "string"
+                },
+                "quoteId": {
+                    "description": "Optional, locks in the price of a quote (see POST /quotes/{chargepointID}/{connectorID}) that hasn't expired",
+                    "type": "string"
+                },
+                "startTime": {
+                    "description": "Optional, books the connector ahead (up to the booking horizon of the connector's reservation policy) instead of reserving it right away",
+                    "type": "string"
+                },
+                "targetEnergy": {
+                    "description": "Optional smart charging: the energy (in kWh) needed by the departure, see GET /reservations/{id}/schedule. Without minutes, the connector is reserved until the departure.",
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "vehicleId": {
+                    "description": "Optional, the reservation is refused when the connector doesn't fit the vehicle",
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.SeriesRequest": {
+            "type": "object",
+            "properties": {
+                "minutes": {
+                    "type": "integer"
+                },
+                "paymentMethod": {
+                    "type": "string"
+                },
+                "rule": {
+                    "description": "For example \"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20231231\" or \"FREQ=WEEKLY;BYDAY=SA;COUNT=10\"",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "When the first occurrence starts",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "vehicleId": {
+                    "description": "Optional, see POST /reservations/{chargepointID}/{connectorID}",
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.SetReservationPolicyRequest": {
+            "type": "object",
+            "properties": {
+                "chargepointId": {
+                    "type": "string"
+                },
+                "connectorId": {
+                    "type": "integer"
+                },
+                "policy": {
+                    "description": "Optional, the policy is removed without it",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ReservationPolicy"
+                        }
+                    ]
+                },
+                "siteId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.SiteCapacityRequest": {
+            "type": "object",
+            "properties": {
+                "maxPower": {
+                    "description": "In kW, 0 means unlimited",
+                    "type": "number"
+                },
+                "strategy": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.StopChargingRequest": {
+            "type": "object",
+            "properties": {
+                "meterStop": {
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.TopUpRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "description": "Amount in cents",
+                    "type": "integer"
+                }
+            }
+        },
+        "endpoints.UpdateOccurrenceRequest": {
+            "type": "object",
+            "properties": {
+                "minutes": {
+                    "description": "Optional, changes the reservation time of this occurrence only",
+                    "type": "integer"
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "endpoints.UpdateSeriesRequest": {
+            "type": "object",
+            "properties": {
+                "minutes": {
+                    "type": "integer"
+                },
+                "rule": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Chargepoint": {
+            "type": "object",
+            "properties": {
+                "connectors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Connector"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reservationPolicy": {
+                    "$ref": "#/definitions/models.ReservationPolicy"
+                },
+                "siteId": {
+                    "type": "string"
+                },
+                "tariffId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ChargingSchedule": {
+            "type": "object",
+            "properties": {
+                "chargingRateUnit": {
+                    "type": "string"
+                },
+                "chargingSchedulePeriod": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ChargingSchedulePeriod"
+                    }
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "duration": {
+                    "type": "integer"
+                },
+                "energyCost": {
+                    "description": "Estimated price of the planned energy, in cents",
+                    "type": "integer"
+                },
+                "plannedEnergy": {
+                    "type": "number"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "startSchedule": {
+                    "type": "string"
+                },
+                "targetEnergy": {
+                    "description": "Energy (in kWh) the vehicle still needs and the energy the schedule delivers",
+                    "type": "number"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ChargingSchedulePeriod": {
+            "type": "object",
+            "properties": {
+                "limit": {
+                    "type": "number"
+                },
+                "pricePerKWh": {
+                    "description": "Price of the energy during the period, in cents per kWh",
+                    "type": "number"
+                },
+                "startPeriod": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ChargingSession": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "description": "Copied from the reservation, so the session is priced as it was quoted",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceAdjustment"
+                    }
+                },
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "energy": {
+                    "description": "Energy delivered in kWh, computed when the session stops",
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "idleBilled": {
+                    "type": "boolean"
+                },
+                "idleSince": {
+                    "description": "When the vehicle starts idling until it's unplugged: the end of the reservation's charging time, or when charging stopped after it. Not set when an operator cancelled the session.",
+                    "type": "string"
+                },
+                "lateWarned": {
+                    "description": "Set once the holder of the connector's next reservation was warned that the vehicle is still plugged in",
+                    "type": "boolean"
+                },
+                "meterStart": {
+                    "type": "number"
+                },
+                "meterStop": {
+                    "description": "The last known meter value while charging",
+                    "type": "number"
+                },
+                "organizationId": {
+                    "type": "string"
+                },
+                "powerLimit": {
+                    "description": "Copied from the reservation for the site's load management",
+                    "type": "number"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Either \"Charging\" or \"Completed\"",
+                    "type": "string"
+                },
+                "stopReason": {
+                    "description": "Either \"Local\" (stopped by the user), \"ReservationEnded\" or \"Cancelled\" (by an operator)",
+                    "type": "string"
+                },
+                "stopTime": {
+                    "type": "string"
+                },
+                "tariffId": {
+                    "type": "string"
+                },
+                "unpluggedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "walkIn": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.CompatibleConnector": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "currentType": {
+                    "type": "string"
+                },
+                "maxPower": {
+                    "type": "number"
+                },
+                "plugType": {
+                    "type": "string"
+                },
+                "power": {
+                    "description": "The power the vehicle can actually draw from the connector, in kW",
+                    "type": "number"
+                }
+            }
+        },
+        "models.Compensation": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "bumpedBy": {
+                    "description": "The priority reservation that took its place",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "priorityClass": {
+                    "type": "string"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Connector": {
+            "type": "object",
+            "properties": {
+                "currentType": {
+                    "type": "string"
+                },
+                "hold": {
+                    "$ref": "#/definitions/models.ConnectorHold"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "maxPower": {
+                    "type": "number"
+                },
+                "plugType": {
+                    "description": "Empty when the chargepoint was created without connector details",
+                    "type": "string"
+                },
+                "reservationPolicy": {
+                    "$ref": "#/definitions/models.ReservationPolicy"
+                },
+                "state": {
+                    "type": "string"
+                },
+                "tariffId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConnectorAvailability": {
+            "type": "object",
+            "properties": {
+                "bufferMinutes": {
+                    "description": "Time kept free before and after the connector's reservations, in minutes",
+                    "type": "integer"
+                },
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "currentType": {
+                    "type": "string"
+                },
+                "distance": {
+                    "description": "From the searched location, in km",
+                    "type": "number"
+                },
+                "maxPower": {
+                    "type": "number"
+                },
+                "plugType": {
+                    "type": "string"
+                },
+                "siteId": {
+                    "type": "string"
+                },
+                "slots": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TimeSlot"
+                    }
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConnectorHold": {
+            "type": "object",
+            "properties": {
+                "priorityClass": {
+                    "type": "string"
+                },
+                "releaseMinutes": {
+                    "description": "Other users can only reserve slots starting within this many minutes",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ConnectorLoad": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "limit": {
+                    "type": "number"
+                },
+                "maxPower": {
+                    "type": "number"
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ConnectorPolicy": {
+            "type": "object",
+            "properties": {
+                "allowedOrganizations": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "bufferMinutes": {
+                    "type": "integer"
+                },
+                "graceMinutes": {
+                    "type": "integer"
+                },
+                "horizonDays": {
+                    "type": "integer"
+                },
+                "maxMinutes": {
+                    "type": "integer"
+                },
+                "minMinutes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Fee": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "organizationId": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unitPrice": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Invoice": {
+            "type": "object",
+            "properties": {
+                "amountPaid": {
+                    "type": "integer"
+                },
+                "balanceDue": {
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "issuedAt": {
+                    "type": "string"
+                },
+                "lines": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InvoiceLine"
+                    }
+                },
+                "number": {
+                    "description": "Numbered sequentially, like \"INV-2026-000042\"",
+                    "type": "string"
+                },
+                "organizationId": {
+                    "type": "string"
+                },
+                "payments": {
+                    "description": "What the user already paid in the period from their wallet or by card, and what was refunded to them as negative amounts. They don't change the total, only what is still due.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.InvoiceLine"
+                    }
+                },
+                "periodEnd": {
+                    "type": "string"
+                },
+                "periodStart": {
+                    "type": "string"
+                },
+                "subtotal": {
+                    "type": "integer"
+                },
+                "tax": {
+                    "type": "integer"
+                },
+                "taxRate": {
+                    "description": "Tax rate in percent",
+                    "type": "number"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "description": "Exactly one of them is set",
+                    "type": "string"
+                }
+            }
+        },
+        "models.InvoiceLine": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "date": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "number": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "reservationId": {
+                    "description": "What the line bills for",
+                    "type": "string"
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unitPrice": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.LedgerEntry": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "description": "The wallet right after the entry was applied",
+                    "type": "integer"
+                },
+                "held": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Either \"TopUp\", \"Hold\", \"Release\", \"Capture\" or \"Refund\"",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.LimitErrorResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "current": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "retryAfter": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Location": {
+            "type": "object",
+            "properties": {
+                "latitude": {
+                    "type": "number"
+                },
+                "longitude": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.MaintenanceWindow": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "description": "0 for all of the chargepoint's connectors",
+                    "type": "integer"
+                },
+                "end": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.MessageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.MeterValue": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "energy": {
+                    "type": "number"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "power": {
+                    "type": "number"
+                },
+                "sessionId": {
+                    "type": "string"
+                },
+                "soc": {
+                    "type": "number"
+                },
+                "timestamp": {
+                    "type": "string"
+                },
+                "voltage": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.NoShow": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "forgiven": {
+                    "type": "boolean"
+                },
+                "forgivenReason": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "time": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Notification": {
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "Either \"LateSession\" or \"Bumped\"",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Organization": {
+            "type": "object",
+            "properties": {
+                "admins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "monthlyMinutes": {
+                    "description": "Minutes all members can reserve together per calendar month, 0 means unlimited",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "priorityClass": {
+                    "description": "Applies to members without a priority class of their own",
+                    "type": "string"
+                },
+                "reservedCapacity": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReservedCapacity"
+                    }
+                }
+            }
+        },
+        "models.Payment": {
+            "type": "object",
+            "properties": {
+                "authorizationId": {
+                    "description": "The gateway's ID of the authorization",
+                    "type": "string"
+                },
+                "authorized": {
+                    "type": "integer"
+                },
+                "captured": {
+                    "type": "integer"
+                },
+                "capturedAt": {
+                    "description": "When the captured and refunded amounts were taken and given back, used to credit them on invoices",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "deposit": {
+                    "description": "The part of the authorization that is the reservation's deposit, only captured on a no-show",
+                    "type": "integer"
+                },
+                "fee": {
+                    "description": "Whether it pays for fees charged after the reservation's own payment was captured",
+                    "type": "boolean"
+                },
+                "gateway": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "paymentMethod": {
+                    "description": "The gateway's payment method, used again when the reservation's change needs a new authorization",
+                    "type": "string"
+                },
+                "refunded": {
+                    "type": "integer"
+                },
+                "refundedAt": {
+                    "type": "string"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "status": {
+                    "description": "Either \"Authorized\", \"Captured\", \"Voided\", \"Refunded\" or \"Failed\"",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PeakWindow": {
+            "type": "object",
+            "properties": {
+                "end": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "percent": {
+                    "type": "integer"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Price": {
+            "type": "object",
+            "properties": {
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceComponent"
+                    }
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PriceAdjustment": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "percent": {
+                    "type": "integer"
+                },
+                "share": {
+                    "type": "number"
+                },
+                "type": {
+                    "description": "Either \"Peak\" or \"Utilization\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.PriceComponent": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "integer"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "type": {
+                    "description": "Either \"Reservation\", \"Session\", \"Time\", \"Energy\", \"Idle\", \"Peak\" or \"Utilization\"",
+                    "type": "string"
+                },
+                "unit": {
+                    "type": "string"
+                },
+                "unitPrice": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.PricingPolicy": {
+            "type": "object",
+            "properties": {
+                "peakWindows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PeakWindow"
+                    }
+                },
+                "utilizationTiers": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UtilizationTier"
+                    }
+                }
+            }
+        },
+        "models.PriorityClass": {
+            "type": "object",
+            "properties": {
+                "bumpNoticeMinutes": {
+                    "description": "Only reservations starting at least this many minutes later can be bumped",
+                    "type": "integer"
+                },
+                "canBump": {
+                    "description": "Whether members can bump reservations of users with a lower rank that haven't started charging",
+                    "type": "boolean"
+                },
+                "compensation": {
+                    "description": "Recorded for every user whose reservation is bumped, in cents",
+                    "type": "integer"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "rank": {
+                    "description": "Higher ranks come first",
+                    "type": "integer"
+                },
+                "takeOverMinutes": {
+                    "description": "A reservation that already holds its connector is taken over when its user hasn't plugged in this many minutes after it started, 0 never takes one over",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Quote": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceAdjustment"
+                    }
+                },
+                "chargepoint": {
+                    "type": "string"
+                },
+                "components": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceComponent"
+                    }
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currency": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "minutes": {
+                    "type": "integer"
+                },
+                "reservationId": {
+                    "description": "Set once a reservation is made with the quote",
+                    "type": "string"
+                },
+                "startTime": {
+                    "description": "Only set for reservations booked ahead, quotes without it are for reservations starting right away",
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Reliability": {
+            "type": "object",
+            "properties": {
+                "bannedUntil": {
+                    "type": "string"
+                },
+                "deposit": {
+                    "type": "integer"
+                },
+                "noShows": {
+                    "type": "integer"
+                },
+                "penalty": {
+                    "type": "string"
+                },
+                "reservations": {
+                    "type": "integer"
+                },
+                "score": {
+                    "description": "Percentage of finished reservations the user actually charged on",
+                    "type": "number"
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Reservation": {
+            "type": "object",
+            "properties": {
+                "adjustments": {
+                    "description": "The site's pricing policy adjustments, locked in when the reservation was made",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PriceAdjustment"
+                    }
+                },
+                "bumpedBy": {
+                    "description": "Set when the reservation was cancelled for a priority reservation",
+                    "type": "string"
+                },
+                "cancelReason": {
+                    "description": "Only set when an operator cancelled the reservation, or it was bumped",
+                    "type": "string"
+                },
+                "cancelled": {
+                    "type": "boolean"
+                },
+                "chargepoint": {
+                    "type": "string"
+                },
+                "chargingTime": {
+                    "type": "string"
+                },
+                "code": {
+                    "description": "Short code like \"7KQ-4MZ\" to give to the customer, see GET /reservations/code/{code}",
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "departure": {
+                    "type": "string"
+                },
+                "deposit": {
+                    "description": "Deposit (in cents) held or pre-authorized with the reservation, required from users with too many no-shows. It is kept on a no-show and released once the user charges.",
+                    "type": "integer"
+                },
+                "expiryTime": {
+                    "type": "string"
+                },
+                "hasFinishedCharging": {
+                    "type": "boolean"
+                },
+                "hasStartedCharging": {
+                    "type": "boolean"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "legacyId": {
+                    "description": "The numeric ID the reservation had before reservations got ObjectIDs, it can still be looked up by it",
+                    "type": "integer"
+                },
+                "minutes": {
+                    "type": "integer"
+                },
+                "organizationId": {
+                    "description": "The organization billed for the reservation, if the user belongs to one",
+                    "type": "string"
+                },
+                "paymentMode": {
+                    "description": "How the reservation is paid for, decided when it's made: \"Wallet\", \"Card\" or \"Invoice\"",
+                    "type": "string"
+                },
+                "powerLimit": {
+                    "description": "Set when the site's capacity couldn't cover the connector's full power (in kW) during the reservation",
+                    "type": "number"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "priorityClass": {
+                    "description": "The user's priority class when the reservation was made",
+                    "type": "string"
+                },
+                "seriesId": {
+                    "description": "Set when the reservation is an occurrence of a recurring reservation",
+                    "type": "string"
+                },
+                "startTime": {
+                    "description": "When the connector is held from, reservations made through the API start right away",
+                    "type": "string"
+                },
+                "targetEnergy": {
+                    "description": "Smart charging: the energy (in kWh) the vehicle needs by its departure, charged when it's cheapest",
+                    "type": "number"
+                },
+                "tariffId": {
+                    "description": "The tariff that applied to the connector when the reservation was made",
+                    "type": "string"
+                },
+                "userId": {
+                    "type": "string"
+                },
+                "vehicleId": {
+                    "type": "string"
+                },
+                "walkIn": {
+                    "description": "Walk-ins are recorded when a user charges without reserving first. They don't count towards quotas and aren't charged the reservation fee.",
+                    "type": "boolean"
+                }
+            }
+        },
+        "models.ReservationPolicy": {
+            "type": "object",
+            "properties": {
+                "allowedOrganizations": {
+                    "description": "Only members of these organizations can reserve, everyone can when empty",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "bufferMinutes": {
+                    "description": "Time kept free between two reservations of a connector, in minutes",
+                    "type": "integer"
+                },
+                "graceMinutes": {
+                    "description": "How long after the start the user can start charging before the reservation expires, in minutes",
+                    "type": "integer"
+                },
+                "horizonDays": {
+                    "description": "How far ahead reservations can be booked, in days",
+                    "type": "integer"
+                },
+                "maxMinutes": {
+                    "type": "integer"
+                },
+                "minMinutes": {
+                    "description": "Shortest and longest reservation time, in minutes",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.ReservationResponse": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "type": "string"
+                },
+                "code": {
+                    "description": "Booking code to give to the customer",
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "warnings": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "models.ReservationSeries": {
+            "type": "object",
+            "properties": {
+                "cancelled": {
+                    "type": "boolean"
+                },
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connector": {
+                    "type": "integer"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "generatedUntil": {
+                    "description": "Occurrences starting before this time have been booked",
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
                 "minutes": {
                     "type": "integer"
                 },
+                "occurrences": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SeriesOccurrence"
+                    }
+                },
+                "rule": {
+                    "description": "For example \"FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=20\"",
+                    "type": "string"
+                },
+                "start": {
+                    "description": "When the first occurrence starts, the others start at the same time of day",
+                    "type": "string"
+                },
                 "userId": {
                     "type": "string"
+                },
+                "vehicleId": {
+                    "type": "string"
                 }
             }
         },
-        "models.Chargepoint": {
+        "models.ReservedCapacity": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "type": "string"
+                },
+                "connectors": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.SeriesOccurrence": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "reservationId": {
+                    "type": "string"
+                },
+                "start": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.Site": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string"
+                },
+                "loadStrategy": {
+                    "description": "How the power is shared between charging sessions, either \"EqualShare\", \"Priority\" or \"FirstCome\"",
+                    "type": "string"
+                },
+                "location": {
+                    "$ref": "#/definitions/models.Location"
+                },
+                "maxPower": {
+                    "description": "Power (in kW) the site's grid connection can deliver to all connectors together, 0 means unlimited",
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "pricingPolicy": {
+                    "$ref": "#/definitions/models.PricingPolicy"
+                },
+                "reservationPolicy": {
+                    "$ref": "#/definitions/models.ReservationPolicy"
+                },
+                "tariffId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SiteLoad": {
             "type": "object",
             "properties": {
+                "allocated": {
+                    "type": "number"
+                },
                 "connectors": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/models.Connector"
+                        "$ref": "#/definitions/models.ConnectorLoad"
                     }
                 },
-                "id": {
+                "loadStrategy": {
+                    "type": "string"
+                },
+                "maxPower": {
+                    "type": "number"
+                },
+                "siteId": {
                     "type": "string"
                 }
             }
         },
-        "models.Connector": {
+        "models.Tariff": {
             "type": "object",
             "properties": {
+                "bands": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.TariffBand"
+                    }
+                },
+                "currency": {
+                    "type": "string"
+                },
                 "id": {
+                    "type": "string"
+                },
+                "idleFee": {
                     "type": "integer"
                 },
-                "state": {
+                "name": {
                     "type": "string"
+                },
+                "noShowFee": {
+                    "type": "integer"
+                },
+                "perKWh": {
+                    "type": "integer"
+                },
+                "perMinute": {
+                    "type": "integer"
+                },
+                "reservationFee": {
+                    "type": "integer"
+                },
+                "sessionFee": {
+                    "type": "integer"
                 }
             }
         },
-        "models.ErrorResponse": {
+        "models.TariffBand": {
             "type": "object",
             "properties": {
-                "error": {
+                "end": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "perKWh": {
+                    "type": "integer"
+                },
+                "perMinute": {
+                    "type": "integer"
+                },
+                "start": {
                     "type": "string"
                 }
             }
         },
-        "models.MessageResponse": {
+        "models.TimeSlot": {
             "type": "object",
             "properties": {
-                "message": {
+                "end": {
+                    "type": "string"
+                },
+                "minutes": {
+                    "type": "integer"
+                },
+                "start": {
                     "type": "string"
                 }
             }
         },
-        "models.Reservation": {
+        "models.User": {
             "type": "object",
             "properties": {
-                "chargepoint": {
+                "id": {
                     "type": "string"
                 },
-                "chargingTime": {
+                "name": {
                     "type": "string"
                 },
-                "connector": {
+                "organizationId": {
+                    "type": "string"
+                },
+                "priority": {
+                    "description": "Sessions of users with a higher priority get power first at sites that share it by priority",
                     "type": "integer"
                 },
-                "expiryTime": {
+                "priorityClass": {
+                    "description": "Overrides the priority class of the user's organization",
                     "type": "string"
+                }
+            }
+        },
+        "models.UtilizationTier": {
+            "type": "object",
+            "properties": {
+                "minUtilization": {
+                    "type": "integer"
                 },
-                "hasFinishedCharging": {
-                    "type": "boolean"
+                "percent": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.Vehicle": {
+            "type": "object",
+            "properties": {
+                "batteryCapacity": {
+                    "type": "number"
                 },
-                "hasStartedCharging": {
-                    "type": "boolean"
+                "id": {
+                    "type": "string"
+                },
+                "make": {
+                    "type": "string"
+                },
+                "maxACPower": {
+                    "description": "Charge rates in kW and battery capacity in kWh",
+                    "type": "number"
+                },
+                "maxDCPower": {
+                    "type": "number"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "plugTypes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "userId": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.WaitlistEntry": {
+            "type": "object",
+            "properties": {
+                "chargepoint": {
+                    "type": "string"
                 },
                 "id": {
-                    "description": "Suggestion for IDs: currently, we use UnixNano() for the ID because for the demonstration, it is sufficient, but I would recommend swapping to something like Mongo ObjectIDs because they're less likely to conflict. For the demo, it's fine!",
+                    "type": "string"
+                },
+                "joinedAt": {
+                    "type": "string"
+                },
+                "offerExpiresAt": {
+                    "type": "string"
+                },
+                "offeredConnector": {
+                    "description": "Set while a connector is offered",
+                    "type": "integer"
+                },
+                "plugType": {
+                    "description": "Optional, only connectors with this plug type are offered",
+                    "type": "string"
+                },
+                "position": {
+                    "description": "Place in the chargepoint's queue while waiting, starting at 1",
                     "type": "integer"
                 },
+                "status": {
+                    "type": "string"
+                },
                 "userId": {
                     "type": "string"
                 }
             }
         },
-        "models.User": {
+        "models.Wallet": {
             "type": "object",
             "properties": {
-                "id": {
+                "available": {
+                    "type": "integer"
+                },
+                "balance": {
+                    "type": "integer"
+                },
+                "currency": {
                     "type": "string"
                 },
-                "name": {
+                "held": {
+                    "type": "integer"
+                },
+                "userId": {
                     "type": "string"
                 }
             }
+        },
+        "models.WalletReconciliation": {
+            "type": "object",
+            "properties": {
+                "consistent": {
+                    "type": "boolean"
+                },
+                "entries": {
+                    "type": "integer"
+                },
+                "ledgerBalance": {
+                    "type": "integer"
+                },
+                "ledgerHeld": {
+                    "type": "integer"
+                },
+                "wallet": {
+                    "$ref": "#/definitions/models.Wallet"
+                }
+            }
         }
     }
 }`
@@ -0,0 +1,279 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"reservations/assignment"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Sessions started within this period count as recent usage of a connector
+const recentUsage = 30 * 24 * time.Hour
+
+// assignmentPolicy is the policy connectors are picked with when the request doesn't name one
+func assignmentPolicy() string {
+	policy := os.Getenv("ASSIGNMENT_POLICY")
+	if !assignment.Valid(policy) {
+		return assignment.LeastUsed
+	}
+
+	return policy
+}
+
+// findCandidates lists the available connectors of the chargepoints that meet the request's constraints, with their usage
+func findCandidates(chargepoints []models.Chargepoint, req AutoReservationRequest, vehicle *models.Vehicle, collection *mongo.Collection) ([]assignment.Candidate, error) {
+	candidates := []assignment.Candidate{}
+	chargepointIDs := bson.A{}
+	usage := map[string]map[int]*assignment.Candidate{}
+
+	for _, chargepoint := range chargepoints {
+		for _, connector := range chargepoint.Connectors {
+			if connector.State != "Available" {
+				continue
+			}
+			if req.PlugType != "" && connector.PlugType != req.PlugType {
+				continue
+			}
+			if req.MinPower > 0 && connector.MaxPower < req.MinPower {
+				continue
+			}
+			if vehicle != nil {
+				if compatible, _ := checkCompatibility(*vehicle, connector); !compatible {
+					continue
+				}
+			}
+
+			candidates = append(candidates, assignment.Candidate{Chargepoint: chargepoint.ID, Connector: connector.ID, MaxPower: connector.MaxPower})
+		}
+		chargepointIDs = append(chargepointIDs, chargepoint.ID)
+	}
+
+	if len(candidates) == 0 {
+		return candidates, nil
+	}
+
+	for i := range candidates {
+		if usage[candidates[i].Chargepoint] == nil {
+			usage[candidates[i].Chargepoint] = map[int]*assignment.Candidate{}
+		}
+		usage[candidates[i].Chargepoint][candidates[i].Connector] = &candidates[i]
+	}
+
+	cursor, err := collection.Find(context.Background(), bson.M{"chargepoint": bson.M{"$in": chargepointIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []models.ChargingSession
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		return nil, err
+	}
+
+	since := time.Now().Add(-recentUsage)
+	for _, session := range sessions {
+		candidate, ok := usage[session.Chargepoint][session.Connector]
+		if !ok {
+			continue
+		}
+
+		candidate.TotalEnergy += session.Energy
+		if session.StartTime.After(since) {
+			candidate.RecentSessions++
+		}
+	}
+
+	return candidates, nil
+}
+
+// autoReserve reserves the best of the chargepoints' connectors that meet the request's constraints. Connectors are claimed atomically in order of preference, so concurrent requests never get the same one.
+func autoReserve(c *gin.Context, req AutoReservationRequest, chargepoints []models.Chargepoint, collections Collections) {
+	policy := req.Policy
+	if policy == "" {
+		policy = assignmentPolicy()
+	}
+
+	if !assignment.Valid(policy) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The policy must be either \"LeastUsed\", \"HighestPower\" or \"SpreadWear\""})
+		return
+	}
+
+	if req.StartTime != nil && req.StartTime.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connectors can only be picked for reservations that start right away"})
+		return
+	}
+
+	if req.MinPower < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The minimum power can't be negative"})
+		return
+	}
+
+	// Vehicles are validated when reserving, here they only narrow down the connectors
+	var vehicle *models.Vehicle
+	if req.VehicleID != "" {
+		found, err := FindVehicleByID(req.VehicleID, collections.Vehicles)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch vehicles"})
+			return
+		}
+		if err == nil && found.UserID == req.UserID {
+			vehicle = &found
+		}
+	}
+
+	candidates, err := findCandidates(chargepoints, req, vehicle, collections.Sessions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connectors' usage"})
+		return
+	}
+
+	// Why the last connector that was tried couldn't be reserved
+	var refused *reservationError
+	for _, candidate := range assignment.Rank(policy, candidates) {
+		claimed, err := setConnectorState(candidate.Chargepoint, candidate.Connector, "Available", "Reserved", collections.Chargepoints)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the state of the connector"})
+			return
+		}
+
+		// Another request got the connector first
+		if !claimed {
+			continue
+		}
+
+		var chargepoint models.Chargepoint
+		for _, found := range chargepoints {
+			if found.ID == candidate.Chargepoint {
+				chargepoint = found
+			}
+		}
+
+		reservation, warnings, reserveErr := reserve(req.ReservationRequest, chargepoint, candidate.Connector, true, collections)
+		if reserveErr != nil {
+			if _, err := setConnectorState(candidate.Chargepoint, candidate.Connector, "Reserved", "Available", collections.Chargepoints); err != nil {
+				fmt.Println("Error releasing claimed connector: ", err)
+			}
+
+			// Another connector might do, unless it's the user that can't reserve
+			if reserveErr.Connector {
+				refused = reserveErr
+				continue
+			}
+			c.JSON(reserveErr.Status, reserveErr.Body)
+			return
+		}
+
+		c.JSON(http.StatusOK, models.ReservationResponse{
+			Message:       "Reservation created",
+			Warnings:      warnings,
+			ReservationID: reservation.ID,
+			Code:          reservation.Code,
+			Chargepoint:   reservation.Chargepoint,
+			Connector:     reservation.Connector,
+		})
+		return
+	}
+
+	if refused != nil {
+		c.JSON(refused.Status, refused.Body)
+		return
+	}
+	c.JSON(http.StatusConflict, models.ErrorResponse{Error: "No available connector meets the constraints"})
+}
+
+// AutoReserveChargepoint godoc
+// @Summary Reserve any suitable connector of a chargepoint
+// @Description Like POST /reservations/{chargepointID}/{connectorID}, but the server picks the connector: the best available one that has the plug type and at least the minimum power (and fits the vehicle, if given), according to the policy. "LeastUsed" picks the connector with the fewest sessions in the last 30 days, "HighestPower" the most powerful one and "SpreadWear" the one that delivered the least energy overall. Connectors that can't be reserved for the request, like ones booked during it, are skipped. Without a policy, ASSIGNMENT_POLICY is used. The chosen connector is returned.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param body body AutoReservationRequest true "Request body"
+// @Param Idempotency-Key header string false "Optional, retries with the same key get the first response instead of handling the request again"
+// @Success 200 {object} models.ReservationResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 403 {object} models.LimitErrorResponse
+// @Failure 402 {object} models.LimitErrorResponse
+// @Router /reservations/{chargepointID} [post]
+func AutoReserveChargepoint(c *gin.Context, collections Collections) {
+	var req AutoReservationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	autoReserve(c, req, []models.Chargepoint{chargepoint}, collections)
+}
+
+// AutoReserveSite godoc
+// @Summary Reserve any suitable connector at a site
+// @Description Picks the connector among all of the site's chargepoints, the same way as POST /reservations/{chargepointID}. The chosen chargepoint and connector are returned.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param body body AutoReservationRequest true "Request body"
+// @Param Idempotency-Key header string false "Optional, retries with the same key get the first response instead of handling the request again"
+// @Success 200 {object} models.ReservationResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 403 {object} models.LimitErrorResponse
+// @Failure 402 {object} models.LimitErrorResponse
+// @Router /sites/{id}/reservations [post]
+func AutoReserveSite(c *gin.Context, collections Collections) {
+	var req AutoReservationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	site, err := FindSiteByID(c.Param("id"), collections.Sites)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Site not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch sites"})
+		return
+	}
+
+	chargepoints, err := findSiteChargepoints(site.ID, collections.Chargepoints)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	autoReserve(c, req, chargepoints, collections)
+}
+
+type AutoReservationRequest struct {
+	ReservationRequest
+	// Optional, only connectors with this plug type are picked
+	PlugType string `json:"plugType"`
+	// Optional, only connectors with at least this power (in kW) are picked
+	MinPower float64 `json:"minPower"`
+	// Optional, either "LeastUsed", "HighestPower" or "SpreadWear"
+	Policy string `json:"policy"`
+}
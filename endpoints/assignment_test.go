@@ -0,0 +1,117 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestAutoReserve(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/reservations/:cpID", func(c *gin.Context) {
+		AutoReserveChargepoint(c, collections)
+	})
+
+	router.POST("/sites/:id/reservations", func(c *gin.Context) {
+		AutoReserveSite(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Sites, collections.Chargepoints, collections.Reservations, collections.Sessions} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	for _, id := range []string{"assignFirst", "assignSecond", "assignThird", "assignFourth", "assignFifth"} {
+		collections.Users.InsertOne(context.Background(), models.User{ID: id, Name: "Driver"})
+	}
+	collections.Sites.InsertOne(context.Background(), models.Site{ID: "assignSite", Name: "Depot"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "assignChargepoint", SiteID: "assignSite", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "Type2", MaxPower: 22},
+		{ID: 2, State: "Available", PlugType: "Type2", MaxPower: 11},
+		{ID: 3, State: "Available", PlugType: "CCS", MaxPower: 50},
+	}})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "assignOther", SiteID: "assignSite", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "Type2", MaxPower: 11},
+	}})
+
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "assignBooked", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "CCS", MaxPower: 150},
+		{ID: 2, State: "Available", PlugType: "CCS", MaxPower: 50},
+	}})
+
+	// The most powerful connector is booked soon after
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{UserID: "assignFirst", Chargepoint: "assignBooked", Connector: 1, StartTime: time.Now().Add(10 * time.Minute), ChargingTime: time.Now().Add(70 * time.Minute)})
+
+	// The first connector is used a lot
+	for i := 0; i < 3; i++ {
+		collections.Sessions.InsertOne(context.Background(), models.ChargingSession{Chargepoint: "assignChargepoint", Connector: 1, Status: SessionCompleted, StartTime: time.Now().Add(-time.Duration(i+1) * time.Hour), Energy: 10})
+	}
+
+	request := func(endpoint string, body any) (int, models.ReservationResponse) {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var response models.ReservationResponse
+		json.Unmarshal(recorder.Body.Bytes(), &response)
+		return recorder.Code, response
+	}
+
+	tests := []struct {
+		name        string
+		endpoint    string
+		body        any
+		code        int
+		chargepoint string
+		connector   int
+	}{
+		{name: "UnknownPolicy", endpoint: "/reservations/assignChargepoint", body: map[string]any{"userId": "assignFirst", "minutes": 30, "policy": "Random"}, code: http.StatusBadRequest},
+		{name: "TooPowerful", endpoint: "/reservations/assignChargepoint", body: map[string]any{"userId": "assignFirst", "minutes": 30, "plugType": "Type2", "minPower": 50}, code: http.StatusConflict},
+		{name: "HighestPower", endpoint: "/reservations/assignChargepoint", body: map[string]any{"userId": "assignFirst", "minutes": 30, "plugType": "Type2", "policy": "HighestPower"}, code: http.StatusOK, chargepoint: "assignChargepoint", connector: 1},
+		{name: "LeastUsed", endpoint: "/reservations/assignChargepoint", body: map[string]any{"userId": "assignSecond", "minutes": 30, "minPower": 11, "policy": "LeastUsed"}, code: http.StatusOK, chargepoint: "assignChargepoint", connector: 2},
+		{name: "Site", endpoint: "/sites/assignSite/reservations", body: map[string]any{"userId": "assignThird", "minutes": 30, "plugType": "Type2"}, code: http.StatusOK, chargepoint: "assignOther", connector: 1},
+		{name: "BookedConnectorSkipped", endpoint: "/reservations/assignBooked", body: map[string]any{"userId": "assignFifth", "minutes": 30, "policy": "HighestPower"}, code: http.StatusOK, chargepoint: "assignBooked", connector: 2},
+		{name: "SiteFull", endpoint: "/sites/assignSite/reservations", body: map[string]any{"userId": "assignFourth", "minutes": 30, "plugType": "Type2"}, code: http.StatusConflict},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code, response := request(test.endpoint, test.body)
+			if code != test.code {
+				t.Fatalf("Expected code %d, but received %d", test.code, code)
+			}
+
+			if code == http.StatusOK && (response.Chargepoint != test.chargepoint || response.Connector != test.connector) {
+				t.Errorf("Expected connector %d of %s to be picked, but received connector %d of %s", test.connector, test.chargepoint, response.Connector, response.Chargepoint)
+			}
+		})
+	}
+}
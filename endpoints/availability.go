@@ -0,0 +1,294 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"reservations/availability"
+	"reservations/models"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Longest time window the availability can be searched in
+const availabilityMaxWindow = 7 * 24 * time.Hour
+
+// busyIntervals returns when each of the chargepoints' connectors is taken during the window, by chargepoint and connector. Reservations take their connector for the buffer of its policy before and after them as well.
+func busyIntervals(chargepoints []models.Chargepoint, policies map[string]map[int]models.ConnectorPolicy, window availability.Interval, collections Collections) (map[string]map[int][]availability.Interval, error) {
+	chargepointIDs := bson.A{}
+	busy := map[string]map[int][]availability.Interval{}
+	var maxBuffer time.Duration
+	for _, chargepoint := range chargepoints {
+		chargepointIDs = append(chargepointIDs, chargepoint.ID)
+		busy[chargepoint.ID] = map[int][]availability.Interval{}
+
+		for _, policy := range policies[chargepoint.ID] {
+			if buffer := time.Duration(policy.BufferMinutes) * time.Minute; buffer > maxBuffer {
+				maxBuffer = buffer
+			}
+		}
+	}
+
+	// Reservations just outside the window can still take it up with their buffer
+	cursor, err := collections.Reservations.Find(context.Background(), bson.M{
+		"chargepoint":         bson.M{"$in": chargepointIDs},
+		"hasFinishedCharging": false,
+		"startTime":           bson.M{"$lt": window.End.Add(maxBuffer)},
+		"chargingTime":        bson.M{"$gt": window.Start.Add(-maxBuffer)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var reservations []models.Reservation
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		return nil, err
+	}
+
+	for _, reservation := range reservations {
+		buffer := time.Duration(policies[reservation.Chargepoint][reservation.Connector].BufferMinutes) * time.Minute
+		busy[reservation.Chargepoint][reservation.Connector] = append(busy[reservation.Chargepoint][reservation.Connector], availability.Interval{Start: reservation.StartTime.Add(-buffer), End: reservation.ChargingTime.Add(buffer)})
+	}
+
+	windows, err := findMaintenance(chargepointIDs, window.Start, window.End, collections.Maintenance)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, chargepoint := range chargepoints {
+		for _, maintenance := range windows {
+			if maintenance.Chargepoint != chargepoint.ID {
+				continue
+			}
+
+			for _, connector := range chargepoint.Connectors {
+				if maintenance.Connector == 0 || maintenance.Connector == connector.ID {
+					busy[chargepoint.ID][connector.ID] = append(busy[chargepoint.ID][connector.ID], availability.Interval{Start: maintenance.Start, End: maintenance.End})
+				}
+			}
+		}
+	}
+
+	cursor, err = collections.Waitlist.Find(context.Background(), bson.M{"chargepoint": bson.M{"$in": chargepointIDs}, "status": WaitlistOffered})
+	if err != nil {
+		return nil, err
+	}
+
+	var offers []models.WaitlistEntry
+	if err := cursor.All(context.Background(), &offers); err != nil {
+		return nil, err
+	}
+
+	for _, offer := range offers {
+		busy[offer.Chargepoint][offer.OfferedConnector] = append(busy[offer.Chargepoint][offer.OfferedConnector], availability.Interval{Start: window.Start, End: offer.OfferExpiresAt})
+	}
+
+	// Connectors that are out of service, or in use without a reservation saying until when, are taken for the whole window
+	now := time.Now()
+	for _, chargepoint := range chargepoints {
+		for _, connector := range chargepoint.Connectors {
+			if connector.State == "Available" || connector.State == "Offered" {
+				continue
+			}
+
+			accounted := false
+			for _, interval := range busy[chargepoint.ID][connector.ID] {
+				if !interval.Start.After(now) && interval.End.After(now) {
+					accounted = true
+				}
+			}
+
+			if connector.State == "Unavailable" || !accounted {
+				busy[chargepoint.ID][connector.ID] = append(busy[chargepoint.ID][connector.ID], window)
+			}
+		}
+	}
+
+	return busy, nil
+}
+
+// SearchAvailability godoc
+// @Summary Search for free connectors in a time window
+// @Description Lists when connectors are free between from and to (at most 7 days apart, the part of the window that already passed is left out), for example to fill a booking calendar. The search can be narrowed down to a chargepoint, a site, the sites within the radius of a location, a plug type and a minimum power. Connectors are taken by their reservations (including the buffer of the connector's reservation policy before and after them), their maintenance windows and connectors offered from a waitlist. Unavailable connectors, and connectors in use without a reservation, are taken for the whole window. Every matching connector is listed, those that aren't free for at least the minimum minutes have no slots. Results are ordered by distance when a location is given.
+// @Tags Reservations
+// @Produce json
+// @Param from query string true "Start of the window (RFC 3339)"
+// @Param to query string true "End of the window (RFC 3339)"
+// @Param chargepoint query string false "Chargepoint ID"
+// @Param site query string false "Site ID"
+// @Param latitude query number false "Latitude of the location, in degrees"
+// @Param longitude query number false "Longitude of the location, in degrees"
+// @Param radius query number false "Search radius around the location in km, 10 by default"
+// @Param plugType query string false "Plug type"
+// @Param minPower query number false "Minimum power in kW"
+// @Param minutes query int false "Shortest slot in minutes, 30 by default"
+// @Success 200 {object} []models.ConnectorAvailability
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /availability [get]
+func SearchAvailability(c *gin.Context, collections Collections) {
+	from, err := time.Parse(time.RFC3339, c.Query("from"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "From must be an RFC 3339 time"})
+		return
+	}
+
+	to, err := time.Parse(time.RFC3339, c.Query("to"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "To must be an RFC 3339 time"})
+		return
+	}
+
+	if !to.After(from) || to.Sub(from) > availabilityMaxWindow {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The window must end after it starts, and last at most 7 days"})
+		return
+	}
+
+	if now := time.Now().Truncate(time.Minute); from.Before(now) {
+		from = now
+	}
+	if !to.After(from) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The window must end in the future"})
+		return
+	}
+
+	minutes := 30
+	if c.Query("minutes") != "" {
+		minutes, err = strconv.Atoi(c.Query("minutes"))
+		if err != nil || minutes <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Minutes must be a positive number"})
+			return
+		}
+	}
+
+	minPower := 0.0
+	if c.Query("minPower") != "" {
+		minPower, err = strconv.ParseFloat(c.Query("minPower"), 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The minimum power must be a number"})
+			return
+		}
+	}
+
+	filter := bson.M{}
+	if chargepointID := c.Query("chargepoint"); chargepointID != "" {
+		filter["_id"] = chargepointID
+	}
+	siteID := c.Query("site")
+	if siteID != "" {
+		filter["siteId"] = siteID
+	}
+
+	// Only chargepoints at sites within the radius are searched, closest first
+	distances := map[string]float64{}
+	if c.Query("latitude") != "" || c.Query("longitude") != "" {
+		latitude, latitudeErr := strconv.ParseFloat(c.Query("latitude"), 64)
+		longitude, longitudeErr := strconv.ParseFloat(c.Query("longitude"), 64)
+		if latitudeErr != nil || longitudeErr != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The latitude and longitude must both be numbers"})
+			return
+		}
+
+		radius := 10.0
+		if c.Query("radius") != "" {
+			radius, err = strconv.ParseFloat(c.Query("radius"), 64)
+			if err != nil || radius <= 0 {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The radius must be a positive number"})
+				return
+			}
+		}
+
+		var sites []models.Site
+		cursor, err := collections.Sites.Find(context.Background(), bson.M{"location": bson.M{"$exists": true}})
+		if err == nil {
+			err = cursor.All(context.Background(), &sites)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch sites"})
+			return
+		}
+
+		nearby := bson.A{}
+		for _, site := range sites {
+			distance := availability.Distance(latitude, longitude, site.Location.Latitude, site.Location.Longitude)
+			if distance > radius || (siteID != "" && site.ID != siteID) {
+				continue
+			}
+
+			distances[site.ID] = distance
+			nearby = append(nearby, site.ID)
+		}
+		filter["siteId"] = bson.M{"$in": nearby}
+	}
+
+	cursor, err := collections.Chargepoints.Find(context.Background(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	var chargepoints []models.Chargepoint
+	if err := cursor.All(context.Background(), &chargepoints); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	policies, err := connectorPolicies(chargepoints, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connectors' reservation policies"})
+		return
+	}
+
+	window := availability.Interval{Start: from, End: to}
+	busy, err := busyIntervals(chargepoints, policies, window, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connectors' bookings"})
+		return
+	}
+
+	results := []models.ConnectorAvailability{}
+	for _, chargepoint := range chargepoints {
+		for _, connector := range chargepoint.Connectors {
+			if plugType := c.Query("plugType"); plugType != "" && connector.PlugType != plugType {
+				continue
+			}
+			if minPower > 0 && connector.MaxPower < minPower {
+				continue
+			}
+
+			result := models.ConnectorAvailability{
+				Chargepoint:   chargepoint.ID,
+				SiteID:        chargepoint.SiteID,
+				Connector:     connector.ID,
+				State:         connector.State,
+				PlugType:      connector.PlugType,
+				CurrentType:   connector.CurrentType,
+				MaxPower:      connector.MaxPower,
+				BufferMinutes: policies[chargepoint.ID][connector.ID].BufferMinutes,
+				Distance:      roundQuantity(distances[chargepoint.SiteID]),
+				Slots:         []models.TimeSlot{},
+			}
+
+			for _, free := range availability.Free(window, busy[chargepoint.ID][connector.ID], time.Duration(minutes)*time.Minute) {
+				result.Slots = append(result.Slots, models.TimeSlot{Start: free.Start, End: free.End, Minutes: int(free.End.Sub(free.Start).Minutes())})
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Distance != results[j].Distance {
+			return results[i].Distance < results[j].Distance
+		}
+		if results[i].Chargepoint != results[j].Chargepoint {
+			return results[i].Chargepoint < results[j].Chargepoint
+		}
+		return results[i].Connector < results[j].Connector
+	})
+
+	c.JSON(http.StatusOK, results)
+}
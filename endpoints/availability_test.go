@@ -0,0 +1,158 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSearchAvailability(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.GET("/availability", func(c *gin.Context) {
+		SearchAvailability(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Sites, collections.Chargepoints, collections.Reservations, collections.Maintenance} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	from := time.Now().Add(time.Hour).Truncate(time.Minute)
+	at := func(minutes int) time.Time { return from.Add(time.Duration(minutes) * time.Minute) }
+
+	collections.Sites.InsertOne(context.Background(), models.Site{ID: "availabilityNear", Name: "Near", Location: &models.Location{Latitude: 52.3791, Longitude: 4.9003}})
+	collections.Sites.InsertOne(context.Background(), models.Site{ID: "availabilityFar", Name: "Far", Location: &models.Location{Latitude: 52.0894, Longitude: 5.1101}})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "availabilityNearChargepoint", SiteID: "availabilityNear", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "Type2", MaxPower: 22},
+		{ID: 2, State: "Available", PlugType: "Type2", MaxPower: 22},
+		{ID: 3, State: "Unavailable", PlugType: "Type2", MaxPower: 22},
+	}})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "availabilityFarChargepoint", SiteID: "availabilityFar", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "CCS", MaxPower: 50},
+	}})
+
+	// The first connector is booked in the middle of the window, the second is under maintenance at its end
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "availabilityNearChargepoint", Connector: 1, StartTime: at(60), ChargingTime: at(90)})
+	collections.Maintenance.InsertOne(context.Background(), models.MaintenanceWindow{Chargepoint: "availabilityNearChargepoint", Connector: 2, Start: at(100), End: at(200)})
+
+	search := func(query url.Values) (int, []models.ConnectorAvailability) {
+		req, _ := http.NewRequest("GET", "/availability?"+query.Encode(), nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var results []models.ConnectorAvailability
+		json.Unmarshal(recorder.Body.Bytes(), &results)
+		return recorder.Code, results
+	}
+
+	window := url.Values{"from": {at(0).Format(time.RFC3339)}, "to": {at(120).Format(time.RFC3339)}}
+
+	t.Run("InvalidWindow", func(t *testing.T) {
+		code, _ := search(url.Values{"from": {at(120).Format(time.RFC3339)}, "to": {at(0).Format(time.RFC3339)}})
+		if code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, but received %d", http.StatusBadRequest, code)
+		}
+	})
+
+	t.Run("Site", func(t *testing.T) {
+		query := url.Values{"site": {"availabilityNear"}}
+		for key, value := range window {
+			query[key] = value
+		}
+
+		code, results := search(query)
+		if code != http.StatusOK || len(results) != 3 {
+			t.Fatalf("Expected the site's 3 connectors, but received code %d and %v", code, results)
+		}
+
+		slots := [][]models.TimeSlot{
+			{{Start: at(0), End: at(60), Minutes: 60}, {Start: at(90), End: at(120), Minutes: 30}},
+			{{Start: at(0), End: at(100), Minutes: 100}},
+			{},
+		}
+
+		for i, result := range results {
+			if len(result.Slots) != len(slots[i]) {
+				t.Errorf("Expected connector %d to have %d slots, but received %v", result.Connector, len(slots[i]), result.Slots)
+				continue
+			}
+
+			for j, slot := range result.Slots {
+				if !slot.Start.Equal(slots[i][j].Start) || !slot.End.Equal(slots[i][j].End) || slot.Minutes != slots[i][j].Minutes {
+					t.Errorf("Expected connector %d to be free %v, but received %v", result.Connector, slots[i][j], slot)
+				}
+			}
+		}
+	})
+
+	t.Run("NearMe", func(t *testing.T) {
+		query := url.Values{"latitude": {"52.37"}, "longitude": {"4.89"}, "radius": {"5"}, "plugType": {"Type2"}, "minutes": {"90"}}
+		for key, value := range window {
+			query[key] = value
+		}
+
+		code, results := search(query)
+		if code != http.StatusOK || len(results) != 3 {
+			t.Fatalf("Expected only the nearby site's connectors, but received code %d and %v", code, results)
+		}
+
+		if results[0].Distance <= 0 || len(results[0].Slots) != 0 || len(results[1].Slots) != 1 {
+			t.Errorf("Expected only the second connector to be free for 90 minutes, but received %v", results)
+		}
+	})
+
+	t.Run("Buffer", func(t *testing.T) {
+		collections.Chargepoints.UpdateOne(context.Background(), bson.M{"_id": "availabilityFarChargepoint"}, bson.M{"$set": bson.M{"reservationPolicy": bson.M{"bufferMinutes": 15}}})
+		collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "availabilityFarChargepoint", Connector: 1, StartTime: at(60), ChargingTime: at(90)})
+
+		query := url.Values{"chargepoint": {"availabilityFarChargepoint"}, "minutes": {"10"}}
+		for key, value := range window {
+			query[key] = value
+		}
+
+		code, results := search(query)
+		if code != http.StatusOK || len(results) != 1 {
+			t.Fatalf("Expected the chargepoint's connector, but received code %d and %v", code, results)
+		}
+
+		slots := []models.TimeSlot{{Start: at(0), End: at(45), Minutes: 45}, {Start: at(105), End: at(120), Minutes: 15}}
+		if results[0].BufferMinutes != 15 || len(results[0].Slots) != len(slots) {
+			t.Fatalf("Expected the reservation to take up the buffer around it, but received %v", results[0])
+		}
+
+		for i, slot := range results[0].Slots {
+			if !slot.Start.Equal(slots[i].Start) || !slot.End.Equal(slots[i].End) {
+				t.Errorf("Expected the connector to be free %v, but received %v", slots[i], slot)
+			}
+		}
+	})
+}
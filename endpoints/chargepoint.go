@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"reservations/db"
 	"reservations/models"
@@ -15,16 +16,20 @@ import (
 
 // CreateChargepoint godoc
 // @Summary Create a new chargepoint
+// @Description Connector details are optional. When given, the plug type can be "Type1", "Type2", "CCS1", "CCS2", "CHAdeMO", "NACS" or "GBT", the current type either "AC" or "DC", and the maximum power is in kW.
 // @Tags Chargepoints
 // @Accept json
 // @Produce json
 // @Param id path string true "Chargepoint ID"
 // @Param body body CreateChargepointRequest true "Request body"
+// @Param Idempotency-Key header string false "Optional, retries with the same key get the first response instead of handling the request again"
 // @Success 200 {object} models.MessageResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
 // @Router /chargepoints/{id} [post]
-func CreateChargepoint(c *gin.Context, collection *mongo.Collection) {
+func CreateChargepoint(c *gin.Context, collections Collections) {
 	var newChargepoint models.Chargepoint
 
 	id := c.Param("id")
@@ -46,18 +51,60 @@ func CreateChargepoint(c *gin.Context, collection *mongo.Collection) {
 		return
 	}
 
+	if req.SiteID != "" {
+		_, err := FindSiteByID(req.SiteID, collections.Sites)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Site does not exist"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch sites"})
+			return
+		}
+	}
+
+	if len(req.ConnectorDetails) != 0 && len(req.ConnectorDetails) != req.Connectors {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector details must be given for every connector or none of them"})
+		return
+	}
+
 	connectors := make([]models.Connector, req.Connectors)
 	for i := 0; i < req.Connectors; i++ {
 		connectors[i] = models.Connector{
 			ID:    i + 1,
 			State: "Available",
 		}
+
+		if len(req.ConnectorDetails) == 0 {
+			continue
+		}
+
+		details := req.ConnectorDetails[i]
+		if !isPlugType(details.PlugType) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Unknown plug type %s", details.PlugType)})
+			return
+		}
+
+		if details.CurrentType != "AC" && details.CurrentType != "DC" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Current type must be either AC or DC"})
+			return
+		}
+
+		if details.MaxPower <= 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector power must exceed 0"})
+			return
+		}
+
+		connectors[i].PlugType = details.PlugType
+		connectors[i].CurrentType = details.CurrentType
+		connectors[i].MaxPower = details.MaxPower
 	}
 
 	newChargepoint.Connectors = connectors
 	newChargepoint.ID = id
+	newChargepoint.SiteID = req.SiteID
 
-	_, err := collection.InsertOne(context.Background(), newChargepoint)
+	_, err := collections.Chargepoints.InsertOne(context.Background(), newChargepoint)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create a new chargepoint, perhaps an existing ID was entered"})
 		return
@@ -68,6 +115,15 @@ func CreateChargepoint(c *gin.Context, collection *mongo.Collection) {
 
 type CreateChargepointRequest struct {
 	Connectors int `json:"connectors"`
+	// Optional, but when given there must be details for every connector
+	ConnectorDetails []ConnectorDetailsRequest `json:"connectorDetails"`
+	SiteID           string                    `json:"siteId"`
+}
+
+type ConnectorDetailsRequest struct {
+	PlugType    string  `json:"plugType"`
+	CurrentType string  `json:"currentType"`
+	MaxPower    float64 `json:"maxPower"`
 }
 
 // ChangeConnectorState godoc
@@ -170,18 +226,24 @@ func GetAllChargepoints(collection *mongo.Collection) ([]bson.M, error) {
 
 // Charge godoc
 // @Summary Start charging
-// @Description For a user to begin charging, they need to have an open reservation for the chargepoint and connector. They need to connect in the 10 minute "expiry" time period (time of reservation + 10 minutes), otherwise the reservation ends. If the user does connect in time, then they charge for the remainder of the "charging" time period specified in the reservation.
+// @Description For a user to begin charging, they need to have an open reservation for the chargepoint and connector. They need to connect in the "expiry" time period (start of the reservation + the grace period of the connector's reservation policy, 10 minutes by default), otherwise the reservation ends. If the user does connect in time, then they charge for the remainder of the "charging" time period specified in the reservation, and the reservation's deposit is given back. Charging starts a charging session, which records what actually happened.
+// @Description Users can also charge on an available connector without a reservation, for the requested minutes. The charging time is shortened so the vehicle leaves before someone else's later reservation on the connector (a reservation that started but whose user hasn't arrived yet counts too), minus the buffer of the connector's reservation policy, and charging is refused when that leaves less than 10 minutes. Charging is refused with a 409 while the connector is still in use, for instance by the vehicle before a reservation booked right after it. The user needs access to the connector like for a reservation: its reservation policy and hold, the user's quotas and organization limits, and no-show bans all apply. The estimated price is held on the user's wallet or pre-authorized on their card, like a reservation's.
 // @Tags Chargepoints
 // @Accept json
 // @Produce json
 // @Param chargepointID path string true "Chargepoint ID"
 // @Param connectorID path int true "Connector ID"
 // @Param body body ChargeRequest true "Request body"
+// @Param Idempotency-Key header string false "Optional, retries with the same key get the first response instead of handling the request again"
 // @Success 200 {object} models.MessageResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 402 {object} models.LimitErrorResponse
+// @Failure 403 {object} models.LimitErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
 // @Router /charge/{chargepointID}/{connectorID} [post]
-func Charge(c *gin.Context, reservationsCollection, chargepointsCollection, usersCollection *mongo.Collection) {
+func Charge(c *gin.Context, collections Collections) {
 	var req ChargeRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -189,7 +251,7 @@ func Charge(c *gin.Context, reservationsCollection, chargepointsCollection, user
 		return
 	}
 
-	_, err := FindUserByID(req.UserID, usersCollection)
+	user, err := FindUserByID(req.UserID, collections.Users)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not exist"})
@@ -205,7 +267,7 @@ func Charge(c *gin.Context, reservationsCollection, chargepointsCollection, user
 		return
 	}
 
-	chargepoint, err := FindChargepointByID(c.Param("cpID"), chargepointsCollection)
+	chargepoint, err := FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
@@ -221,16 +283,20 @@ func Charge(c *gin.Context, reservationsCollection, chargepointsCollection, user
 	}
 
 	var reservation models.Reservation
+	var warnings []string
 
 	reservationsFilter := bson.M{
-		"userId":             req.UserID,
-		"chargepoint":        c.Param("cpID"),
-		"connector":          connectorNumber,
-		"expiryTime":         bson.M{"$gt": time.Now()},
-		"hasStartedCharging": false,
+		"userId":              req.UserID,
+		"chargepoint":         c.Param("cpID"),
+		"connector":           connectorNumber,
+		"startTime":           bson.M{"$lte": time.Now()},
+		"expiryTime":          bson.M{"$gt": time.Now()},
+		"hasStartedCharging":  false,
+		"hasFinishedCharging": false,
 	}
-	err = reservationsCollection.FindOne(context.Background(), reservationsFilter).Decode(&reservation)
-	if err != nil {
+	err = collections.Reservations.FindOne(context.Background(), reservationsFilter).Decode(&reservation)
+	walkingIn := err == mongo.ErrNoDocuments && chargepoint.Connectors[connectorNumber-1].State == "Available"
+	if err != nil && !walkingIn {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not have an active reservation to the connector"})
 			return
@@ -239,23 +305,58 @@ func Charge(c *gin.Context, reservationsCollection, chargepointsCollection, user
 		return
 	}
 
-	chargepoint.Connectors[connectorNumber-1].State = "Charging"
-
-	_, err = chargepointsCollection.UpdateOne(context.Background(), bson.M{"_id": chargepoint.ID}, bson.M{"$set": bson.M{"connectors": chargepoint.Connectors}})
+	// The connector is claimed atomically, the previous vehicle may still be charging on it. A reservation that just started may not have been activated yet, see activateReservations.
+	claimed, err := setConnectorState(chargepoint.ID, connectorNumber, "Available", "Charging", collections.Chargepoints)
+	if err == nil && !claimed && !walkingIn {
+		claimed, err = setConnectorState(chargepoint.ID, connectorNumber, "Reserved", "Charging", collections.Chargepoints)
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the state of the connector"})
 		return
 	}
+	if !claimed {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "The connector is still in use"})
+		return
+	}
 
-	_, err = reservationsCollection.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{"hasStartedCharging": true}})
+	if walkingIn {
+		var ok bool
+		reservation, warnings, ok = walkIn(c, user, chargepoint, connectorNumber, req.Minutes, req.PaymentMethod, collections)
+		if !ok {
+			if _, err := setConnectorState(chargepoint.ID, connectorNumber, "Charging", "Available", collections.Chargepoints); err != nil {
+				fmt.Println("Error releasing claimed connector: ", err)
+			}
+			return
+		}
+	}
+
+	_, err = collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{"hasStartedCharging": true}})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update charging state of reservation"})
 		return
 	}
 
-	c.JSON(http.StatusOK, models.MessageResponse{Message: "Started charging on the connector"})
+	err = releaseDeposit(reservation, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not release the reservation's deposit"})
+		return
+	}
+
+	_, err = startSession(reservation, req.MeterStart, collections.Sessions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not start the charging session"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Started charging on the connector", Warnings: warnings})
 }
 
 type ChargeRequest struct {
 	UserID string `json:"userId"`
+	// Optional meter value in Wh when charging starts
+	MeterStart float64 `json:"meterStart"`
+	// Only used when charging without a reservation, defaults to 180 minutes
+	Minutes int `json:"minutes"`
+	// Only used when charging without a reservation, the gateway's payment method is required when card payments are enabled and the user has no wallet or organization
+	PaymentMethod string `json:"paymentMethod"`
 }
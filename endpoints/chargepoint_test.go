@@ -13,6 +13,8 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestChargepoints(t *testing.T) {
@@ -25,14 +27,15 @@ func TestChargepoints(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
 	}
-	chargepointsCollection := client.Database("TestDB").Collection("chargepoints")
-	usersCollection := client.Database("TestDB").Collection("users")
-	reservationsCollection := client.Database("TestDB").Collection("reservations")
+	collections := NewCollections(client.Database("TestDB"))
+	chargepointsCollection := collections.Chargepoints
+	usersCollection := collections.Users
+	reservationsCollection := collections.Reservations
 
 	router := gin.Default()
 
 	router.POST("/chargepoints/:id", func(c *gin.Context) {
-		CreateChargepoint(c, chargepointsCollection)
+		CreateChargepoint(c, collections)
 	})
 
 	router.GET("/chargepoints/:id", func(c *gin.Context) {
@@ -56,7 +59,7 @@ func TestChargepoints(t *testing.T) {
 	})
 
 	router.POST("/charge/:cpID/:coID", func(c *gin.Context) {
-		Charge(c, reservationsCollection, chargepointsCollection, usersCollection)
+		Charge(c, collections)
 	})
 
 	tests := []struct {
@@ -84,6 +87,10 @@ func TestChargepoints(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to clear collection:\n%v", err)
 		}
+		err = db.ClearCollection(collections.Sessions)
+		if err != nil {
+			t.Fatalf("Failed to clear collection:\n%v", err)
+		}
 		client.Disconnect(context.Background())
 	}()
 
@@ -134,7 +141,7 @@ func TestChargepoints(t *testing.T) {
 		}
 
 		_, err = reservationsCollection.InsertOne(context.Background(), models.Reservation{
-			ID:                  987,
+			ID:                  primitive.NewObjectID(),
 			Chargepoint:         "chargingChargepoint",
 			Connector:           1,
 			UserID:              "charger",
@@ -159,4 +166,54 @@ func TestChargepoints(t *testing.T) {
 			t.Logf("Received the correct code %d", recorder.Code)
 		}
 	})
+
+	t.Run("ChargeCancelled", func(t *testing.T) {
+		// The connector is still reserved for whoever reserved it next
+		chargepointsCollection.InsertOne(context.Background(), models.Chargepoint{ID: "cancelledChargepoint", Connectors: []models.Connector{{ID: 1, State: "Reserved"}}})
+		reservationsCollection.InsertOne(context.Background(), models.Reservation{
+			ID:                  primitive.NewObjectID(),
+			Chargepoint:         "cancelledChargepoint",
+			Connector:           1,
+			UserID:              "charger",
+			ExpiryTime:          time.Now().Add(time.Hour),
+			HasFinishedCharging: true,
+			Cancelled:           true,
+		})
+
+		body, _ := json.Marshal(map[string]string{"userId": "charger"})
+		req, _ := http.NewRequest("POST", "/charge/cancelledChargepoint/1", bytes.NewReader(body))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, but got %d", http.StatusBadRequest, recorder.Code)
+		}
+	})
+
+	t.Run("ChargeStillInUse", func(t *testing.T) {
+		// The reservation booked right after the previous one starts while its vehicle is still charging
+		chargepointsCollection.InsertOne(context.Background(), models.Chargepoint{ID: "busyChargepoint", Connectors: []models.Connector{{ID: 1, State: "Charging"}}})
+		reservationsCollection.InsertOne(context.Background(), models.Reservation{
+			ID:          primitive.NewObjectID(),
+			Chargepoint: "busyChargepoint",
+			Connector:   1,
+			UserID:      "charger",
+			StartTime:   time.Now().Add(-time.Minute),
+			ExpiryTime:  time.Now().Add(time.Hour),
+		})
+
+		body, _ := json.Marshal(map[string]string{"userId": "charger"})
+		req, _ := http.NewRequest("POST", "/charge/busyChargepoint/1", bytes.NewReader(body))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusConflict {
+			t.Errorf("Expected code %d, but got %d", http.StatusConflict, recorder.Code)
+		}
+
+		count, _ := collections.Sessions.CountDocuments(context.Background(), bson.M{"chargepoint": "busyChargepoint"})
+		if count != 0 {
+			t.Errorf("Expected no second session on the connector, but found %d", count)
+		}
+	})
 }
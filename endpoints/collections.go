@@ -0,0 +1,96 @@
+package endpoints
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collections groups the MongoDB collections used by endpoints that work across several of them
+type Collections struct {
+	Users           *mongo.Collection
+	Chargepoints    *mongo.Collection
+	Reservations    *mongo.Collection
+	NoShows         *mongo.Collection
+	Vehicles        *mongo.Collection
+	Organizations   *mongo.Collection
+	Sites           *mongo.Collection
+	Tariffs         *mongo.Collection
+	Sessions        *mongo.Collection
+	MeterValues     *mongo.Collection
+	Wallets         *mongo.Collection
+	Ledger          *mongo.Collection
+	Payments        *mongo.Collection
+	PaymentEvents   *mongo.Collection
+	Invoices        *mongo.Collection
+	Counters        *mongo.Collection
+	Fees            *mongo.Collection
+	Quotes          *mongo.Collection
+	Waitlist        *mongo.Collection
+	Maintenance     *mongo.Collection
+	Series          *mongo.Collection
+	Idempotency     *mongo.Collection
+	Notifications   *mongo.Collection
+	PriorityClasses *mongo.Collection
+	Compensations   *mongo.Collection
+}
+
+func NewCollections(database *mongo.Database) Collections {
+	return Collections{
+		Users:           database.Collection("users"),
+		Chargepoints:    database.Collection("chargepoints"),
+		Reservations:    database.Collection("reservations"),
+		NoShows:         database.Collection("noshows"),
+		Vehicles:        database.Collection("vehicles"),
+		Organizations:   database.Collection("organizations"),
+		Sites:           database.Collection("sites"),
+		Tariffs:         database.Collection("tariffs"),
+		Sessions:        database.Collection("sessions"),
+		MeterValues:     database.Collection("metervalues"),
+		Wallets:         database.Collection("wallets"),
+		Ledger:          database.Collection("ledger"),
+		Payments:        database.Collection("payments"),
+		PaymentEvents:   database.Collection("paymentevents"),
+		Invoices:        database.Collection("invoices"),
+		Counters:        database.Collection("counters"),
+		Fees:            database.Collection("fees"),
+		Quotes:          database.Collection("quotes"),
+		Waitlist:        database.Collection("waitlist"),
+		Maintenance:     database.Collection("maintenance"),
+		Series:          database.Collection("series"),
+		Idempotency:     database.Collection("idempotency"),
+		Notifications:   database.Collection("notifications"),
+		PriorityClasses: database.Collection("priorityclasses"),
+		Compensations:   database.Collection("compensations"),
+	}
+}
+
+// CreateIndexes creates the indexes the endpoints rely on, like the unique booking codes, if they don't exist yet. The Docker init script only runs on a fresh database, so they're created on every start as well.
+func CreateIndexes(collections Collections) error {
+	indexes := map[*mongo.Collection][]mongo.IndexModel{
+		// Invoices are numbered uniquely, and a user or organization is invoiced once per month
+		collections.Invoices: {
+			{Keys: bson.D{{Key: "number", Value: 1}}, Options: options.Index().SetUnique(true)},
+			{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "organizationId", Value: 1}, {Key: "periodStart", Value: 1}}, Options: options.Index().SetUnique(true)},
+		},
+		// Booking codes are given to customers, so no two reservations can share one
+		collections.Reservations: {
+			{Keys: bson.D{{Key: "code", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+			{Keys: bson.D{{Key: "legacyId", Value: 1}}, Options: options.Index().SetSparse(true)},
+		},
+		// Idempotency keys are forgotten once they expire
+		collections.Idempotency: {
+			{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+		},
+	}
+
+	for collection, indexModels := range indexes {
+		if _, err := collection.Indexes().CreateMany(context.Background(), indexModels); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
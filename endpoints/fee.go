@@ -0,0 +1,260 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"reservations/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Fee types
+const (
+	FeeNoShow  = "NoShow"
+	FeeIdle    = "Idle"
+	FeeDeposit = "Deposit"
+)
+
+// idleFeeMaxMinutes caps the idle fee of a vehicle whose unplugging is never reported
+func idleFeeMaxMinutes() int {
+	return envInt("IDLE_FEE_MAX_MINUTES", 120)
+}
+
+func feeDescription(fee models.Fee) string {
+	location := fmt.Sprintf("%s (%d)", fee.Chargepoint, fee.Connector)
+	switch fee.Type {
+	case FeeIdle:
+		return "Idle fee after charging ended, " + location
+	case FeeDeposit:
+		return "Forfeited deposit, " + location
+	}
+
+	return "No-show fee, " + location
+}
+
+// applyFee records the fee and takes it the way the reservation is paid for, from the user's wallet or from the card the reservation is paid with. Fees that can't be collected either way are left to the invoice.
+func applyFee(fee models.Fee, collections Collections) error {
+	return applyFees([]models.Fee{fee}, collections)
+}
+
+// applyFees records the fees of a user and takes them from their wallet, or all at once from the card authorization, which can only be captured once. When it was captured already, the card is charged again.
+func applyFees(fees []models.Fee, collections Collections) error {
+	if len(fees) == 0 {
+		return nil
+	}
+
+	total := int64(0)
+	for i := range fees {
+		fees[i].ID = primitive.NewObjectID()
+		_, err := collections.Fees.InsertOne(context.Background(), fees[i])
+		if err != nil {
+			return err
+		}
+		total += fees[i].Amount
+	}
+
+	mode, err := reservationPaymentMode(fees[0].ReservationID, fees[0].UserID, collections)
+	if err != nil || mode == PaymentModeInvoice {
+		return err
+	}
+
+	if mode == PaymentModeWallet {
+		for _, fee := range fees {
+			_, err = applyLedgerEntry(models.LedgerEntry{UserID: fee.UserID, Type: LedgerCapture, Amount: fee.Amount, ReservationID: fee.ReservationID, SessionID: fee.SessionID}, collections)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	authorized, err := findPayment(fees[0].ReservationID, PaymentAuthorized, collections.Payments)
+	if err != nil {
+		return err
+	}
+	if authorized != nil {
+		return capturePaymentAmount(fees[0].ReservationID, total, collections)
+	}
+
+	// Fees after the session was paid for, like idle fees, are charged to the same card separately
+	return chargeCard(fees[0].ReservationID, total, fees[0].Currency, collections)
+}
+
+// applyNoShowFee charges the tariff's no-show fee for a reservation that expired without charging, and keeps its deposit
+func applyNoShowFee(reservation models.Reservation, collections Collections) error {
+	tariff, err := findTariffOrFree(reservation.TariffID, collections.Tariffs)
+	if err != nil {
+		return err
+	}
+
+	fee := models.Fee{
+		UserID:         reservation.UserID,
+		OrganizationID: reservation.OrganizationID,
+		ReservationID:  reservation.ID,
+		Chargepoint:    reservation.Chargepoint,
+		Connector:      reservation.Connector,
+		Quantity:       1,
+		Unit:           "reservation",
+		Currency:       tariff.Currency,
+		Time:           reservation.ExpiryTime,
+	}
+
+	var fees []models.Fee
+	if tariff.NoShowFee > 0 {
+		noShow := fee
+		noShow.Type, noShow.UnitPrice, noShow.Amount = FeeNoShow, tariff.NoShowFee, tariff.NoShowFee
+		fees = append(fees, noShow)
+	}
+	if reservation.Deposit > 0 {
+		deposit := fee
+		deposit.Type, deposit.UnitPrice, deposit.Amount = FeeDeposit, reservation.Deposit, reservation.Deposit
+		fees = append(fees, deposit)
+	}
+
+	return applyFees(fees, collections)
+}
+
+// markUnplugged ends the idle time of the vehicle left on the connector, if there is one
+func markUnplugged(chargepointID string, connector int, sessionsCollection *mongo.Collection) error {
+	filter := bson.M{"chargepoint": chargepointID, "connector": connector, "idleSince": bson.M{"$exists": true}, "unpluggedAt": bson.M{"$exists": false}}
+	_, err := sessionsCollection.UpdateMany(context.Background(), filter, bson.M{"$set": bson.M{"unpluggedAt": time.Now()}})
+	return err
+}
+
+// checkIdleSessions charges the idle fee of vehicles that were unplugged, or that stayed plugged in for longer than the cap
+func checkIdleSessions(collections Collections) {
+	maxIdle := time.Duration(idleFeeMaxMinutes()) * time.Minute
+
+	filter := bson.M{
+		"idleSince":  bson.M{"$exists": true},
+		"idleBilled": bson.M{"$ne": true},
+		"$or": bson.A{
+			bson.M{"unpluggedAt": bson.M{"$exists": true}},
+			bson.M{"idleSince": bson.M{"$lte": time.Now().Add(-maxIdle)}},
+		},
+	}
+
+	cursor, err := collections.Sessions.Find(context.Background(), filter)
+	if err != nil {
+		fmt.Println("Error getting idle sessions: ", err)
+		return
+	}
+
+	var sessions []models.ChargingSession
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		fmt.Println("Error decoding idle sessions: ", err)
+		return
+	}
+
+	for _, session := range sessions {
+		// The session is left unbilled, so it's tried again on the next run
+		tariff, err := findTariffOrFree(session.TariffID, collections.Tariffs)
+		if err != nil {
+			fmt.Println("Error getting tariff: ", err)
+			continue
+		}
+
+		end := session.IdleSince.Add(maxIdle)
+		if !session.UnpluggedAt.IsZero() && session.UnpluggedAt.Before(end) {
+			end = session.UnpluggedAt
+		}
+		minutes := math.Floor(end.Sub(session.IdleSince).Minutes())
+
+		// Claim the session right before the fee is recorded, so its idle time is never billed twice
+		result, err := collections.Sessions.UpdateOne(context.Background(), bson.M{"_id": session.ID, "idleBilled": bson.M{"$ne": true}}, bson.M{"$set": bson.M{"idleBilled": true}})
+		if err != nil || result.ModifiedCount == 0 {
+			continue
+		}
+
+		if minutes <= 0 || tariff.IdleFee <= 0 {
+			continue
+		}
+
+		err = applyFee(models.Fee{
+			Type:           FeeIdle,
+			UserID:         session.UserID,
+			OrganizationID: session.OrganizationID,
+			ReservationID:  session.ReservationID,
+			SessionID:      session.ID,
+			Chargepoint:    session.Chargepoint,
+			Connector:      session.Connector,
+			Quantity:       minutes,
+			Unit:           "minute",
+			UnitPrice:      tariff.IdleFee,
+			Amount:         int64(minutes) * tariff.IdleFee,
+			Currency:       tariff.Currency,
+			Time:           end,
+		}, collections)
+		if err != nil {
+			fmt.Println("Error applying idle fee: ", err)
+		}
+	}
+}
+
+// Unplug godoc
+// @Summary Report that a vehicle was unplugged
+// @Description Called by the chargepoint when the cable is removed from the connector. A vehicle left plugged in after its reservation ended and charging stopped is charged the tariff's idle fee for every minute until then (up to IDLE_FEE_MAX_MINUTES, in case the unplugging is never reported). Starting a new session on the connector counts as unplugging too.
+// @Tags Sessions
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param connectorID path int true "Connector ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /unplug/{chargepointID}/{connectorID} [post]
+func Unplug(c *gin.Context, collections Collections) {
+	connectorNumber, err := strconv.Atoi(c.Param("coID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be a number"})
+		return
+	}
+
+	err = markUnplugged(c.Param("cpID"), connectorNumber, collections.Sessions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Vehicle unplugged"})
+}
+
+// GetFees godoc
+// @Summary Get fees
+// @Description Lists the no-show and idle fees applied by the background worker, newest first. The user and reservation filters are optional.
+// @Tags Fees
+// @Produce json
+// @Param userId query string false "User ID"
+// @Param reservationId query string false "Reservation ID"
+// @Success 200 {object} []models.Fee
+// @Failure 500 {object} models.ErrorResponse
+// @Router /fees [get]
+func GetFees(userID, reservationID string, collection *mongo.Collection) ([]models.Fee, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["userId"] = userID
+	}
+	if id, err := primitive.ObjectIDFromHex(reservationID); err == nil {
+		filter["reservationId"] = id
+	}
+
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.M{"time": -1}))
+	if err != nil {
+		return []models.Fee{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	fees := []models.Fee{}
+	if err := cursor.All(context.Background(), &fees); err != nil {
+		return []models.Fee{}, err
+	}
+
+	return fees, nil
+}
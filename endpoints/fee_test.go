@@ -0,0 +1,192 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestFees(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/unplug/:cpID/:coID", func(c *gin.Context) {
+		Unplug(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Tariffs, collections.Sessions, collections.Wallets, collections.Ledger, collections.NoShows, collections.Fees} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "feeUser", Name: "Late user"})
+	collections.Tariffs.InsertOne(context.Background(), models.Tariff{ID: "feeTariff", Name: "Fees", Currency: "EUR", IdleFee: 20, NoShowFee: 500})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "feeChargepoint", TariffID: "feeTariff", Connectors: []models.Connector{
+		{ID: 1, State: "Reserved"},
+	}})
+	applyLedgerEntry(models.LedgerEntry{UserID: "feeUser", Type: LedgerTopUp, Amount: 5000}, collections)
+
+	balance := func() int64 {
+		wallet, err := FindWalletByUserID("feeUser", collections.Wallets)
+		if err != nil {
+			t.Fatalf("Could not find the wallet:\n%v", err)
+		}
+		return wallet.Balance
+	}
+
+	t.Run("NoShowFee", func(t *testing.T) {
+		reservationID := primitive.NewObjectID()
+		collections.Reservations.InsertOne(context.Background(), models.Reservation{
+			ID:          reservationID,
+			Chargepoint: "feeChargepoint",
+			Connector:   1,
+			UserID:      "feeUser",
+			TariffID:    "feeTariff",
+			CreatedAt:   time.Now().Add(-time.Hour),
+			ExpiryTime:  time.Now().Add(-time.Minute),
+		})
+
+		checkNonChargingReservations(collections)
+
+		fees, err := GetFees("feeUser", reservationID.Hex(), collections.Fees)
+		if err != nil || len(fees) != 1 {
+			t.Fatalf("Expected one fee, but received %v (%v)", fees, err)
+		}
+
+		if fees[0].Type != FeeNoShow || fees[0].Amount != 500 {
+			t.Errorf("Expected a no-show fee of 500, but received a %s fee of %d", fees[0].Type, fees[0].Amount)
+		}
+
+		if balance() != 4500 {
+			t.Errorf("Expected the fee to be taken from the wallet, leaving 4500, but the balance is %d", balance())
+		}
+	})
+
+	t.Run("IdleFee", func(t *testing.T) {
+		reservationID := primitive.NewObjectID()
+		session := models.ChargingSession{
+			ID:            primitive.NewObjectID(),
+			ReservationID: reservationID,
+			UserID:        "feeUser",
+			Chargepoint:   "feeChargepoint",
+			Connector:     1,
+			TariffID:      "feeTariff",
+			Status:        SessionCompleted,
+			StartTime:     time.Now().Add(-2 * time.Hour),
+			StopTime:      time.Now().Add(-30 * time.Minute),
+			StopReason:    StopReasonReservationEnded,
+			IdleSince:     time.Now().Add(-30*time.Minute - 10*time.Second),
+		}
+		collections.Sessions.InsertOne(context.Background(), session)
+
+		// Still plugged in and under the cap, nothing to charge yet
+		checkIdleSessions(collections)
+		if fees, _ := GetFees("", reservationID.Hex(), collections.Fees); len(fees) != 0 {
+			t.Fatalf("Expected no idle fee before unplugging, but received %v", fees)
+		}
+
+		req, _ := http.NewRequest("POST", "/unplug/feeChargepoint/1", nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+
+		checkIdleSessions(collections)
+		checkIdleSessions(collections)
+
+		fees, err := GetFees("", reservationID.Hex(), collections.Fees)
+		if err != nil || len(fees) != 1 {
+			t.Fatalf("Expected the idle time to be charged once, but received %v (%v)", fees, err)
+		}
+
+		if fees[0].Type != FeeIdle || fees[0].Quantity != 30 || fees[0].Amount != 600 {
+			t.Errorf("Expected an idle fee of 30 minutes for 600, but received a %s fee of %v minutes for %d", fees[0].Type, fees[0].Quantity, fees[0].Amount)
+		}
+
+		var updated models.ChargingSession
+		collections.Sessions.FindOne(context.Background(), bson.M{"_id": session.ID}).Decode(&updated)
+		if updated.UnpluggedAt.IsZero() || !updated.IdleBilled {
+			t.Errorf("Expected the session to be unplugged and billed, but received %v", updated)
+		}
+	})
+
+	t.Run("InvoiceLines", func(t *testing.T) {
+		lines, _, err := invoiceLines("feeUser", "", time.Now().Add(-24*time.Hour), time.Now().Add(time.Hour), collections)
+		if err != nil {
+			t.Fatalf("Could not collect invoice lines:\n%v", err)
+		}
+
+		var fees int64
+		for _, line := range lines {
+			if line.Unit == "minute" && line.UnitPrice == 20 || line.Unit == "reservation" && line.UnitPrice == 500 {
+				fees += line.Amount
+			}
+		}
+
+		if fees != 1100 {
+			t.Errorf("Expected the invoice to bill 1100 in fees, but it bills %d", fees)
+		}
+	})
+
+	t.Run("Deposit", func(t *testing.T) {
+		before := balance()
+
+		// Kept on a no-show along with the fee
+		forfeitedID := primitive.NewObjectID()
+		placeHold("feeUser", forfeitedID, 1000, collections)
+		setConnectorState("feeChargepoint", 1, "Available", "Reserved", collections.Chargepoints)
+		collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: forfeitedID, Chargepoint: "feeChargepoint", Connector: 1, UserID: "feeUser", TariffID: "feeTariff", Deposit: 1000, CreatedAt: time.Now().Add(-time.Hour), ExpiryTime: time.Now().Add(-time.Minute)})
+
+		checkNonChargingReservations(collections)
+
+		fees, err := GetFees("feeUser", forfeitedID.Hex(), collections.Fees)
+		deposits := 0
+		for _, fee := range fees {
+			if fee.Type == FeeDeposit && fee.Amount == 1000 {
+				deposits++
+			}
+		}
+		if err != nil || len(fees) != 2 || deposits != 1 {
+			t.Fatalf("Expected a no-show fee and the forfeited deposit, but received %v (%v)", fees, err)
+		}
+		if balance() != before-1500 {
+			t.Errorf("Expected the fee and deposit to be taken from the wallet, leaving %d, but the balance is %d", before-1500, balance())
+		}
+
+		// Given back once the user charges
+		chargedID := primitive.NewObjectID()
+		placeHold("feeUser", chargedID, 1300, collections)
+		if err := releaseDeposit(models.Reservation{ID: chargedID, UserID: "feeUser", Deposit: 1000}, collections); err != nil {
+			t.Fatalf("Could not release the deposit:\n%v", err)
+		}
+		if _, held, _ := ledgerNet(chargedID, LedgerHold, LedgerRelease, collections.Ledger); held != 300 {
+			t.Errorf("Expected only the estimated price of 300 to stay held, but %d is", held)
+		}
+	})
+}
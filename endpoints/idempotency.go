@@ -0,0 +1,149 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// IdempotencyKeyHeader is the header clients send to make retrying a request safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// idempotencyTTL is how long responses are kept for retries
+func idempotencyTTL() time.Duration {
+	return time.Duration(envInt("IDEMPOTENCY_TTL_HOURS", 24)) * time.Hour
+}
+
+// recordingWriter keeps a copy of the response body while writing it
+type recordingWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w recordingWriter) WriteString(data string) (int, error) {
+	w.body.WriteString(data)
+	return w.ResponseWriter.WriteString(data)
+}
+
+// idempotencyScope tells who a request is made for, from the userId in its body, so keys are only shared by requests of the same user
+func idempotencyScope(body []byte) string {
+	var scope struct {
+		UserID string `json:"userId"`
+	}
+	json.Unmarshal(body, &scope)
+
+	return scope.UserID
+}
+
+// claimIdempotencyKey stores the key for the request, or returns the record that already holds it
+func claimIdempotencyKey(record models.IdempotencyRecord, collection *mongo.Collection) (*models.IdempotencyRecord, error) {
+	now := time.Now()
+	record.CreatedAt, record.ExpiresAt = now, now.Add(idempotencyTTL())
+
+	_, err := collection.InsertOne(context.Background(), record)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	// MongoDB removes expired keys about once a minute, until then they're replaced here
+	result, err := collection.DeleteOne(context.Background(), bson.M{"_id": record.ID, "expiresAt": bson.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+	if result.DeletedCount > 0 {
+		return claimIdempotencyKey(record, collection)
+	}
+
+	var existing models.IdempotencyRecord
+	err = collection.FindOne(context.Background(), bson.M{"_id": record.ID}).Decode(&existing)
+	if err != nil {
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+// Idempotent lets clients retry a request by sending the same Idempotency-Key header. The first response is stored for IDEMPOTENCY_TTL_HOURS and returned again to retries, instead of handling the request twice. Keys are kept per path and user, the one in the request body, so clients can't get each other's responses by guessing a key. A key can only be used for one request: reusing it with another method or body is refused. Server errors aren't stored, so the request can be retried with the same key.
+func Idempotent(collection *mongo.Collection) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		if len(key) > 255 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{Error: "The idempotency key can be at most 255 characters"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.Sum256([]byte(fmt.Sprintf("%s %s\n%s", c.Request.Method, c.Request.URL.Path, body)))
+		requestHash := hex.EncodeToString(hash[:])
+
+		record := models.IdempotencyRecord{Key: key, Path: c.Request.URL.Path, UserID: idempotencyScope(body), RequestHash: requestHash}
+		id := sha256.Sum256([]byte(fmt.Sprintf("%s\n%s\n%s", record.UserID, record.Path, record.Key)))
+		record.ID = hex.EncodeToString(id[:])
+
+		existing, err := claimIdempotencyKey(record, collection)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not check the idempotency key"})
+			return
+		}
+
+		if existing != nil {
+			if existing.RequestHash != requestHash {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, models.ErrorResponse{Error: "The idempotency key was already used for a different request"})
+				return
+			}
+
+			if !existing.Completed {
+				c.AbortWithStatusJSON(http.StatusConflict, models.ErrorResponse{Error: "A request with this idempotency key is still being handled"})
+				return
+			}
+
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(existing.Status, "application/json; charset=utf-8", existing.Body)
+			c.Abort()
+			return
+		}
+
+		writer := recordingWriter{ResponseWriter: c.Writer, body: &bytes.Buffer{}}
+		c.Writer = writer
+		c.Next()
+
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			_, err = collection.DeleteOne(context.Background(), bson.M{"_id": record.ID})
+		} else {
+			_, err = collection.UpdateOne(context.Background(), bson.M{"_id": record.ID}, bson.M{"$set": bson.M{"completed": true, "status": c.Writer.Status(), "body": writer.body.Bytes()}})
+		}
+		if err != nil {
+			fmt.Println("Error storing idempotent response: ", err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestIdempotent(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/users/:id", Idempotent(collections.Idempotency), func(c *gin.Context) {
+		CreateUser(c, collections.Users)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Idempotency} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	request := func(path, key string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(encoded))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		key      string
+		body     any
+		code     int
+		replayed bool
+	}{
+		{name: "First", path: "/users/idempotentUser", key: "retry-me", body: map[string]any{"name": "Retrier"}, code: http.StatusOK},
+		{name: "Retry", path: "/users/idempotentUser", key: "retry-me", body: map[string]any{"name": "Retrier"}, code: http.StatusOK, replayed: true},
+		{name: "WithoutKey", path: "/users/idempotentUser", body: map[string]any{"name": "Retrier"}, code: http.StatusInternalServerError},
+		{name: "DifferentPayload", path: "/users/idempotentUser", key: "retry-me", body: map[string]any{"name": "Someone else"}, code: http.StatusUnprocessableEntity},
+		{name: "OtherPath", path: "/users/otherIdempotentUser", key: "retry-me", body: map[string]any{"name": "Someone else"}, code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := request(test.path, test.key, test.body)
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			}
+
+			if replayed := recorder.Header().Get("Idempotent-Replayed") == "true"; replayed != test.replayed {
+				t.Errorf("Expected the response to be replayed: %v, but it was: %v", test.replayed, replayed)
+			}
+		})
+	}
+
+	count, _ := collections.Users.CountDocuments(context.Background(), bson.M{"_id": "idempotentUser"})
+	if count != 1 {
+		t.Errorf("Expected the user to be created once, but found %d", count)
+	}
+}
@@ -0,0 +1,529 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reservations/billing"
+	"reservations/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var errAlreadyInvoiced = errors.New("the period was already invoiced")
+
+// taxRate is the tax added to invoices, in percent
+func taxRate() float64 {
+	rate, err := strconv.ParseFloat(os.Getenv("TAX_RATE"), 64)
+	if err != nil || rate < 0 {
+		return 0
+	}
+
+	return rate
+}
+
+// parsePeriod parses a "YYYY-MM" calendar month into its start and the start of the next month
+func parsePeriod(period string) (time.Time, time.Time, error) {
+	month, err := time.ParseInLocation("2006-01", period, time.Local)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	return month, month.AddDate(0, 1, 0), nil
+}
+
+// partyFilter matches the documents billed to the user or organization. Members of an organization are billed through it, so a user's invoice leaves out what they did on the organization's behalf.
+func partyFilter(userID, organizationID string) bson.M {
+	if organizationID != "" {
+		return bson.M{"organizationId": organizationID}
+	}
+
+	return bson.M{"userId": userID, "organizationId": bson.M{"$in": bson.A{nil, ""}}}
+}
+
+func priceLines(price models.Price, date time.Time, location string, reservationID primitive.ObjectID, sessionID primitive.ObjectID) []models.InvoiceLine {
+	lines := []models.InvoiceLine{}
+	for _, component := range price.Components {
+		lines = append(lines, models.InvoiceLine{
+			Date:          date,
+			Description:   component.Description + ", " + location,
+			Quantity:      component.Quantity,
+			Unit:          component.Unit,
+			UnitPrice:     component.UnitPrice,
+			Amount:        component.Amount,
+			ReservationID: reservationID,
+			SessionID:     sessionID,
+		})
+	}
+
+	return lines
+}
+
+// paymentLines lists what the user paid in the period, from their wallet or by card, and what was refunded to them as negative amounts. They are shown below the invoice's total, so the invoice only asks for what is still due.
+func paymentLines(userID string, start, end time.Time, collections Collections) ([]models.InvoiceLine, error) {
+	lines := []models.InvoiceLine{}
+
+	cursor, err := collections.Ledger.Find(context.Background(), bson.M{
+		"userId": userID,
+		"type":   bson.M{"$in": bson.A{LedgerCapture, LedgerRefund}},
+		"time":   bson.M{"$gte": start, "$lt": end},
+	}, options.Find().SetSort(bson.M{"time": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []models.LedgerEntry
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		line := models.InvoiceLine{Date: entry.Time, Quantity: 1, Unit: "payment", ReservationID: entry.ReservationID, SessionID: entry.SessionID}
+		if entry.Type == LedgerCapture {
+			line.Description, line.Amount = "Paid from wallet", entry.Amount
+		} else {
+			line.Description, line.Amount = "Refunded to wallet", -entry.Amount
+		}
+		line.UnitPrice = line.Amount
+		lines = append(lines, line)
+	}
+
+	cursor, err = collections.Payments.Find(context.Background(), bson.M{
+		"userId": userID,
+		"$or": bson.A{
+			bson.M{"capturedAt": bson.M{"$gte": start, "$lt": end}},
+			bson.M{"refundedAt": bson.M{"$gte": start, "$lt": end}},
+		},
+	}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var payments []models.Payment
+	if err := cursor.All(context.Background(), &payments); err != nil {
+		return nil, err
+	}
+
+	within := func(t time.Time) bool { return !t.Before(start) && t.Before(end) }
+	for _, payment := range payments {
+		if within(payment.CapturedAt) && payment.Captured > 0 {
+			lines = append(lines, models.InvoiceLine{Date: payment.CapturedAt, Description: "Paid by card", Quantity: 1, Unit: "payment", UnitPrice: payment.Captured, Amount: payment.Captured, ReservationID: payment.ReservationID})
+		}
+		if within(payment.RefundedAt) && payment.Refunded > 0 {
+			lines = append(lines, models.InvoiceLine{Date: payment.RefundedAt, Description: "Refunded to card", Quantity: 1, Unit: "payment", UnitPrice: -payment.Refunded, Amount: -payment.Refunded, ReservationID: payment.ReservationID})
+		}
+	}
+
+	return lines, nil
+}
+
+// invoiceLines collects everything billed to the party in the period: the sessions that stopped in it, the fees of reservations made in it that never led to a session, and the no-show and idle fees applied in it.
+func invoiceLines(userID, organizationID string, start, end time.Time, collections Collections) ([]models.InvoiceLine, string, error) {
+	lines := []models.InvoiceLine{}
+	currency := ""
+
+	sessionsFilter := partyFilter(userID, organizationID)
+	sessionsFilter["status"] = SessionCompleted
+	sessionsFilter["stopTime"] = bson.M{"$gte": start, "$lt": end}
+
+	cursor, err := collections.Sessions.Find(context.Background(), sessionsFilter, options.Find().SetSort(bson.M{"startTime": 1}))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var sessions []models.ChargingSession
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		return nil, "", err
+	}
+
+	for _, session := range sessions {
+		price, err := GetSessionPrice(session, collections)
+		if err != nil {
+			return nil, "", err
+		}
+
+		currency = price.Currency
+		location := fmt.Sprintf("%s (%d)", session.Chargepoint, session.Connector)
+		lines = append(lines, priceLines(price, session.StartTime, location, session.ReservationID, session.ID)...)
+	}
+
+	reservationsFilter := partyFilter(userID, organizationID)
+	reservationsFilter["hasStartedCharging"] = false
+	reservationsFilter["hasFinishedCharging"] = true
+	reservationsFilter["cancelled"] = bson.M{"$ne": true}
+	reservationsFilter["createdAt"] = bson.M{"$gte": start, "$lt": end}
+
+	cursor, err = collections.Reservations.Find(context.Background(), reservationsFilter, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var reservations []models.Reservation
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		return nil, "", err
+	}
+
+	for _, reservation := range reservations {
+		price, err := GetReservationPrice(reservation, collections)
+		if err != nil {
+			return nil, "", err
+		}
+
+		currency = price.Currency
+		location := fmt.Sprintf("%s (%d)", reservation.Chargepoint, reservation.Connector)
+		lines = append(lines, priceLines(price, reservation.CreatedAt, location, reservation.ID, primitive.NilObjectID)...)
+	}
+
+	feesFilter := partyFilter(userID, organizationID)
+	feesFilter["time"] = bson.M{"$gte": start, "$lt": end}
+
+	cursor, err = collections.Fees.Find(context.Background(), feesFilter, options.Find().SetSort(bson.M{"time": 1}))
+	if err != nil {
+		return nil, "", err
+	}
+
+	var fees []models.Fee
+	if err := cursor.All(context.Background(), &fees); err != nil {
+		return nil, "", err
+	}
+
+	for _, fee := range fees {
+		currency = fee.Currency
+		lines = append(lines, models.InvoiceLine{
+			Date:          fee.Time,
+			Description:   feeDescription(fee),
+			Quantity:      fee.Quantity,
+			Unit:          fee.Unit,
+			UnitPrice:     fee.UnitPrice,
+			Amount:        fee.Amount,
+			ReservationID: fee.ReservationID,
+			SessionID:     fee.SessionID,
+		})
+	}
+
+	if currency == "" {
+		currency = defaultCurrency()
+	}
+
+	return lines, currency, nil
+}
+
+// nextInvoiceNumber numbers invoices sequentially per year
+func nextInvoiceNumber(year int, collection *mongo.Collection) (string, error) {
+	var counter struct {
+		Sequence int `bson:"sequence"`
+	}
+
+	id := fmt.Sprintf("invoice-%d", year)
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := collection.FindOneAndUpdate(context.Background(), bson.M{"_id": id}, bson.M{"$inc": bson.M{"sequence": 1}}, opts).Decode(&counter)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("INV-%d-%06d", year, counter.Sequence), nil
+}
+
+// issueInvoice bills the party for the period. It returns nil when there is nothing to bill, and errAlreadyInvoiced when the period already has an invoice.
+func issueInvoice(userID, organizationID string, start, end time.Time, collections Collections) (*models.Invoice, error) {
+	filter := partyFilter(userID, organizationID)
+	filter["periodStart"] = start
+
+	existing, err := collections.Invoices.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, errAlreadyInvoiced
+	}
+
+	lines, currency, err := invoiceLines(userID, organizationID, start, end, collections)
+	if err != nil || len(lines) == 0 {
+		return nil, err
+	}
+
+	// Organizations are billed for everything, only users without one pay up front
+	var paid []models.InvoiceLine
+	if organizationID == "" {
+		paid, err = paymentLines(userID, start, end, collections)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	number, err := nextInvoiceNumber(now.Year(), collections.Counters)
+	if err != nil {
+		return nil, err
+	}
+
+	invoice := models.Invoice{
+		ID:             primitive.NewObjectID(),
+		Number:         number,
+		UserID:         userID,
+		OrganizationID: organizationID,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		Currency:       currency,
+		Lines:          lines,
+		Payments:       paid,
+		TaxRate:        taxRate(),
+		IssuedAt:       now,
+	}
+	billing.Total(&invoice)
+
+	_, err = collections.Invoices.InsertOne(context.Background(), invoice)
+	if mongo.IsDuplicateKeyError(err) {
+		return nil, errAlreadyInvoiced
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &invoice, nil
+}
+
+// billedParties lists the users (without an organization) and organizations that have sessions, reservations or fees in the period
+func billedParties(start, end time.Time, collections Collections) ([]string, []string, error) {
+	users := map[string]bool{}
+	organizations := map[string]bool{}
+
+	collect := func(collection *mongo.Collection, filter bson.M) error {
+		cursor, err := collection.Find(context.Background(), filter, options.Find().SetProjection(bson.M{"userId": 1, "organizationId": 1}))
+		if err != nil {
+			return err
+		}
+
+		var documents []struct {
+			UserID         string `bson:"userId"`
+			OrganizationID string `bson:"organizationId"`
+		}
+		if err := cursor.All(context.Background(), &documents); err != nil {
+			return err
+		}
+
+		for _, document := range documents {
+			if document.OrganizationID != "" {
+				organizations[document.OrganizationID] = true
+			} else {
+				users[document.UserID] = true
+			}
+		}
+		return nil
+	}
+
+	if err := collect(collections.Sessions, bson.M{"status": SessionCompleted, "stopTime": bson.M{"$gte": start, "$lt": end}}); err != nil {
+		return nil, nil, err
+	}
+	if err := collect(collections.Reservations, bson.M{"hasStartedCharging": false, "hasFinishedCharging": true, "createdAt": bson.M{"$gte": start, "$lt": end}}); err != nil {
+		return nil, nil, err
+	}
+	if err := collect(collections.Fees, bson.M{"time": bson.M{"$gte": start, "$lt": end}}); err != nil {
+		return nil, nil, err
+	}
+
+	userIDs, organizationIDs := []string{}, []string{}
+	for id := range users {
+		userIDs = append(userIDs, id)
+	}
+	for id := range organizations {
+		organizationIDs = append(organizationIDs, id)
+	}
+
+	return userIDs, organizationIDs, nil
+}
+
+// RunBilling godoc
+// @Summary Run billing for a month
+// @Description Issues invoices for a calendar month that has ended. With a user or organization, only that party is billed, otherwise every party with billable sessions or reservations is. Invoices bill completed sessions, the fees of reservations that were never charged on and the no-show and idle fees, with tax (TAX_RATE) added over the subtotal. What users paid from their wallet or by card in the month is listed below the total, followed by the amount paid and the balance due. Members of an organization are billed through it. Parties that were already invoiced for the month are skipped.
+// @Tags Invoices
+// @Accept json
+// @Produce json
+// @Param body body BillingRunRequest true "Request body"
+// @Success 200 {object} []models.Invoice
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /invoices/run [post]
+func RunBilling(c *gin.Context, collections Collections) {
+	var req BillingRunRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	start, end, err := parsePeriod(req.Period)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Period must be a month like 2026-01"})
+		return
+	}
+
+	if end.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Only months that have ended can be billed"})
+		return
+	}
+
+	if req.UserID != "" && req.OrganizationID != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Bill either a user or an organization"})
+		return
+	}
+
+	invoices := []models.Invoice{}
+
+	if req.UserID != "" || req.OrganizationID != "" {
+		invoice, err := issueInvoice(req.UserID, req.OrganizationID, start, end, collections)
+		if err != nil {
+			if err == errAlreadyInvoiced {
+				c.JSON(http.StatusConflict, models.ErrorResponse{Error: "The month was already invoiced"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not issue the invoice"})
+			return
+		}
+
+		if invoice != nil {
+			invoices = append(invoices, *invoice)
+		}
+		c.JSON(http.StatusOK, invoices)
+		return
+	}
+
+	userIDs, organizationIDs, err := billedParties(start, end, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not find who to bill"})
+		return
+	}
+
+	issue := func(userID, organizationID string) error {
+		invoice, err := issueInvoice(userID, organizationID, start, end, collections)
+		if err == errAlreadyInvoiced {
+			return nil
+		}
+		if invoice != nil {
+			invoices = append(invoices, *invoice)
+		}
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := issue(userID, ""); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not issue the invoice of user " + userID})
+			return
+		}
+	}
+	for _, organizationID := range organizationIDs {
+		if err := issue("", organizationID); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not issue the invoice of organization " + organizationID})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, invoices)
+}
+
+type BillingRunRequest struct {
+	// Calendar month like "2026-01"
+	Period string `json:"period"`
+	// Optional, bills only this user or organization
+	UserID         string `json:"userId"`
+	OrganizationID string `json:"organizationId"`
+}
+
+// GetInvoices godoc
+// @Summary Get invoices
+// @Description Lists invoices, newest first. The user and organization filters are optional.
+// @Tags Invoices
+// @Produce json
+// @Param userId query string false "User ID"
+// @Param organizationId query string false "Organization ID"
+// @Success 200 {object} []models.Invoice
+// @Failure 500 {object} models.ErrorResponse
+// @Router /invoices [get]
+func GetInvoices(userID, organizationID string, collection *mongo.Collection) ([]models.Invoice, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["userId"] = userID
+	}
+	if organizationID != "" {
+		filter["organizationId"] = organizationID
+	}
+
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.M{"issuedAt": -1}))
+	if err != nil {
+		return []models.Invoice{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	invoices := []models.Invoice{}
+	if err := cursor.All(context.Background(), &invoices); err != nil {
+		return []models.Invoice{}, err
+	}
+
+	return invoices, nil
+}
+
+// FindInvoiceByID godoc
+// @Summary Get an invoice by ID
+// @Tags Invoices
+// @Produce json
+// @Param id path string true "Invoice ID"
+// @Success 200 {object} models.Invoice
+// @Failure 404 {object} models.ErrorResponse
+// @Router /invoices/{id} [get]
+func FindInvoiceByID(id string, collection *mongo.Collection) (models.Invoice, error) {
+	var invoice models.Invoice
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Invoice{}, mongo.ErrNoDocuments
+	}
+
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&invoice)
+	if err != nil {
+		return models.Invoice{}, err
+	}
+
+	return invoice, nil
+}
+
+// GetInvoiceCSV godoc
+// @Summary Download an invoice as CSV
+// @Tags Invoices
+// @Produce text/csv
+// @Param id path string true "Invoice ID"
+// @Success 200 {file} file
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /invoices/{id}/csv [get]
+func GetInvoiceCSV(c *gin.Context, invoice models.Invoice) {
+	rendered, err := billing.RenderCSV(invoice)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not render the invoice"})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename="+invoice.Number+".csv")
+	c.Data(http.StatusOK, "text/csv", rendered)
+}
+
+// GetInvoicePDF godoc
+// @Summary Download an invoice as PDF
+// @Tags Invoices
+// @Produce application/pdf
+// @Param id path string true "Invoice ID"
+// @Success 200 {file} file
+// @Failure 404 {object} models.ErrorResponse
+// @Router /invoices/{id}/pdf [get]
+func GetInvoicePDF(c *gin.Context, invoice models.Invoice) {
+	c.Header("Content-Disposition", "attachment; filename="+invoice.Number+".pdf")
+	c.Data(http.StatusOK, "application/pdf", billing.RenderPDF(invoice))
+}
@@ -0,0 +1,180 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestInvoices(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	os.Setenv("TAX_RATE", "21")
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/invoices/run", func(c *gin.Context) {
+		RunBilling(c, collections)
+	})
+
+	router.GET("/invoices/:id/csv", func(c *gin.Context) {
+		invoice, err := FindInvoiceByID(c.Param("id"), collections.Invoices)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Invoice not found"})
+			return
+		}
+		GetInvoiceCSV(c, invoice)
+	})
+
+	router.GET("/invoices/:id/pdf", func(c *gin.Context) {
+		invoice, err := FindInvoiceByID(c.Param("id"), collections.Invoices)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Invoice not found"})
+			return
+		}
+		GetInvoicePDF(c, invoice)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Tariffs, collections.Reservations, collections.Sessions, collections.Invoices, collections.Counters, collections.Ledger} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	lastMonth := thisMonth.AddDate(0, -1, 0)
+	charged := lastMonth.AddDate(0, 0, 10).Add(10 * time.Hour)
+
+	collections.Tariffs.InsertOne(context.Background(), models.Tariff{ID: "invoiceTariff", Name: "Per minute", Currency: "EUR", PerMinute: 10, ReservationFee: 50})
+	chargedID := primitive.NewObjectID()
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: chargedID, Chargepoint: "invoiceChargepoint", Connector: 1, UserID: "invoicedUser", TariffID: "invoiceTariff", CreatedAt: charged, HasStartedCharging: true, HasFinishedCharging: true})
+	collections.Sessions.InsertOne(context.Background(), models.ChargingSession{ID: primitive.NewObjectID(), ReservationID: chargedID, UserID: "invoicedUser", Chargepoint: "invoiceChargepoint", Connector: 1, TariffID: "invoiceTariff", Status: SessionCompleted, StartTime: charged, StopTime: charged.Add(30 * time.Minute)})
+	// A no-show is still billed the reservation fee, a cancelled reservation isn't
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "invoiceChargepoint", Connector: 1, UserID: "invoicedUser", TariffID: "invoiceTariff", CreatedAt: charged.Add(24 * time.Hour), HasFinishedCharging: true})
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "invoiceChargepoint", Connector: 1, UserID: "invoicedUser", TariffID: "invoiceTariff", CreatedAt: charged.Add(48 * time.Hour), HasFinishedCharging: true, Cancelled: true})
+
+	run := func(body map[string]any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", "/invoices/run", bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	tests := []struct {
+		name string
+		body map[string]any
+		code int
+	}{
+		{name: "InvalidPeriod", body: map[string]any{"period": "last month"}, code: http.StatusBadRequest},
+		{name: "PeriodNotEnded", body: map[string]any{"period": thisMonth.Format("2006-01")}, code: http.StatusBadRequest},
+		{name: "UserAndOrganization", body: map[string]any{"period": lastMonth.Format("2006-01"), "userId": "invoicedUser", "organizationId": "invoicedOrganization"}, code: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := run(test.body).Code; code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+
+	var invoice models.Invoice
+
+	t.Run("Run", func(t *testing.T) {
+		recorder := run(map[string]any{"period": lastMonth.Format("2006-01")})
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+
+		var invoices []models.Invoice
+		json.Unmarshal(recorder.Body.Bytes(), &invoices)
+		if len(invoices) != 1 {
+			t.Fatalf("Expected one invoice, but received %d", len(invoices))
+		}
+		invoice = invoices[0]
+
+		// Reservation fee and 30 minutes for the session, and the no-show's reservation fee
+		if len(invoice.Lines) != 3 || invoice.Subtotal != 400 || invoice.Tax != 84 || invoice.Total != 484 {
+			t.Errorf("Expected 3 lines adding up to 400 plus 84 tax, but received %v", invoice)
+		}
+
+		if invoice.Number != now.Format("INV-2006-000001") {
+			t.Errorf("Expected the first invoice number of the year, but received %s", invoice.Number)
+		}
+	})
+
+	t.Run("AlreadyInvoiced", func(t *testing.T) {
+		if code := run(map[string]any{"period": lastMonth.Format("2006-01"), "userId": "invoicedUser"}).Code; code != http.StatusConflict {
+			t.Errorf("Expected code %d, but received %d", http.StatusConflict, code)
+		}
+
+		if recorder := run(map[string]any{"period": lastMonth.Format("2006-01")}); recorder.Body.String() != "[]" {
+			t.Errorf("Expected a second run to skip the invoiced user, but received %s", recorder.Body.String())
+		}
+	})
+
+	t.Run("FullyPrepaid", func(t *testing.T) {
+		paidID := primitive.NewObjectID()
+		sessionID := primitive.NewObjectID()
+		collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: paidID, Chargepoint: "invoiceChargepoint", Connector: 1, UserID: "prepaidInvoicedUser", TariffID: "invoiceTariff", CreatedAt: charged, HasStartedCharging: true, HasFinishedCharging: true})
+		collections.Sessions.InsertOne(context.Background(), models.ChargingSession{ID: sessionID, ReservationID: paidID, UserID: "prepaidInvoicedUser", Chargepoint: "invoiceChargepoint", Connector: 1, TariffID: "invoiceTariff", Status: SessionCompleted, StartTime: charged, StopTime: charged.Add(30 * time.Minute)})
+		collections.Ledger.InsertOne(context.Background(), models.LedgerEntry{ID: primitive.NewObjectID(), UserID: "prepaidInvoicedUser", Type: LedgerCapture, Amount: 424, ReservationID: paidID, SessionID: sessionID, Time: charged.Add(30 * time.Minute)})
+
+		recorder := run(map[string]any{"period": lastMonth.Format("2006-01"), "userId": "prepaidInvoicedUser"})
+		var invoices []models.Invoice
+		json.Unmarshal(recorder.Body.Bytes(), &invoices)
+		if len(invoices) != 1 {
+			t.Fatalf("Expected one invoice, but received %s", recorder.Body.String())
+		}
+
+		// The session's two lines add up to 350, taxed like any other, and the wallet payment settles them
+		paid := invoices[0]
+		if len(paid.Lines) != 2 || paid.Subtotal != 350 || paid.Tax != 74 || paid.Total != 424 {
+			t.Errorf("Expected 2 lines adding up to 350 plus 74 tax, but received %v", paid)
+		}
+		if len(paid.Payments) != 1 || paid.AmountPaid != 424 || paid.BalanceDue != 0 {
+			t.Errorf("Expected the wallet payment to settle the invoice, but received %v", paid)
+		}
+	})
+
+	t.Run("Renderings", func(t *testing.T) {
+		for format, contentType := range map[string]string{"csv": "text/csv", "pdf": "application/pdf"} {
+			req, _ := http.NewRequest("GET", "/invoices/"+invoice.ID.Hex()+"/"+format, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != http.StatusOK || recorder.Header().Get("Content-Type") != contentType {
+				t.Errorf("Expected a %s rendering, but received %d %s", contentType, recorder.Code, recorder.Header().Get("Content-Type"))
+			}
+		}
+	})
+}
@@ -0,0 +1,264 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"reservations/loadmanagement"
+	"reservations/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// QuotaSiteCapacity is returned when the site's grid connection can't power another reservation
+const QuotaSiteCapacity = "SITE_CAPACITY"
+
+// minChargingPower is the lowest power (in kW) a reservation is downgraded to before it's refused
+func minChargingPower() float64 {
+	power, err := strconv.ParseFloat(os.Getenv("MIN_CHARGING_POWER"), 64)
+	if err != nil {
+		return 3.7
+	}
+
+	return power
+}
+
+// connectorDemand is the most power (in kW) a reservation or session can draw from the connector
+func connectorDemand(connector models.Connector, powerLimit float64) float64 {
+	if powerLimit > 0 && (connector.MaxPower == 0 || powerLimit < connector.MaxPower) {
+		return powerLimit
+	}
+
+	return connector.MaxPower
+}
+
+func findSiteChargepoints(siteID string, collection *mongo.Collection) ([]models.Chargepoint, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"siteId": siteID})
+	if err != nil {
+		return nil, err
+	}
+
+	var chargepoints []models.Chargepoint
+	err = cursor.All(context.Background(), &chargepoints)
+	return chargepoints, err
+}
+
+// projectedLoad adds up the power of every connector at the site that is reserved at some point during the period, except the given one
+func projectedLoad(chargepoints []models.Chargepoint, exceptChargepoint string, exceptConnector int, start, end time.Time, collection *mongo.Collection) (float64, error) {
+	chargepointIDs := bson.A{}
+	connectors := map[string][]models.Connector{}
+	for _, chargepoint := range chargepoints {
+		chargepointIDs = append(chargepointIDs, chargepoint.ID)
+		connectors[chargepoint.ID] = chargepoint.Connectors
+	}
+
+	cursor, err := collection.Find(context.Background(), bson.M{
+		"chargepoint":         bson.M{"$in": chargepointIDs},
+		"hasFinishedCharging": false,
+		"startTime":           bson.M{"$lt": end},
+		"chargingTime":        bson.M{"$gt": start},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var reservations []models.Reservation
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		return 0, err
+	}
+
+	// A connector only draws power for one reservation at a time, so it counts with its highest demand
+	demands := map[string]float64{}
+	for _, reservation := range reservations {
+		if reservation.Chargepoint == exceptChargepoint && reservation.Connector == exceptConnector {
+			continue
+		}
+		if reservation.Connector <= 0 || reservation.Connector > len(connectors[reservation.Chargepoint]) {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%d", reservation.Chargepoint, reservation.Connector)
+		demand := connectorDemand(connectors[reservation.Chargepoint][reservation.Connector-1], reservation.PowerLimit)
+		if demand > demands[key] {
+			demands[key] = demand
+		}
+	}
+
+	load := 0.0
+	for _, demand := range demands {
+		load += demand
+	}
+
+	return load, nil
+}
+
+// checkSiteCapacity returns the power limit (in kW) a reservation of the connector during the period gets at its site, or 0 when it can draw all it asks for. When the site can't even provide the minimum charging power, a response describing the shortfall is returned instead.
+func checkSiteCapacity(chargepoint models.Chargepoint, connectorNumber int, demand float64, start, end time.Time, collections Collections) (float64, *models.LimitErrorResponse, error) {
+	if chargepoint.SiteID == "" || demand <= 0 {
+		return 0, nil, nil
+	}
+
+	site, err := FindSiteByID(chargepoint.SiteID, collections.Sites)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil, nil
+		}
+		return 0, nil, err
+	}
+
+	if site.MaxPower <= 0 {
+		return 0, nil, nil
+	}
+
+	chargepoints, err := findSiteChargepoints(site.ID, collections.Chargepoints)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	load, err := projectedLoad(chargepoints, chargepoint.ID, connectorNumber, start, end, collections.Reservations)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	headroom := site.MaxPower - load
+	if headroom >= demand {
+		return 0, nil, nil
+	}
+
+	if headroom >= minChargingPower() {
+		return headroom, nil, nil
+	}
+
+	return 0, &models.LimitErrorResponse{
+		Error:   fmt.Sprintf("The site's grid connection is fully booked during the reservation, only %.1f of its %.1f kW are left", headroom, site.MaxPower),
+		Code:    QuotaSiteCapacity,
+		Limit:   int(site.MaxPower),
+		Current: int(load),
+	}, nil
+}
+
+// SetSiteCapacity godoc
+// @Summary Set a site's power capacity
+// @Description Limits the power (in kW) the site's connectors can draw together, 0 removes the limit. The load manager shares the power between charging sessions with the strategy: "EqualShare" (the default) splits it evenly, "Priority" serves users with a higher priority first and "FirstCome" serves sessions in the order they started. Reservations are downgraded to the power that is left during their time, or refused when less than MIN_CHARGING_POWER is left.
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param body body SiteCapacityRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /sites/{id}/capacity [post]
+func SetSiteCapacity(c *gin.Context, collections Collections) {
+	var req SiteCapacityRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.MaxPower < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Maximum power can't be negative"})
+		return
+	}
+
+	if req.Strategy == "" {
+		req.Strategy = loadmanagement.EqualShare
+	}
+
+	if !loadmanagement.Valid(req.Strategy) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Strategy must be either EqualShare, Priority or FirstCome"})
+		return
+	}
+
+	result, err := collections.Sites.UpdateOne(context.Background(), bson.M{"_id": c.Param("id")}, bson.M{"$set": bson.M{"maxPower": req.MaxPower, "loadStrategy": req.Strategy}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the site"})
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Site not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Site capacity updated"})
+}
+
+type SiteCapacityRequest struct {
+	// In kW, 0 means unlimited
+	MaxPower float64 `json:"maxPower"`
+	Strategy string  `json:"strategy"`
+}
+
+// GetSiteLoad godoc
+// @Summary Get the power limits of a site's connectors
+// @Description Shares the site's capacity between its charging sessions with its load strategy. Every connector gets a power limit in kW, connectors that aren't charging get none. A session can't draw more than its connector's maximum power, or the power its reservation was downgraded to.
+// @Tags Sites
+// @Produce json
+// @Param id path string true "Site ID"
+// @Success 200 {object} models.SiteLoad
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /sites/{id}/load [get]
+func GetSiteLoad(site models.Site, collections Collections) (models.SiteLoad, error) {
+	chargepoints, err := findSiteChargepoints(site.ID, collections.Chargepoints)
+	if err != nil {
+		return models.SiteLoad{}, err
+	}
+
+	chargepointIDs := bson.A{}
+	for _, chargepoint := range chargepoints {
+		chargepointIDs = append(chargepointIDs, chargepoint.ID)
+	}
+
+	cursor, err := collections.Sessions.Find(context.Background(), bson.M{"chargepoint": bson.M{"$in": chargepointIDs}, "status": SessionCharging})
+	if err != nil {
+		return models.SiteLoad{}, err
+	}
+
+	var sessions []models.ChargingSession
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		return models.SiteLoad{}, err
+	}
+
+	active := map[string]models.ChargingSession{}
+	for _, session := range sessions {
+		active[fmt.Sprintf("%s/%d", session.Chargepoint, session.Connector)] = session
+	}
+
+	load := models.SiteLoad{SiteID: site.ID, MaxPower: site.MaxPower, LoadStrategy: site.LoadStrategy, Connectors: []models.ConnectorLoad{}}
+	if load.LoadStrategy == "" {
+		load.LoadStrategy = loadmanagement.EqualShare
+	}
+
+	demands := []loadmanagement.Demand{}
+	for _, chargepoint := range chargepoints {
+		for _, connector := range chargepoint.Connectors {
+			key := fmt.Sprintf("%s/%d", chargepoint.ID, connector.ID)
+			connectorLoad := models.ConnectorLoad{Chargepoint: chargepoint.ID, Connector: connector.ID, State: connector.State, MaxPower: connector.MaxPower}
+
+			if session, ok := active[key]; ok {
+				connectorLoad.SessionID = session.ID.Hex()
+				demands = append(demands, loadmanagement.Demand{ID: key, MaxPower: connectorDemand(connector, session.PowerLimit), Priority: session.Priority, Since: session.StartTime})
+			}
+
+			load.Connectors = append(load.Connectors, connectorLoad)
+		}
+	}
+
+	limits := loadmanagement.Allocate(site.MaxPower, load.LoadStrategy, demands)
+	for i, connector := range load.Connectors {
+		load.Connectors[i].Limit = roundQuantity(limits[fmt.Sprintf("%s/%d", connector.Chargepoint, connector.Connector)])
+		load.Allocated += load.Connectors[i].Limit
+	}
+	load.Allocated = roundQuantity(load.Allocated)
+
+	return load, nil
+}
@@ -0,0 +1,123 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestLoadManagement(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/sites/:id/capacity", func(c *gin.Context) {
+		SetSiteCapacity(c, collections)
+	})
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Sites, collections.Chargepoints, collections.Reservations, collections.Sessions} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	for _, id := range []string{"loadUser1", "loadUser2", "loadUser3"} {
+		collections.Users.InsertOne(context.Background(), models.User{ID: id, Name: "Depot driver"})
+	}
+	collections.Sites.InsertOne(context.Background(), models.Site{ID: "loadSite", Name: "Depot"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "loadChargepoint", SiteID: "loadSite", Connectors: []models.Connector{
+		{ID: 1, State: "Available", MaxPower: 22},
+		{ID: 2, State: "Available", MaxPower: 22},
+		{ID: 3, State: "Available", MaxPower: 22},
+	}})
+
+	request := func(endpoint string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		body     any
+		code     int
+	}{
+		{name: "InvalidStrategy", endpoint: "/sites/loadSite/capacity", body: map[string]any{"maxPower": 30, "strategy": "Random"}, code: http.StatusBadRequest},
+		{name: "MissingSite", endpoint: "/sites/missing/capacity", body: map[string]any{"maxPower": 30}, code: http.StatusNotFound},
+		{name: "SetCapacity", endpoint: "/sites/loadSite/capacity", body: map[string]any{"maxPower": 30, "strategy": "EqualShare"}, code: http.StatusOK},
+		{name: "ReserveFullPower", endpoint: "/reservations/loadChargepoint/1", body: map[string]any{"userId": "loadUser1", "minutes": 60}, code: http.StatusOK},
+		{name: "ReserveDowngraded", endpoint: "/reservations/loadChargepoint/2", body: map[string]any{"userId": "loadUser2", "minutes": 60}, code: http.StatusOK},
+		{name: "ReserveOverCapacity", endpoint: "/reservations/loadChargepoint/3", body: map[string]any{"userId": "loadUser3", "minutes": 60}, code: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := request(test.endpoint, test.body)
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			}
+		})
+	}
+
+	var downgraded models.Reservation
+	collections.Reservations.FindOne(context.Background(), bson.M{"userId": "loadUser2"}).Decode(&downgraded)
+	if downgraded.PowerLimit != 8 {
+		t.Errorf("Expected the second reservation to be limited to the 8 kW left, but received %.1f", downgraded.PowerLimit)
+	}
+
+	t.Run("GetSiteLoad", func(t *testing.T) {
+		collections.Sessions.InsertMany(context.Background(), []interface{}{
+			models.ChargingSession{ID: primitive.NewObjectID(), Chargepoint: "loadChargepoint", Connector: 1, Status: SessionCharging, StartTime: time.Now()},
+			models.ChargingSession{ID: primitive.NewObjectID(), Chargepoint: "loadChargepoint", Connector: 2, Status: SessionCharging, StartTime: time.Now(), PowerLimit: 8},
+		})
+
+		site, _ := FindSiteByID("loadSite", collections.Sites)
+		load, err := GetSiteLoad(site, collections)
+		if err != nil {
+			t.Fatalf("Could not compute the site's load:\n%v", err)
+		}
+
+		limits := []float64{22, 8, 0}
+		for i, connector := range load.Connectors {
+			if connector.Limit != limits[i] {
+				t.Errorf("Expected connector %d to be limited to %.1f kW, but received %.1f", connector.Connector, limits[i], connector.Limit)
+			}
+		}
+
+		if load.Allocated != 30 {
+			t.Errorf("Expected all 30 kW to be allocated, but %.1f were", load.Allocated)
+		}
+	})
+}
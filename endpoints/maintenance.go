@@ -0,0 +1,162 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// findMaintenance returns the maintenance windows of the chargepoints that overlap the period
+func findMaintenance(chargepointIDs bson.A, start, end time.Time, collection *mongo.Collection) ([]models.MaintenanceWindow, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{
+		"chargepoint": bson.M{"$in": chargepointIDs},
+		"start":       bson.M{"$lt": end},
+		"end":         bson.M{"$gt": start},
+	}, options.Find().SetSort(bson.M{"start": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	windows := []models.MaintenanceWindow{}
+	err = cursor.All(context.Background(), &windows)
+	return windows, err
+}
+
+// underMaintenance returns the first maintenance window that takes the connector out of service during the period, or nil when there is none
+func underMaintenance(chargepointID string, connector int, start, end time.Time, collection *mongo.Collection) (*models.MaintenanceWindow, error) {
+	windows, err := findMaintenance(bson.A{chargepointID}, start, end, collection)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, window := range windows {
+		if window.Connector == 0 || window.Connector == connector {
+			return &window, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// ScheduleMaintenance godoc
+// @Summary Schedule maintenance on a chargepoint
+// @Description Takes a connector, or all of the chargepoint's connectors when none is given, out of service for a period. Reservations can't be made during it and it doesn't show up as free in the availability search. Reservations that were already made for the period aren't cancelled, they're returned as warnings so they can be moved or cancelled.
+// @Tags Operators
+// @Accept json
+// @Produce json
+// @Param id path string true "Chargepoint ID"
+// @Param body body MaintenanceRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /chargepoints/{id}/maintenance [post]
+func ScheduleMaintenance(c *gin.Context, collections Collections) {
+	var req MaintenanceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(c.Param("id"), collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Chargepoint not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	if req.Connector < 0 || req.Connector > len(chargepoint.Connectors) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors, or 0 for all of them"})
+		return
+	}
+
+	if !req.End.After(req.Start) || !req.End.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The maintenance must end after it starts, and in the future"})
+		return
+	}
+
+	window := models.MaintenanceWindow{
+		Chargepoint: chargepoint.ID,
+		Connector:   req.Connector,
+		Start:       req.Start,
+		End:         req.End,
+		Reason:      req.Reason,
+	}
+
+	_, err = collections.Maintenance.InsertOne(context.Background(), window)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not schedule the maintenance"})
+		return
+	}
+
+	filter := bson.M{
+		"chargepoint":         chargepoint.ID,
+		"hasFinishedCharging": false,
+		"startTime":           bson.M{"$lt": req.End},
+		"chargingTime":        bson.M{"$gt": req.Start},
+	}
+	if req.Connector != 0 {
+		filter["connector"] = req.Connector
+	}
+
+	cursor, err := collections.Reservations.Find(context.Background(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch reservations"})
+		return
+	}
+
+	var affected []models.Reservation
+	if err := cursor.All(context.Background(), &affected); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch reservations"})
+		return
+	}
+
+	var warnings []string
+	for _, reservation := range affected {
+		warnings = append(warnings, fmt.Sprintf("Reservation %s on connector %d overlaps the maintenance", reservation.ID.Hex(), reservation.Connector))
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Maintenance scheduled", Warnings: warnings})
+}
+
+type MaintenanceRequest struct {
+	// Optional, all of the chargepoint's connectors when 0
+	Connector int       `json:"connector"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Reason    string    `json:"reason"`
+}
+
+// GetMaintenanceWindows godoc
+// @Summary Get a chargepoint's maintenance windows
+// @Description Lists the ongoing and upcoming maintenance of the chargepoint.
+// @Tags Operators
+// @Produce json
+// @Param id path string true "Chargepoint ID"
+// @Success 200 {object} []models.MaintenanceWindow
+// @Failure 500 {object} models.ErrorResponse
+// @Router /chargepoints/{id}/maintenance [get]
+func GetMaintenanceWindows(chargepointID string, collection *mongo.Collection) ([]models.MaintenanceWindow, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"chargepoint": chargepointID, "end": bson.M{"$gt": time.Now()}}, options.Find().SetSort(bson.M{"start": 1}))
+	if err != nil {
+		return []models.MaintenanceWindow{}, err
+	}
+
+	windows := []models.MaintenanceWindow{}
+	if err := cursor.All(context.Background(), &windows); err != nil {
+		return []models.MaintenanceWindow{}, err
+	}
+
+	return windows, nil
+}
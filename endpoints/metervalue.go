@@ -0,0 +1,151 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"reservations/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PushMeterValues godoc
+// @Summary Push meter values of a connector
+// @Description Records periodic readings of the connector's active charging session, like the sampled values of an OCPP MeterValues message. Energy is the meter's register in Wh, power is in kW, the state of charge in percent and voltage in V. Readings without a timestamp are taken now. The highest energy reading becomes the session's last known meter value.
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param connectorID path int true "Connector ID"
+// @Param body body MeterValuesRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /metervalues/{chargepointID}/{connectorID} [post]
+func PushMeterValues(c *gin.Context, collections Collections) {
+	var req MeterValuesRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if len(req.Values) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "At least one meter value is required"})
+		return
+	}
+
+	connectorNumber, err := strconv.Atoi(c.Param("coID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be a number"})
+		return
+	}
+
+	session, err := findActiveSession(c.Param("cpID"), connectorNumber, collections.Sessions)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "There is no active charging session on the connector"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch sessions"})
+		return
+	}
+
+	meterStop := session.MeterStop
+	documents := make([]interface{}, 0, len(req.Values))
+
+	for _, value := range req.Values {
+		if value.Energy < 0 || value.Power < 0 || value.Voltage < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Meter values can't be negative"})
+			return
+		}
+
+		if value.Energy > 0 && value.Energy < session.MeterStart {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The energy reading can't be lower than at the start of the session"})
+			return
+		}
+
+		if value.SoC < 0 || value.SoC > 100 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The state of charge must be between 0 and 100 percent"})
+			return
+		}
+
+		timestamp := time.Now()
+		if value.Timestamp != nil {
+			timestamp = *value.Timestamp
+		}
+
+		if value.Energy > meterStop {
+			meterStop = value.Energy
+		}
+
+		documents = append(documents, models.MeterValue{
+			ID:          primitive.NewObjectID(),
+			SessionID:   session.ID,
+			Chargepoint: session.Chargepoint,
+			Connector:   session.Connector,
+			Timestamp:   timestamp,
+			Energy:      value.Energy,
+			Power:       value.Power,
+			SoC:         value.SoC,
+			Voltage:     value.Voltage,
+		})
+	}
+
+	_, err = collections.MeterValues.InsertMany(context.Background(), documents)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not record the meter values"})
+		return
+	}
+
+	_, err = collections.Sessions.UpdateOne(context.Background(), bson.M{"_id": session.ID}, bson.M{"$set": bson.M{"meterStop": meterStop}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the session's meter value"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Meter values recorded"})
+}
+
+type MeterValuesRequest struct {
+	Values []MeterValueRequest `json:"values"`
+}
+
+// MeterValueRequest is a single reading, any of the measurands can be left out
+type MeterValueRequest struct {
+	Timestamp *time.Time `json:"timestamp"`
+	Energy    float64    `json:"energy"`
+	Power     float64    `json:"power"`
+	SoC       float64    `json:"soc"`
+	Voltage   float64    `json:"voltage"`
+}
+
+// GetSessionPowerCurve godoc
+// @Summary Get the power curve of a charging session
+// @Description Lists the meter values pushed during the session, oldest first.
+// @Tags Sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} []models.MeterValue
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /sessions/{id}/powercurve [get]
+func GetSessionPowerCurve(session models.ChargingSession, collection *mongo.Collection) ([]models.MeterValue, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"sessionId": session.ID}, options.Find().SetSort(bson.M{"timestamp": 1}))
+	if err != nil {
+		return []models.MeterValue{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	values := []models.MeterValue{}
+	if err := cursor.All(context.Background(), &values); err != nil {
+		return []models.MeterValue{}, err
+	}
+
+	return values, nil
+}
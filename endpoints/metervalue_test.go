@@ -0,0 +1,111 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMeterValues(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/metervalues/:cpID/:coID", func(c *gin.Context) {
+		PushMeterValues(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Sessions, collections.MeterValues} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	session, err := startSession(models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "meterChargepoint", Connector: 1, UserID: "meterUser"}, 1000, collections.Sessions)
+	if err != nil {
+		t.Fatalf("Could not start a session:\n%v", err)
+	}
+
+	start := time.Now()
+	tests := []struct {
+		name      string
+		connector string
+		values    []map[string]any
+		code      int
+	}{
+		{name: "NoSession", connector: "2", values: []map[string]any{{"energy": 2000}}, code: http.StatusBadRequest},
+		{name: "NoValues", connector: "1", values: []map[string]any{}, code: http.StatusBadRequest},
+		{name: "EnergyBelowStart", connector: "1", values: []map[string]any{{"energy": 500}}, code: http.StatusBadRequest},
+		{name: "InvalidSoC", connector: "1", values: []map[string]any{{"soc": 120}}, code: http.StatusBadRequest},
+		{name: "Readings", connector: "1", values: []map[string]any{
+			{"timestamp": start.Add(time.Minute), "energy": 4000, "power": 11, "soc": 40, "voltage": 400},
+			{"timestamp": start.Add(2 * time.Minute), "energy": 7500, "power": 10.5, "soc": 45, "voltage": 400},
+		}, code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]any{"values": test.values})
+			req, _ := http.NewRequest("POST", "/metervalues/meterChargepoint/"+test.connector, bytes.NewReader(body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+
+	t.Run("PowerCurve", func(t *testing.T) {
+		values, err := GetSessionPowerCurve(session, collections.MeterValues)
+		if err != nil {
+			t.Fatalf("Could not fetch meter values:\n%v", err)
+		}
+
+		if len(values) != 2 || values[0].Power != 11 || values[1].SoC != 45 {
+			t.Errorf("Expected the two readings oldest first, but received %v", values)
+		}
+	})
+
+	t.Run("EnergyDelivered", func(t *testing.T) {
+		err := stopSession("meterChargepoint", 1, 0, StopReasonLocal, collections)
+		if err != nil {
+			t.Fatalf("Could not stop the session:\n%v", err)
+		}
+
+		stopped, err := FindSessionByID(session.ID.Hex(), collections.Sessions)
+		if err != nil {
+			t.Fatalf("Could not find the session:\n%v", err)
+		}
+
+		if stopped.MeterStop != 7500 || stopped.Energy != 6.5 {
+			t.Errorf("Expected 6.5 kWh to be delivered up to 7500 Wh, but received %.2f kWh up to %.0f Wh", stopped.Energy, stopped.MeterStop)
+		}
+	})
+}
@@ -0,0 +1,99 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"reservations/bookingcode"
+	"reservations/models"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// legacyObjectID returns a new ObjectID that sorts by when the reservation was created, like the ObjectIDs of new reservations
+func legacyObjectID(document bson.M) primitive.ObjectID {
+	id := primitive.NewObjectID()
+	if createdAt, ok := document["createdAt"].(primitive.DateTime); ok && createdAt > 0 {
+		binary.BigEndian.PutUint32(id[0:4], uint32(createdAt.Time().Unix()))
+	}
+	return id
+}
+
+// MigrateReservationIDs gives the reservations that still have a numeric ID an ObjectID and a booking code, and points the sessions, payments, ledger entries, no-shows, fees, invoices and series that refer to them to the new ID. The old ID is kept as the legacy ID, so they can still be looked up by it. Reservations are migrated one by one and the migration can be run again after it's interrupted.
+func MigrateReservationIDs(collections Collections) error {
+	cursor, err := collections.Reservations.Find(context.Background(), bson.M{"_id": bson.M{"$type": "number"}})
+	if err != nil {
+		return err
+	}
+
+	var documents []bson.M
+	if err := cursor.All(context.Background(), &documents); err != nil {
+		return err
+	}
+
+	for _, document := range documents {
+		legacyID := document["_id"]
+
+		// An interrupted migration may have copied the reservation already
+		var migrated models.Reservation
+		err := collections.Reservations.FindOne(context.Background(), bson.M{"legacyId": legacyID}).Decode(&migrated)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		id := migrated.ID
+		if err == mongo.ErrNoDocuments {
+			id = legacyObjectID(document)
+			document["_id"] = id
+			document["legacyId"] = legacyID
+
+			for attempt := 0; attempt < 5; attempt++ {
+				document["code"], err = bookingcode.New()
+				if err != nil {
+					return err
+				}
+
+				_, err = collections.Reservations.InsertOne(context.Background(), document)
+				if !mongo.IsDuplicateKeyError(err) {
+					break
+				}
+			}
+			if err != nil {
+				return fmt.Errorf("copying reservation %v: %w", legacyID, err)
+			}
+		}
+
+		for _, collection := range []*mongo.Collection{collections.Sessions, collections.Ledger, collections.Payments, collections.NoShows, collections.Fees} {
+			_, err := collection.UpdateMany(context.Background(), bson.M{"reservationId": legacyID}, bson.M{"$set": bson.M{"reservationId": id}})
+			if err != nil {
+				return fmt.Errorf("updating references to reservation %v: %w", legacyID, err)
+			}
+		}
+
+		_, err = collections.Invoices.UpdateMany(context.Background(), bson.M{"lines.reservationId": legacyID}, bson.M{"$set": bson.M{"lines.$[line].reservationId": id}},
+			options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"line.reservationId": legacyID}}}))
+		if err != nil {
+			return fmt.Errorf("updating invoices of reservation %v: %w", legacyID, err)
+		}
+
+		_, err = collections.Series.UpdateMany(context.Background(), bson.M{"occurrences.reservationId": legacyID}, bson.M{"$set": bson.M{"occurrences.$[occurrence].reservationId": id}},
+			options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"occurrence.reservationId": legacyID}}}))
+		if err != nil {
+			return fmt.Errorf("updating series of reservation %v: %w", legacyID, err)
+		}
+
+		_, err = collections.Reservations.DeleteOne(context.Background(), bson.M{"_id": legacyID})
+		if err != nil {
+			return fmt.Errorf("removing reservation %v: %w", legacyID, err)
+		}
+	}
+
+	if len(documents) > 0 {
+		fmt.Printf("Migrated %d reservations to ObjectIDs\n", len(documents))
+	}
+
+	return nil
+}
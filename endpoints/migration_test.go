@@ -0,0 +1,86 @@
+package endpoints
+
+import (
+	"context"
+	"reservations/db"
+	"reservations/models"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestMigrateReservationIDs(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Reservations, collections.Sessions} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	createdAt := time.Now().Add(-24 * time.Hour).Truncate(time.Second)
+	collections.Reservations.InsertOne(context.Background(), bson.M{"_id": 1686000000000000000, "chargepoint": "legacyChargepoint", "connector": 1, "userId": "legacyUser", "createdAt": createdAt})
+	collections.Sessions.InsertOne(context.Background(), models.ChargingSession{ID: primitive.NewObjectID(), UserID: "legacyUser", Chargepoint: "legacyChargepoint", Connector: 1})
+	collections.Sessions.UpdateOne(context.Background(), bson.M{"userId": "legacyUser"}, bson.M{"$set": bson.M{"reservationId": 1686000000000000000}})
+
+	// Like on start, and creating them again changes nothing
+	for i := 0; i < 2; i++ {
+		if err := CreateIndexes(collections); err != nil {
+			t.Fatalf("Could not create the indexes:\n%v", err)
+		}
+	}
+
+	// Running it twice changes nothing the second time
+	for i := 0; i < 2; i++ {
+		if err := MigrateReservationIDs(collections); err != nil {
+			t.Fatalf("Could not migrate the reservations:\n%v", err)
+		}
+	}
+
+	count, _ := collections.Reservations.CountDocuments(context.Background(), bson.M{})
+	if count != 1 {
+		t.Fatalf("Expected one reservation after the migration, but found %d", count)
+	}
+
+	reservation, err := FindReservationByID("1686000000000000000", collections.Reservations)
+	if err != nil {
+		t.Fatalf("Could not find the reservation by its legacy ID:\n%v", err)
+	}
+
+	if reservation.ID.IsZero() || !reservation.ID.Timestamp().Equal(createdAt) || reservation.Code == "" {
+		t.Errorf("Expected an ObjectID from the creation time and a booking code, but received %v and %q", reservation.ID, reservation.Code)
+	}
+
+	found, err := FindReservationByCode(strings.ToLower(strings.ReplaceAll(reservation.Code, "-", "")), collections.Reservations)
+	if err != nil || found.ID != reservation.ID {
+		t.Errorf("Expected to find the reservation by its booking code, but received %v (%v)", found.ID, err)
+	}
+
+	sessions, _ := GetSessions(bson.M{"reservationId": reservation.ID}, collections.Sessions)
+	if len(sessions) != 1 {
+		t.Errorf("Expected the session to refer to the new ID, but found %d sessions", len(sessions))
+	}
+
+	_, err = collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), Code: reservation.Code})
+	if !mongo.IsDuplicateKeyError(err) {
+		t.Errorf("Expected booking codes to be unique, but received %v", err)
+	}
+}
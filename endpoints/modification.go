@@ -0,0 +1,363 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ModifyReservation godoc
+// @Summary Modify a reservation
+// @Description Moves a reservation that hasn't started charging to another connector or chargepoint, or changes its start time or reservation time. Fields that are left out keep their value, a reservation that already started keeps its start unless it's moved to later. The new connector and time are checked like a new reservation's, and the change is all or nothing: when they can't be taken, the reservation stays as it was. The reservation is priced again for its new connector and time, and longer reservations count against the user's quotas, organization and no-show limits again. What the wallet holds or the card authorized for it is resized to the new estimated price. The old connector becomes available again once the reservation no longer holds it.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Param body body ModifyReservationRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 403 {object} models.LimitErrorResponse
+// @Failure 402 {object} models.LimitErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /reservations/{id} [put]
+func ModifyReservation(c *gin.Context, collections Collections) {
+	var req ModifyReservationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	reservation, err := FindReservationByID(c.Param("id"), collections.Reservations)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Reservation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch reservations"})
+		return
+	}
+
+	if reservation.UserID != req.UserID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The reservation belongs to another user"})
+		return
+	}
+
+	if reservation.HasStartedCharging || reservation.HasFinishedCharging {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Only reservations that haven't started charging can be modified"})
+		return
+	}
+
+	warnings, modifyErr := modifyReservation(reservation, req, collections)
+	if modifyErr != nil {
+		c.JSON(modifyErr.Status, modifyErr.Body)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Reservation modified", Warnings: warnings})
+}
+
+type ModifyReservationRequest struct {
+	UserID string `json:"userId"`
+	// Optional, moves the reservation to another chargepoint, which needs the connector as well
+	Chargepoint string `json:"chargepoint"`
+	// Optional, moves the reservation to another connector
+	Connector int `json:"connector"`
+	// Optional, moves the reservation to another time in the future, up to the connector's booking horizon
+	StartTime *time.Time `json:"startTime"`
+	// Optional, changes the reservation time
+	Minutes int `json:"minutes"`
+}
+
+// undoModification puts back what modifyReservation changed before failing: the reservation when it was already updated, the claimed connector and the resized payment
+func undoModification(previous *models.Reservation, chargepointID string, connectorNumber int, claimed bool, payment *paymentChange, collections Collections) {
+	if previous != nil {
+		_, err := collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": previous.ID}, bson.M{"$set": bson.M{
+			"chargepoint":  previous.Chargepoint,
+			"connector":    previous.Connector,
+			"minutes":      previous.Minutes,
+			"startTime":    previous.StartTime,
+			"expiryTime":   previous.ExpiryTime,
+			"chargingTime": previous.ChargingTime,
+			"powerLimit":   previous.PowerLimit,
+			"tariffId":     previous.TariffID,
+			"adjustments":  previous.Adjustments,
+		}})
+		if err != nil {
+			fmt.Println("Error restoring modified reservation: ", err)
+		}
+	}
+
+	if claimed {
+		if _, err := setConnectorState(chargepointID, connectorNumber, "Reserved", "Available", collections.Chargepoints); err != nil {
+			fmt.Println("Error releasing claimed connector: ", err)
+		}
+	}
+
+	payment.undo(collections)
+}
+
+// modifyReservation moves the reservation as requested, or returns why it can't and leaves the reservation as it was
+func modifyReservation(reservation models.Reservation, req ModifyReservationRequest, collections Collections) ([]string, *reservationError) {
+	chargepointID := reservation.Chargepoint
+	if req.Chargepoint != "" {
+		if req.Connector == 0 {
+			return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "A connector is required when moving to another chargepoint"}}
+		}
+		chargepointID = req.Chargepoint
+	}
+
+	chargepoint, err := FindChargepointByID(chargepointID, collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Chargepoint does not exist"}}
+		}
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch chargepoints"}}
+	}
+
+	connectorNumber := reservation.Connector
+	if req.Connector != 0 {
+		connectorNumber = req.Connector
+	}
+
+	if connectorNumber <= 0 || connectorNumber > len(chargepoint.Connectors) {
+		return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors"}}
+	}
+
+	policy, err := resolveReservationPolicy(chargepoint, connectorNumber, collections)
+	if err != nil {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch the connector's reservation policy"}}
+	}
+
+	user, err := FindUserByID(reservation.UserID, collections.Users)
+	if err != nil && err != mongo.ErrNoDocuments {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not fetch users"}}
+	}
+	if !allowsUser(policy, user) {
+		return nil, &reservationError{Status: http.StatusForbidden, Body: models.ErrorResponse{Error: "The connector can only be reserved by certain organizations"}}
+	}
+
+	now := time.Now()
+	start := reservation.StartTime
+	if req.StartTime != nil {
+		if !req.StartTime.After(now) {
+			return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "The reservation can only be moved to a time in the future"}}
+		}
+		if req.StartTime.After(now.AddDate(0, 0, policy.HorizonDays)) {
+			return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: fmt.Sprintf("Reservations can start at most %d days ahead", policy.HorizonDays)}}
+		}
+		start = *req.StartTime
+	}
+
+	minutes := reservation.Minutes
+	if req.Minutes != 0 {
+		minutes = req.Minutes
+	}
+
+	if message := checkDuration(policy, minutes); message != "" {
+		return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: message}}
+	}
+
+	end := start.Add(time.Duration(minutes) * time.Minute)
+	if !end.After(now) {
+		return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "The reservation would already have ended"}}
+	}
+
+	// A reservation that stays where it was keeps its place on a held connector
+	moved := chargepoint.ID != reservation.Chargepoint || connectorNumber != reservation.Connector || !start.Equal(reservation.StartTime)
+	if moved {
+		class, err := priorityClassOf(user, collections)
+		if err != nil {
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not fetch the user's priority class"}}
+		}
+
+		if holdErr := checkHold(chargepoint.Connectors[connectorNumber-1], class, start, now, collections); holdErr != nil {
+			return nil, holdErr
+		}
+	}
+
+	// Longer reservations count against the user's limits again, the reservation itself is only counted with its new minutes
+	var warnings []string
+	if minutes > reservation.Minutes {
+		var limit *models.LimitErrorResponse
+		if start.After(now) {
+			limit, err = checkUpcomingQuotas(reservation.UserID, start, minutes, reservation.ID, LoadQuotaPolicy(), collections.Reservations)
+		} else {
+			limit, err = checkQuotas(reservation.UserID, minutes, reservation.ID, LoadQuotaPolicy(), collections.Reservations)
+		}
+		if err != nil {
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not check the user's reservation quotas"}}
+		}
+		if limit != nil {
+			return nil, &reservationError{Status: http.StatusForbidden, Body: limit}
+		}
+
+		limit, err = checkOrganizationLimits(user, chargepoint, minutes-reservation.Minutes, collections)
+		if err != nil {
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not check the organization's limits"}}
+		}
+		if limit != nil {
+			return nil, &reservationError{Status: http.StatusForbidden, Body: limit}
+		}
+
+		limit, warning, err := checkPenalty(reservation.UserID, reservation.Deposit, LoadPenaltyPolicy(), collections.NoShows)
+		if err != nil {
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not check the user's no-show penalties"}}
+		}
+		if limit != nil {
+			return nil, &reservationError{Status: http.StatusForbidden, Body: limit}
+		}
+		if warning != "" {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	// A reservation holds its connector from its start, see activateReservations
+	wasActive := !reservation.StartTime.After(now)
+	active := !start.After(now)
+	sameConnector := chargepoint.ID == reservation.Chargepoint && connectorNumber == reservation.Connector
+
+	connector := chargepoint.Connectors[connectorNumber-1]
+	demand := connector.MaxPower
+	var reservedVehicle *models.Vehicle
+
+	if reservation.VehicleID != "" {
+		vehicle, err := FindVehicleByID(reservation.VehicleID, collections.Vehicles)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not fetch vehicles"}}
+		}
+
+		if err == nil {
+			compatible, compatibilityWarnings := checkCompatibility(vehicle, connector)
+			if !compatible {
+				return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Connector is not compatible with the vehicle"}}
+			}
+			if !sameConnector {
+				warnings = append(warnings, compatibilityWarnings...)
+			}
+			demand = chargingPower(vehicle, connector)
+			reservedVehicle = &vehicle
+		}
+	}
+
+	buffer := time.Duration(policy.BufferMinutes) * time.Minute
+	booked, err := findOverlapping(chargepoint.ID, connectorNumber, start.Add(-buffer), end.Add(buffer), reservation.ID, collections.Reservations)
+	if err != nil {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch reservations"}}
+	}
+	if booked != nil {
+		return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: fmt.Sprintf("The connector is reserved from %s to %s", booked.StartTime.Format("Jan 2 15:04"), booked.ChargingTime.Format("15:04"))}}
+	}
+
+	maintenance, err := underMaintenance(chargepoint.ID, connectorNumber, start, end, collections.Maintenance)
+	if err != nil {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch maintenance windows"}}
+	}
+	if maintenance != nil {
+		return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: fmt.Sprintf("The connector is under maintenance from %s to %s", maintenance.Start.Format("15:04"), maintenance.End.Format("15:04"))}}
+	}
+
+	powerLimit, limit, err := checkSiteCapacity(chargepoint, connectorNumber, demand, start, end, collections)
+	if err != nil {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not check the site's capacity"}}
+	}
+	if limit != nil {
+		return nil, &reservationError{Status: http.StatusForbidden, Body: limit}
+	}
+	if powerLimit > 0 {
+		warnings = append(warnings, fmt.Sprintf("Charging is limited to %.1f kW because the site's grid connection is shared", powerLimit))
+	}
+
+	// The connector and time decide the price, so the changed reservation is priced again and what was set aside for it resized
+	tariff, err := resolveTariff(chargepoint, connectorNumber, collections)
+	if err != nil {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch the connector's tariff"}}
+	}
+
+	adjustments, err := dynamicAdjustments(chargepoint, start, end, collections)
+	if err != nil {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to apply the site's pricing policy"}}
+	}
+
+	if powerLimit > 0 {
+		connector.MaxPower = powerLimit
+	}
+	energy := estimateEnergy(reservedVehicle, connector, minutes)
+	if reservation.TargetEnergy > 0 && reservation.TargetEnergy < energy {
+		energy = reservation.TargetEnergy
+	}
+
+	estimate := ApplyAdjustments(ComputePrice(tariff, Usage{Reserved: true, Start: start, End: end, Energy: energy}), adjustments)
+
+	mode, err := reservationPaymentMode(reservation.ID, reservation.UserID, collections)
+	if err != nil {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not fetch the user's wallet"}}
+	}
+
+	payment, paymentErr := resizePayment(user, mode, reservation, estimate, collections)
+	if paymentErr != nil {
+		return nil, paymentErr
+	}
+
+	// The new connector is claimed first, so the reservation stays where it was when someone else takes it
+	claim := active && !(sameConnector && wasActive)
+	if claim {
+		claimed, err := setConnectorState(chargepoint.ID, connectorNumber, "Available", "Reserved", collections.Chargepoints)
+		if err != nil {
+			payment.undo(collections)
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not update the state of the connector"}}
+		}
+		if !claimed {
+			payment.undo(collections)
+			return nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Connector must be available"}}
+		}
+	}
+
+	_, err = collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{
+		"chargepoint":  chargepoint.ID,
+		"connector":    connectorNumber,
+		"minutes":      minutes,
+		"startTime":    start,
+		"expiryTime":   start.Add(time.Duration(policy.GraceMinutes) * time.Minute),
+		"chargingTime": end,
+		"powerLimit":   powerLimit,
+		"tariffId":     tariff.ID,
+		"adjustments":  adjustments,
+	}})
+	if err != nil {
+		undoModification(nil, chargepoint.ID, connectorNumber, claim, payment, collections)
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not update the reservation"}}
+	}
+
+	// The series shows the reservation's new start, the reservation is put back when it can't be updated
+	if seriesID, err := primitive.ObjectIDFromHex(reservation.SeriesID); err == nil {
+		_, err = collections.Series.UpdateOne(context.Background(), bson.M{"_id": seriesID, "occurrences.reservationId": reservation.ID}, bson.M{"$set": bson.M{"occurrences.$.start": start}})
+		if err != nil {
+			undoModification(&reservation, chargepoint.ID, connectorNumber, claim, payment, collections)
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not update the series"}}
+		}
+	}
+	payment.keep(collections)
+
+	// The old connector is freed once the reservation no longer holds it
+	if wasActive && !(sameConnector && active) {
+		_, err = setConnectorState(reservation.Chargepoint, reservation.Connector, "Reserved", "Available", collections.Chargepoints)
+		if err != nil {
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not update the state of the connector"}}
+		}
+
+		if err := offerConnectors(reservation.Chargepoint, collections); err != nil {
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not offer the connector to the waitlist"}}
+		}
+	}
+
+	return warnings, nil
+}
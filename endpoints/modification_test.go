@@ -0,0 +1,151 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestModifyReservation(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	router.PUT("/reservations/:id", func(c *gin.Context) {
+		ModifyReservation(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Tariffs, collections.Wallets, collections.Ledger} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "modifyUser", Name: "Mover"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "modifyChargepoint", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "Type2"},
+		{ID: 2, State: "Available", PlugType: "Type2"},
+		{ID: 3, State: "Unavailable", PlugType: "Type2"},
+	}})
+
+	request := func(method, endpoint string, body any) int {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	if code := request("POST", "/reservations/modifyChargepoint/1", map[string]any{"userId": "modifyUser", "minutes": 60}); code != http.StatusOK {
+		t.Fatalf("Expected the reservation to be created, but received code %d", code)
+	}
+
+	var reservation models.Reservation
+	collections.Reservations.FindOne(context.Background(), bson.M{"userId": "modifyUser"}).Decode(&reservation)
+	endpoint := "/reservations/" + reservation.ID.Hex()
+
+	states := func() []string {
+		chargepoint, _ := FindChargepointByID("modifyChargepoint", collections.Chargepoints)
+		states := []string{}
+		for _, connector := range chargepoint.Connectors {
+			states = append(states, connector.State)
+		}
+		return states
+	}
+
+	tests := []struct {
+		name      string
+		body      any
+		code      int
+		connector int
+		states    []string
+	}{
+		{name: "AnotherUser", body: map[string]any{"userId": "someoneElse", "connector": 2}, code: http.StatusBadRequest, connector: 1, states: []string{"Reserved", "Available", "Unavailable"}},
+		{name: "TooLong", body: map[string]any{"userId": "modifyUser", "minutes": 240}, code: http.StatusBadRequest, connector: 1, states: []string{"Reserved", "Available", "Unavailable"}},
+		{name: "UnavailableConnector", body: map[string]any{"userId": "modifyUser", "connector": 3}, code: http.StatusBadRequest, connector: 1, states: []string{"Reserved", "Available", "Unavailable"}},
+		{name: "AnotherConnector", body: map[string]any{"userId": "modifyUser", "connector": 2, "minutes": 90}, code: http.StatusOK, connector: 2, states: []string{"Available", "Reserved", "Unavailable"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := request("PUT", endpoint, test.body)
+			if code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, code)
+			}
+
+			modified, _ := FindReservationByID(reservation.ID.Hex(), collections.Reservations)
+			if modified.Connector != test.connector {
+				t.Errorf("Expected the reservation to be on connector %d, but it's on %d", test.connector, modified.Connector)
+			}
+
+			for i, state := range states() {
+				if state != test.states[i] {
+					t.Errorf("Expected connector %d to be %s, but it's %s", i+1, test.states[i], state)
+				}
+			}
+		})
+	}
+
+	t.Run("Repriced", func(t *testing.T) {
+		collections.Users.InsertOne(context.Background(), models.User{ID: "modifyPrepaidUser", Name: "Prepaid mover"})
+		collections.Tariffs.InsertOne(context.Background(), models.Tariff{ID: "modifyTariff", Name: "Per minute", Currency: "EUR", PerMinute: 10})
+		collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "modifyPaidChargepoint", TariffID: "modifyTariff", Connectors: []models.Connector{
+			{ID: 1, State: "Available", PlugType: "Type2"},
+		}})
+		applyLedgerEntry(models.LedgerEntry{UserID: "modifyPrepaidUser", Type: LedgerTopUp, Amount: 1000}, collections)
+
+		if code := request("POST", "/reservations/modifyPaidChargepoint/1", map[string]any{"userId": "modifyPrepaidUser", "minutes": 60}); code != http.StatusOK {
+			t.Fatalf("Expected the reservation to be created, but received code %d", code)
+		}
+
+		var paid models.Reservation
+		collections.Reservations.FindOne(context.Background(), bson.M{"userId": "modifyPrepaidUser"}).Decode(&paid)
+		held := func() int64 {
+			_, held, _ := ledgerNet(paid.ID, LedgerHold, LedgerRelease, collections.Ledger)
+			return held
+		}
+
+		if code := request("PUT", "/reservations/"+paid.ID.Hex(), map[string]any{"userId": "modifyPrepaidUser", "minutes": 90}); code != http.StatusOK {
+			t.Fatalf("Expected the reservation to be extended, but received code %d", code)
+		}
+		if held() != 900 {
+			t.Errorf("Expected the hold to grow to 900, but it's %d", held())
+		}
+
+		// The wallet can't cover two hours, so nothing changes
+		if code := request("PUT", "/reservations/"+paid.ID.Hex(), map[string]any{"userId": "modifyPrepaidUser", "minutes": 120}); code != http.StatusPaymentRequired {
+			t.Errorf("Expected code %d, but received %d", http.StatusPaymentRequired, code)
+		}
+		modified, _ := FindReservationByID(paid.ID.Hex(), collections.Reservations)
+		if modified.Minutes != 90 || held() != 900 {
+			t.Errorf("Expected the reservation to keep 90 minutes and a hold of 900, but it has %d minutes and %d held", modified.Minutes, held())
+		}
+	})
+}
@@ -0,0 +1,251 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Penalty levels, from least to most severe
+const (
+	PenaltyNone    = "None"
+	PenaltyWarning = "Warning"
+	PenaltyBan     = "Ban"
+	PenaltyDeposit = "Deposit"
+)
+
+// Codes returned in models.LimitErrorResponse when a penalty refuses a reservation
+const (
+	QuotaNoShowCooldown  = "NO_SHOW_COOLDOWN"
+	QuotaDepositRequired = "DEPOSIT_REQUIRED"
+)
+
+// PenaltyPolicy decides how users are penalized for no-shows. Only no-shows inside Window that haven't been forgiven count towards the thresholds, and a threshold of 0 disables that penalty.
+type PenaltyPolicy struct {
+	Window           time.Duration
+	WarningThreshold int
+	// A banned user can't reserve until BanDuration has passed since their latest no-show
+	BanThreshold int
+	BanDuration  time.Duration
+	// Past DepositThreshold, every reservation needs a deposit (in cents)
+	DepositThreshold int
+	Deposit          int64
+}
+
+// LoadPenaltyPolicy reads the penalty policy from the environment, falling back to the defaults for any unset variable
+func LoadPenaltyPolicy() PenaltyPolicy {
+	return PenaltyPolicy{
+		Window:           time.Duration(envInt("NO_SHOW_WINDOW_HOURS", 168)) * time.Hour,
+		WarningThreshold: envInt("NO_SHOW_WARNING_THRESHOLD", 1),
+		BanThreshold:     envInt("NO_SHOW_COOLDOWN_THRESHOLD", 3),
+		BanDuration:      time.Duration(envInt("NO_SHOW_COOLDOWN_HOURS", 24)) * time.Hour,
+		DepositThreshold: envInt("NO_SHOW_DEPOSIT_THRESHOLD", 5),
+		Deposit:          int64(envInt("NO_SHOW_DEPOSIT", 2000)),
+	}
+}
+
+// assessPenalty works out the most severe penalty that currently applies to the user
+func assessPenalty(userID string, policy PenaltyPolicy, noShowsCollection *mongo.Collection) (models.Reliability, error) {
+	now := time.Now()
+	result := models.Reliability{UserID: userID, Penalty: PenaltyNone}
+
+	filter := bson.M{"userId": userID, "forgiven": false, "time": bson.M{"$gte": now.Add(-policy.Window)}}
+	opts := options.Find().SetSort(bson.M{"time": -1})
+	cursor, err := noShowsCollection.Find(context.Background(), filter, opts)
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(context.Background())
+
+	var noShows []models.NoShow
+	if err := cursor.All(context.Background(), &noShows); err != nil {
+		return result, err
+	}
+
+	result.NoShows = len(noShows)
+	if result.NoShows == 0 {
+		return result, nil
+	}
+
+	if policy.WarningThreshold > 0 && result.NoShows >= policy.WarningThreshold {
+		result.Penalty = PenaltyWarning
+	}
+
+	if policy.BanThreshold > 0 && result.NoShows >= policy.BanThreshold {
+		bannedUntil := noShows[0].Time.Add(policy.BanDuration)
+		if bannedUntil.After(now) {
+			result.Penalty = PenaltyBan
+			result.BannedUntil = &bannedUntil
+		}
+	}
+
+	// A ban still takes precedence, the deposit is required once it runs out
+	if policy.DepositThreshold > 0 && result.NoShows >= policy.DepositThreshold && result.Penalty != PenaltyBan {
+		result.Penalty = PenaltyDeposit
+		result.Deposit = policy.Deposit
+	}
+
+	return result, nil
+}
+
+// checkPenalty returns a non-nil response when the user's penalty refuses the reservation, and a warning when the user should be warned about their no-shows
+func checkPenalty(userID string, deposit int64, policy PenaltyPolicy, noShowsCollection *mongo.Collection) (*models.LimitErrorResponse, string, error) {
+	penalty, err := assessPenalty(userID, policy, noShowsCollection)
+	if err != nil {
+		return nil, "", err
+	}
+
+	switch penalty.Penalty {
+	case PenaltyBan:
+		return &models.LimitErrorResponse{
+			Error:      "Reservations are paused because of repeated no-shows",
+			Code:       QuotaNoShowCooldown,
+			Limit:      policy.BanThreshold,
+			Current:    penalty.NoShows,
+			RetryAfter: penalty.BannedUntil,
+		}, "", nil
+	case PenaltyDeposit:
+		if deposit < penalty.Deposit {
+			return &models.LimitErrorResponse{
+				Error:   "A deposit is required because of repeated no-shows",
+				Code:    QuotaDepositRequired,
+				Limit:   int(penalty.Deposit),
+				Current: int(deposit),
+			}, "", nil
+		}
+	case PenaltyWarning:
+		return nil, "You have recently missed reservations, further no-shows will pause your reservations", nil
+	}
+
+	return nil, "", nil
+}
+
+func recordNoShow(reservation models.Reservation, noShowsCollection *mongo.Collection) error {
+	_, err := noShowsCollection.InsertOne(context.Background(), models.NoShow{
+		UserID:        reservation.UserID,
+		ReservationID: reservation.ID,
+		Chargepoint:   reservation.Chargepoint,
+		Connector:     reservation.Connector,
+		Time:          reservation.ExpiryTime,
+	})
+
+	return err
+}
+
+// GetUserReliability godoc
+// @Summary Get a user's reliability
+// @Description The score is the percentage of the user's finished reservations they actually charged on. The penalty is based on the no-shows in the configured window that haven't been forgiven, and is either "None", "Warning", "Ban" or "Deposit".
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.Reliability
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/reliability [get]
+func GetUserReliability(userID string, collections Collections) (models.Reliability, error) {
+	reliability, err := assessPenalty(userID, LoadPenaltyPolicy(), collections.NoShows)
+	if err != nil {
+		return models.Reliability{}, err
+	}
+
+	finished, err := collections.Reservations.CountDocuments(context.Background(), bson.M{"userId": userID, "hasFinishedCharging": true, "walkIn": bson.M{"$ne": true}})
+	if err != nil {
+		return models.Reliability{}, err
+	}
+
+	noShows, err := collections.NoShows.CountDocuments(context.Background(), bson.M{"userId": userID, "forgiven": false})
+	if err != nil {
+		return models.Reliability{}, err
+	}
+
+	if noShows > finished {
+		noShows = finished
+	}
+
+	reliability.Reservations = int(finished)
+	reliability.Score = 100
+	if finished > 0 {
+		reliability.Score = 100 * float64(finished-noShows) / float64(finished)
+	}
+
+	return reliability, nil
+}
+
+// GetUserNoShows godoc
+// @Summary Get all of a user's no-shows
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} []models.NoShow
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/noshows [get]
+func GetUserNoShows(userID string, collection *mongo.Collection) ([]models.NoShow, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"userId": userID}, options.Find().SetSort(bson.M{"time": -1}))
+	if err != nil {
+		return []models.NoShow{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	noShows := []models.NoShow{}
+	if err := cursor.All(context.Background(), &noShows); err != nil {
+		return []models.NoShow{}, err
+	}
+
+	return noShows, nil
+}
+
+// ForgiveNoShow godoc
+// @Summary Forgive a no-show
+// @Description Forgiven no-shows no longer count towards the user's penalties or reliability score. Meant for operators, for example when the connector was broken.
+// @Tags Operators
+// @Accept json
+// @Produce json
+// @Param id path string true "No-show ID"
+// @Param body body ForgiveNoShowRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /noshows/{id}/forgive [post]
+func ForgiveNoShow(c *gin.Context, collection *mongo.Collection) {
+	var req ForgiveNoShowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Reason must be a non-empty string"})
+		return
+	}
+
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid no-show ID"})
+		return
+	}
+
+	result, err := collection.UpdateOne(context.Background(), bson.M{"_id": id}, bson.M{"$set": bson.M{"forgiven": true, "forgivenReason": req.Reason}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not forgive the no-show"})
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "No-show not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "No-show forgiven"})
+}
+
+type ForgiveNoShowRequest struct {
+	Reason string `json:"reason"`
+}
@@ -0,0 +1,135 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestNoShows(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	noShowsCollection := client.Database("TestDB").Collection("noshows")
+
+	router := gin.Default()
+
+	router.POST("/noshows/:id/forgive", func(c *gin.Context) {
+		ForgiveNoShow(c, noShowsCollection)
+	})
+
+	defer func() {
+		err := db.ClearCollection(noShowsCollection)
+		if err != nil {
+			t.Fatalf("Failed to clear collection:\n%v", err)
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	policy := PenaltyPolicy{
+		Window:           24 * time.Hour,
+		WarningThreshold: 1,
+		BanThreshold:     2,
+		BanDuration:      time.Hour,
+		DepositThreshold: 3,
+		Deposit:          1000,
+	}
+
+	insertNoShows := func(userID string, times ...time.Time) {
+		for _, noShowTime := range times {
+			_, err := noShowsCollection.InsertOne(context.Background(), models.NoShow{UserID: userID, Time: noShowTime})
+			if err != nil {
+				t.Fatalf("Could not insert no-show:\n%v", err)
+			}
+		}
+	}
+
+	now := time.Now()
+	insertNoShows("penaltyWarning", now.Add(-time.Hour))
+	insertNoShows("penaltyBan", now.Add(-2*time.Hour), now.Add(-10*time.Minute))
+	insertNoShows("penaltyDeposit", now.Add(-5*time.Hour), now.Add(-4*time.Hour), now.Add(-3*time.Hour))
+	insertNoShows("penaltyExpired", now.Add(-72*time.Hour), now.Add(-48*time.Hour))
+
+	tests := []struct {
+		userID  string
+		deposit int64
+		penalty string
+		code    string
+	}{
+		{userID: "penaltyNone", penalty: PenaltyNone, code: ""},
+		{userID: "penaltyWarning", penalty: PenaltyWarning, code: ""},
+		{userID: "penaltyBan", penalty: PenaltyBan, code: QuotaNoShowCooldown},
+		{userID: "penaltyDeposit", penalty: PenaltyDeposit, code: QuotaDepositRequired},
+		{userID: "penaltyDeposit", deposit: 1000, penalty: PenaltyDeposit, code: ""},
+		{userID: "penaltyExpired", penalty: PenaltyNone, code: ""},
+	}
+
+	for _, test := range tests {
+		t.Run("CheckPenalty", func(t *testing.T) {
+			penalty, err := assessPenalty(test.userID, policy, noShowsCollection)
+			if err != nil {
+				t.Fatalf("Could not assess penalty:\n%v", err)
+			}
+
+			if penalty.Penalty != test.penalty {
+				t.Errorf("Expected penalty %s for %s, but received %s", test.penalty, test.userID, penalty.Penalty)
+			}
+
+			limit, _, err := checkPenalty(test.userID, test.deposit, policy, noShowsCollection)
+			if err != nil {
+				t.Fatalf("Could not check penalty:\n%v", err)
+			}
+
+			code := ""
+			if limit != nil {
+				code = limit.Code
+			}
+
+			if code != test.code {
+				t.Errorf("Expected limit code %q for %s, but received %q", test.code, test.userID, code)
+			}
+		})
+	}
+
+	t.Run("ForgiveNoShow", func(t *testing.T) {
+		var noShow models.NoShow
+		err := noShowsCollection.FindOne(context.Background(), bson.M{"userId": "penaltyBan"}).Decode(&noShow)
+		if err != nil {
+			t.Fatalf("Could not find no-show:\n%v", err)
+		}
+
+		body, _ := json.Marshal(map[string]string{"reason": "Connector was broken"})
+		req, _ := http.NewRequest("POST", "/noshows/"+noShow.ID.Hex()+"/forgive", bytes.NewReader(body))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+
+		penalty, err := assessPenalty("penaltyBan", policy, noShowsCollection)
+		if err != nil {
+			t.Fatalf("Could not assess penalty:\n%v", err)
+		}
+
+		if penalty.Penalty != PenaltyWarning {
+			t.Errorf("Expected penalty %s after forgiving, but received %s", PenaltyWarning, penalty.Penalty)
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package endpoints
+
+import (
+	"context"
+	"reservations/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Notification types
+const (
+	NotificationLateSession = "LateSession"
+	NotificationBumped      = "Bumped"
+)
+
+// notify records a notification for the user, which they get from GET /users/{id}/notifications
+func notify(notification models.Notification, collection *mongo.Collection) error {
+	notification.ID = primitive.NewObjectID()
+	notification.CreatedAt = time.Now()
+
+	_, err := collection.InsertOne(context.Background(), notification)
+	return err
+}
+
+// GetUserNotifications godoc
+// @Summary Get a user's notifications
+// @Description Lists what happened to the user's reservations, newest first. For example, the user is warned when the vehicle before them is still plugged in shortly before their reservation starts.
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} []models.Notification
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/notifications [get]
+func GetUserNotifications(userID string, collection *mongo.Collection) ([]models.Notification, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"userId": userID}, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := []models.Notification{}
+	if err := cursor.All(context.Background(), &notifications); err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
@@ -0,0 +1,101 @@
+package endpoints
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestLateSessionNotifications(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.GET("/users/:id/notifications", func(c *gin.Context) {
+		notifications, err := GetUserNotifications(c.Param("id"), collections.Notifications)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch the user's notifications"})
+			return
+		}
+
+		c.JSON(http.StatusOK, notifications)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Reservations, collections.Sessions, collections.Notifications} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	now := time.Now()
+
+	// The previous vehicle is still plugged in on the first connector, the second connector's vehicle already left
+	collections.Sessions.InsertOne(context.Background(), models.ChargingSession{ReservationID: primitive.NewObjectID(), UserID: "lateDriver", Chargepoint: "lateChargepoint", Connector: 1, Status: SessionCompleted, IdleSince: now.Add(-5 * time.Minute)})
+	collections.Sessions.InsertOne(context.Background(), models.ChargingSession{ReservationID: primitive.NewObjectID(), UserID: "lateDriver", Chargepoint: "lateChargepoint", Connector: 2, Status: SessionCompleted, IdleSince: now.Add(-5 * time.Minute), UnpluggedAt: now.Add(-time.Minute)})
+
+	for connector, userID := range map[int]string{1: "nextDriver", 2: "otherDriver"} {
+		start := now.Add(10 * time.Minute)
+		collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), UserID: userID, Chargepoint: "lateChargepoint", Connector: connector, StartTime: start, ExpiryTime: start.Add(10 * time.Minute), ChargingTime: start.Add(time.Hour)})
+	}
+
+	// The warning is only given once
+	checkLateSessions(collections)
+	checkLateSessions(collections)
+
+	notifications := func(userID string) []models.Notification {
+		req, _ := http.NewRequest("GET", "/users/"+userID+"/notifications", nil)
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		var notifications []models.Notification
+		json.Unmarshal(recorder.Body.Bytes(), &notifications)
+		return notifications
+	}
+
+	tests := []struct {
+		name   string
+		userID string
+		count  int
+	}{
+		{name: "NextHolder", userID: "nextDriver", count: 1},
+		{name: "VehicleLeft", userID: "otherDriver", count: 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			received := notifications(test.userID)
+			if len(received) != test.count {
+				t.Fatalf("Expected %d notifications, but received %v", test.count, received)
+			}
+
+			for _, notification := range received {
+				if notification.Type != NotificationLateSession {
+					t.Errorf("Expected a late session warning, but received %v", notification)
+				}
+			}
+		})
+	}
+}
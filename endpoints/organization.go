@@ -0,0 +1,349 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/db"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Codes returned in models.LimitErrorResponse when an organization's limit is hit
+const (
+	QuotaOrganizationMonthlyMinutes = "ORGANIZATION_MONTHLY_MINUTES"
+	QuotaCapacityHeld               = "CAPACITY_HELD"
+)
+
+// CreateOrganization godoc
+// @Summary Create a new organization
+// @Description Admins must be existing users, and they become members of the organization. Reserved capacity holds a number of a chargepoint's connectors for the organization's members.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param body body CreateOrganizationRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /organizations/{id} [post]
+func CreateOrganization(c *gin.Context, collections Collections) {
+	var req CreateOrganizationRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	id := c.Param("id")
+
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Name must be a non-empty string"})
+		return
+	}
+
+	if len(req.Admins) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "An organization needs at least one admin"})
+		return
+	}
+
+	if req.MonthlyMinutes < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Monthly minutes can't be negative"})
+		return
+	}
+
+	for _, admin := range req.Admins {
+		user, err := FindUserByID(admin, collections.Users)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("User %s does not exist", admin)})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch users"})
+			return
+		}
+
+		if user.OrganizationID != "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("User %s already belongs to an organization", admin)})
+			return
+		}
+	}
+
+	for _, capacity := range req.ReservedCapacity {
+		chargepoint, err := FindChargepointByID(capacity.Chargepoint, collections.Chargepoints)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Chargepoint %s does not exist", capacity.Chargepoint)})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+			return
+		}
+
+		if capacity.Connectors <= 0 || capacity.Connectors > len(chargepoint.Connectors) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Reserved connectors must be between 1 and the amount of the chargepoint's connectors"})
+			return
+		}
+	}
+
+	newOrganization := models.Organization{
+		ID:               id,
+		Name:             req.Name,
+		Admins:           req.Admins,
+		MonthlyMinutes:   req.MonthlyMinutes,
+		ReservedCapacity: req.ReservedCapacity,
+	}
+	if newOrganization.ReservedCapacity == nil {
+		newOrganization.ReservedCapacity = []models.ReservedCapacity{}
+	}
+
+	_, err := collections.Organizations.InsertOne(context.Background(), newOrganization)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create a new organization, perhaps an existing ID was entered"})
+		return
+	}
+
+	_, err = collections.Users.UpdateMany(context.Background(), bson.M{"_id": bson.M{"$in": req.Admins}}, bson.M{"$set": bson.M{"organizationId": id}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not add the admins to the organization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Organization created"})
+}
+
+type CreateOrganizationRequest struct {
+	Name             string                    `json:"name"`
+	Admins           []string                  `json:"admins"`
+	MonthlyMinutes   int                       `json:"monthlyMinutes"`
+	ReservedCapacity []models.ReservedCapacity `json:"reservedCapacity"`
+}
+
+// FindOrganizationByID godoc
+// @Summary Get information about an organization by ID
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Success 200 {object} models.Organization
+// @Failure 404 {object} models.ErrorResponse
+// @Router /organizations/{id} [get]
+func FindOrganizationByID(id string, collection *mongo.Collection) (models.Organization, error) {
+	var organization models.Organization
+
+	err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&organization)
+	if err != nil {
+		return models.Organization{}, err
+	}
+
+	return organization, nil
+}
+
+// GetAllOrganizations godoc
+// @Summary Get all organizations
+// @Tags Organizations
+// @Produce json
+// @Success 200 {object} []models.Organization
+// @Failure 500 {object} models.ErrorResponse
+// @Router /organizations [get]
+func GetAllOrganizations(collection *mongo.Collection) ([]bson.M, error) {
+	documents, err := db.GetAllDocumentsInCollection(collection)
+	if err != nil {
+		return []bson.M{}, err
+	}
+
+	return documents, nil
+}
+
+// AddOrganizationMember godoc
+// @Summary Add a user to an organization
+// @Description Only the organization's admins can add members, and a user can only belong to one organization.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param userID path string true "User ID"
+// @Param body body OrganizationAdminRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /organizations/{id}/members/{userID} [post]
+func AddOrganizationMember(c *gin.Context, collections Collections) {
+	var req OrganizationAdminRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	organization, ok := findOrganizationAsAdmin(c, c.Param("id"), req.AdminID, collections.Organizations)
+	if !ok {
+		return
+	}
+
+	user, err := FindUserByID(c.Param("userID"), collections.Users)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch users"})
+		return
+	}
+
+	if user.OrganizationID != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User already belongs to an organization"})
+		return
+	}
+
+	_, err = collections.Users.UpdateOne(context.Background(), bson.M{"_id": user.ID}, bson.M{"$set": bson.M{"organizationId": organization.ID}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not add the user to the organization"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Member added"})
+}
+
+type OrganizationAdminRequest struct {
+	AdminID string `json:"adminId"`
+}
+
+// GetOrganizationReservations godoc
+// @Summary Get all reservations of an organization's members
+// @Description Only the organization's admins can view its reservations.
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param adminId query string true "Admin's user ID"
+// @Success 200 {object} []models.Reservation
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /organizations/{id}/reservations [get]
+func GetOrganizationReservations(c *gin.Context, collections Collections) {
+	organization, ok := findOrganizationAsAdmin(c, c.Param("id"), c.Query("adminId"), collections.Organizations)
+	if !ok {
+		return
+	}
+
+	cursor, err := collections.Reservations.Find(context.Background(), bson.M{"organizationId": organization.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch reservations"})
+		return
+	}
+	defer cursor.Close(context.Background())
+
+	reservations := []models.Reservation{}
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch reservations"})
+		return
+	}
+
+	c.JSON(http.StatusOK, reservations)
+}
+
+// findOrganizationAsAdmin fetches the organization and makes sure the user is one of its admins, writing the error response otherwise
+func findOrganizationAsAdmin(c *gin.Context, id, adminID string, collection *mongo.Collection) (models.Organization, bool) {
+	organization, err := FindOrganizationByID(id, collection)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Organization not found"})
+			return models.Organization{}, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch organizations"})
+		return models.Organization{}, false
+	}
+
+	for _, admin := range organization.Admins {
+		if admin == adminID {
+			return organization, true
+		}
+	}
+
+	c.JSON(http.StatusForbidden, models.ErrorResponse{Error: "Only the organization's admins can do this"})
+	return models.Organization{}, false
+}
+
+// checkOrganizationLimits returns a non-nil response when the reservation would exceed the user's organization's monthly budget, or take a connector another organization is holding
+func checkOrganizationLimits(user models.User, chargepoint models.Chargepoint, minutes int, collections Collections) (*models.LimitErrorResponse, error) {
+	if user.OrganizationID != "" {
+		organization, err := FindOrganizationByID(user.OrganizationID, collections.Organizations)
+		if err != nil {
+			return nil, err
+		}
+
+		if organization.MonthlyMinutes > 0 {
+			now := time.Now()
+			startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+
+			reserved, err := sumReservedMinutes(bson.M{"organizationId": organization.ID, "createdAt": bson.M{"$gte": startOfMonth}, "walkIn": bson.M{"$ne": true}}, collections.Reservations)
+			if err != nil {
+				return nil, err
+			}
+
+			if reserved+minutes > organization.MonthlyMinutes {
+				nextMonth := startOfMonth.AddDate(0, 1, 0)
+				return &models.LimitErrorResponse{
+					Error:      fmt.Sprintf("The organization can reserve at most %d minutes per month", organization.MonthlyMinutes),
+					Code:       QuotaOrganizationMonthlyMinutes,
+					Limit:      organization.MonthlyMinutes,
+					Current:    reserved,
+					RetryAfter: &nextMonth,
+				}, nil
+			}
+		}
+	}
+
+	// Other organizations' members can't take the last connectors their organization is holding
+	cursor, err := collections.Organizations.Find(context.Background(), bson.M{"_id": bson.M{"$ne": user.OrganizationID}, "reservedCapacity.chargepoint": chargepoint.ID})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(context.Background())
+
+	var organizations []models.Organization
+	if err := cursor.All(context.Background(), &organizations); err != nil {
+		return nil, err
+	}
+
+	held := 0
+	for _, organization := range organizations {
+		for _, capacity := range organization.ReservedCapacity {
+			if capacity.Chargepoint != chargepoint.ID {
+				continue
+			}
+
+			inUse, err := collections.Reservations.CountDocuments(context.Background(), bson.M{"organizationId": organization.ID, "chargepoint": chargepoint.ID, "hasFinishedCharging": false})
+			if err != nil {
+				return nil, err
+			}
+
+			if int(inUse) < capacity.Connectors {
+				held += capacity.Connectors - int(inUse)
+			}
+		}
+	}
+
+	available := 0
+	for _, connector := range chargepoint.Connectors {
+		if connector.State == "Available" {
+			available++
+		}
+	}
+
+	if held > 0 && available <= held {
+		return &models.LimitErrorResponse{
+			Error:   "The remaining connectors on the chargepoint are held for an organization",
+			Code:    QuotaCapacityHeld,
+			Limit:   held,
+			Current: available,
+		}, nil
+	}
+
+	return nil, nil
+}
@@ -0,0 +1,115 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestOrganizations(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/organizations/:id", func(c *gin.Context) {
+		CreateOrganization(c, collections)
+	})
+
+	router.POST("/organizations/:id/members/:userID", func(c *gin.Context) {
+		AddOrganizationMember(c, collections)
+	})
+
+	router.GET("/organizations/:id/reservations", func(c *gin.Context) {
+		GetOrganizationReservations(c, collections)
+	})
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Organizations} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	for _, id := range []string{"fleetAdmin", "fleetDriver", "outsider"} {
+		collections.Users.InsertOne(context.Background(), models.User{ID: id, Name: id})
+	}
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "depot", Connectors: []models.Connector{
+		{ID: 1, State: "Available"},
+		{ID: 2, State: "Available"},
+		{ID: 3, State: "Available"},
+	}})
+
+	request := func(method, endpoint string, body any) int {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		endpoint string
+		body     any
+		code     int
+	}{
+		{name: "CreateOrganization", method: "POST", endpoint: "/organizations/fleet", body: map[string]any{"name": "Fleet", "admins": []string{"fleetAdmin"}, "monthlyMinutes": 100, "reservedCapacity": []map[string]any{{"chargepoint": "depot", "connectors": 3}}}, code: http.StatusOK},
+		{name: "CreateOrganizationWithoutAdmins", method: "POST", endpoint: "/organizations/empty", body: map[string]any{"name": "Empty"}, code: http.StatusBadRequest},
+		{name: "AddMemberAsNonAdmin", method: "POST", endpoint: "/organizations/fleet/members/fleetDriver", body: map[string]string{"adminId": "outsider"}, code: http.StatusForbidden},
+		{name: "AddMember", method: "POST", endpoint: "/organizations/fleet/members/fleetDriver", body: map[string]string{"adminId": "fleetAdmin"}, code: http.StatusOK},
+		{name: "ReserveAsMember", method: "POST", endpoint: "/reservations/depot/1", body: map[string]any{"userId": "fleetDriver", "minutes": 60}, code: http.StatusOK},
+		{name: "ExceedMonthlyBudget", method: "POST", endpoint: "/reservations/depot/2", body: map[string]any{"userId": "fleetAdmin", "minutes": 60}, code: http.StatusForbidden},
+		{name: "TakeHeldCapacity", method: "POST", endpoint: "/reservations/depot/3", body: map[string]any{"userId": "outsider", "minutes": 60}, code: http.StatusForbidden},
+		{name: "ViewReservationsAsNonAdmin", method: "GET", endpoint: "/organizations/fleet/reservations?adminId=fleetDriver", code: http.StatusForbidden},
+		{name: "ViewReservations", method: "GET", endpoint: "/organizations/fleet/reservations?adminId=fleetAdmin", code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := request(test.method, test.endpoint, test.body)
+			if code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+
+	t.Run("ReservationAttributedToOrganization", func(t *testing.T) {
+		count, err := collections.Reservations.CountDocuments(context.Background(), bson.M{"organizationId": "fleet"})
+		if err != nil {
+			t.Fatalf("Could not count reservations:\n%v", err)
+		}
+
+		if count != 1 {
+			t.Errorf("Expected %d reservation attributed to the organization, but found %d", 1, count)
+		}
+	})
+}
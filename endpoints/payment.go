@@ -0,0 +1,487 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"reservations/models"
+	"reservations/payments"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Payment statuses
+const (
+	PaymentAuthorized = "Authorized"
+	PaymentCaptured   = "Captured"
+	PaymentVoided     = "Voided"
+	PaymentRefunded   = "Refunded"
+	PaymentFailed     = "Failed"
+)
+
+var (
+	fakeGateway     *payments.FakeGateway
+	fakeGatewayOnce sync.Once
+)
+
+// paymentGateway returns the gateway configured by PAYMENT_GATEWAY, or nil when card payments are disabled
+func paymentGateway() payments.Gateway {
+	switch os.Getenv("PAYMENT_GATEWAY") {
+	case "fake":
+		// The fake gateway keeps its payments in memory, so every request has to share it
+		fakeGatewayOnce.Do(func() {
+			fakeGateway = payments.NewFakeGateway(os.Getenv("PAYMENT_WEBHOOK_SECRET"))
+		})
+		return fakeGateway
+	case "stripe":
+		return payments.NewStripeGateway(os.Getenv("STRIPE_API_URL"), os.Getenv("STRIPE_SECRET_KEY"), os.Getenv("PAYMENT_WEBHOOK_SECRET"))
+	default:
+		return nil
+	}
+}
+
+// How reservations are paid for
+const (
+	PaymentModeWallet  = "Wallet"
+	PaymentModeCard    = "Card"
+	PaymentModeInvoice = "Invoice"
+)
+
+// paymentModeOf tells how the user's reservations are paid for. Prepaid users pay from their wallet, members of an organization are billed through it and everyone else pays by card, or by invoice when card payments are disabled.
+func paymentModeOf(user models.User, collections Collections) (string, error) {
+	prepaid, err := hasWallet(user, collections.Wallets)
+	if err != nil {
+		return "", err
+	}
+
+	if prepaid {
+		return PaymentModeWallet, nil
+	}
+	if paymentGateway() != nil && user.OrganizationID == "" {
+		return PaymentModeCard, nil
+	}
+	return PaymentModeInvoice, nil
+}
+
+// reservationPaymentMode tells how the reservation is paid for. Topping up a wallet or joining an organization later doesn't change it, reservations made before the mode was saved are paid from the wallet when it holds for them, or the way the user pays now.
+func reservationPaymentMode(reservationID primitive.ObjectID, userID string, collections Collections) (string, error) {
+	if !reservationID.IsZero() {
+		reservation, err := FindReservationByID(reservationID.Hex(), collections.Reservations)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return "", err
+		}
+		if reservation.PaymentMode != "" {
+			return reservation.PaymentMode, nil
+		}
+
+		held, err := hasHold(reservationID, collections.Ledger)
+		if err != nil || held {
+			return PaymentModeWallet, err
+		}
+	}
+
+	user, err := FindUserByID(userID, collections.Users)
+	if err != nil {
+		return "", err
+	}
+
+	return paymentModeOf(user, collections)
+}
+
+// securePayment sets the estimated price of the reservation and its deposit aside before it's made. Prepaid users need enough balance for it, which is held until the session is paid for. Everyone else but organization members pays by card, the amount is pre-authorized and the session's price captured when it ends. The deposit is only kept on a no-show.
+func securePayment(user models.User, reservation models.Reservation, estimate models.Price, paymentMethod string, collections Collections) *reservationError {
+	amount := estimate.Total + reservation.Deposit
+	if amount <= 0 {
+		return nil
+	}
+
+	currency := estimate.Currency
+	if currency == "" {
+		currency = defaultCurrency()
+	}
+
+	switch reservation.PaymentMode {
+	case PaymentModeWallet:
+		limit, err := placeHold(user.ID, reservation.ID, amount, collections)
+		if err != nil {
+			return &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not place a hold on the user's wallet"}}
+		}
+		if limit != nil {
+			return &reservationError{Status: http.StatusPaymentRequired, Body: limit}
+		}
+
+	case PaymentModeCard:
+		if paymentMethod == "" {
+			return &reservationError{Status: http.StatusPaymentRequired, Body: models.ErrorResponse{Error: "A payment method is required"}}
+		}
+
+		_, err := authorizePayment(reservation, amount, currency, paymentMethod, collections)
+		if err != nil {
+			if err == payments.ErrDeclined {
+				return &reservationError{Status: http.StatusPaymentRequired, Body: models.ErrorResponse{Error: "The payment method was declined"}}
+			}
+			return &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not authorize the payment"}}
+		}
+	}
+
+	return nil
+}
+
+// authorizePayment pre-authorizes the reservation's estimated price on the payment method
+func authorizePayment(reservation models.Reservation, amount int64, currency, paymentMethod string, collections Collections) (models.Payment, error) {
+	return authorizePaymentAs("reservation-"+reservation.ID.Hex(), reservation, amount, currency, paymentMethod, collections)
+}
+
+// authorizePaymentAs is authorizePayment with the gateway's reference, which makes retrying it with the same reference harmless
+func authorizePaymentAs(reference string, reservation models.Reservation, amount int64, currency, paymentMethod string, collections Collections) (models.Payment, error) {
+	gateway := paymentGateway()
+
+	authorization, err := gateway.Authorize(amount, currency, paymentMethod, reference)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	payment := models.Payment{
+		ID:              primitive.NewObjectID(),
+		ReservationID:   reservation.ID,
+		UserID:          reservation.UserID,
+		Gateway:         gateway.Name(),
+		AuthorizationID: authorization.ID,
+		PaymentMethod:   paymentMethod,
+		Currency:        currency,
+		Authorized:      authorization.Amount,
+		Deposit:         reservation.Deposit,
+		Status:          PaymentAuthorized,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+
+	_, err = collections.Payments.InsertOne(context.Background(), payment)
+	return payment, err
+}
+
+// paymentChange is a reservation's hold or card authorization resized for a change of the reservation. It is kept once the change is made, or undone when it isn't.
+type paymentChange struct {
+	reservation models.Reservation
+	// What the wallet held for the reservation before
+	held    int64
+	prepaid bool
+	// The card authorization that is replaced, and the one replacing it
+	previous *models.Payment
+	current  *models.Payment
+}
+
+// resizePayment sets the estimated price of the changed reservation and its deposit aside instead of what was set aside before. Cards can't change what they authorized, so they are authorized again with the payment method the reservation was made with.
+func resizePayment(user models.User, mode string, reservation models.Reservation, estimate models.Price, collections Collections) (*paymentChange, *reservationError) {
+	change := &paymentChange{reservation: reservation, prepaid: mode == PaymentModeWallet}
+	amount := estimate.Total + reservation.Deposit
+
+	if mode == PaymentModeWallet {
+		held, limit, err := resizeHold(user.ID, reservation.ID, amount, collections)
+		if err != nil {
+			return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not change the hold on the user's wallet"}}
+		}
+		if limit != nil {
+			return nil, &reservationError{Status: http.StatusPaymentRequired, Body: limit}
+		}
+		change.held = held
+		return change, nil
+	}
+
+	if mode != PaymentModeCard || paymentGateway() == nil {
+		return change, nil
+	}
+
+	previous, err := findPayment(reservation.ID, PaymentAuthorized, collections.Payments)
+	if err != nil {
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch payments"}}
+	}
+	// Without the payment method the authorization is kept as it is, and what it doesn't cover is left to the invoice
+	if previous == nil || previous.Authorized == amount || previous.PaymentMethod == "" {
+		return change, nil
+	}
+	change.previous = previous
+	if amount <= 0 {
+		return change, nil
+	}
+
+	current, err := authorizePaymentAs("reservation-"+reservation.ID.Hex()+"-"+primitive.NewObjectID().Hex(), reservation, amount, previous.Currency, previous.PaymentMethod, collections)
+	if err != nil {
+		if err == payments.ErrDeclined {
+			return nil, &reservationError{Status: http.StatusPaymentRequired, Body: models.ErrorResponse{Error: "The payment method was declined"}}
+		}
+		return nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not authorize the payment"}}
+	}
+	change.current = &current
+
+	return change, nil
+}
+
+// keep gives back the card authorization that was replaced
+func (change *paymentChange) keep(collections Collections) {
+	if change.previous != nil {
+		if err := voidAuthorization(*change.previous, collections); err != nil {
+			fmt.Println("Error voiding payment: ", err)
+		}
+	}
+}
+
+// undo sets aside what was set aside before the change
+func (change *paymentChange) undo(collections Collections) {
+	if change.prepaid {
+		if _, _, err := resizeHold(change.reservation.UserID, change.reservation.ID, change.held, collections); err != nil {
+			fmt.Println("Error restoring wallet hold: ", err)
+		}
+	}
+
+	if change.current != nil {
+		if err := voidAuthorization(*change.current, collections); err != nil {
+			fmt.Println("Error voiding payment: ", err)
+		}
+	}
+}
+
+// findPayment returns the reservation's payment in the given status, or nil when there is none. Payments for fees are left out.
+func findPayment(reservationID primitive.ObjectID, status string, collection *mongo.Collection) (*models.Payment, error) {
+	var payment models.Payment
+	err := collection.FindOne(context.Background(), bson.M{"reservationId": reservationID, "status": status, "fee": bson.M{"$ne": true}}).Decode(&payment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+func updatePayment(payment models.Payment, update bson.M, collection *mongo.Collection) error {
+	update["updatedAt"] = time.Now()
+	_, err := collection.UpdateOne(context.Background(), bson.M{"_id": payment.ID}, bson.M{"$set": update})
+	return err
+}
+
+// capturePayment charges the session's price to the reservation's authorized card payment. The deposit isn't captured since the user showed up, whatever the authorization doesn't cover is left to the invoice.
+func capturePayment(session models.ChargingSession, collections Collections) error {
+	payment, err := findPayment(session.ReservationID, PaymentAuthorized, collections.Payments)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	price, err := GetSessionPrice(session, collections)
+	if err != nil {
+		return err
+	}
+
+	amount := price.Total
+	if amount > payment.Authorized-payment.Deposit {
+		amount = payment.Authorized - payment.Deposit
+	}
+
+	return capturePaymentAmount(session.ReservationID, amount, collections)
+}
+
+// capturePaymentAmount captures the amount from the reservation's card authorization, if it hasn't been captured yet. Authorizations can't be captured for more than they hold, so the capture is capped at the authorized amount.
+func capturePaymentAmount(reservationID primitive.ObjectID, amount int64, collections Collections) error {
+	payment, err := findPayment(reservationID, PaymentAuthorized, collections.Payments)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	if amount > payment.Authorized {
+		amount = payment.Authorized
+	}
+
+	if amount <= 0 {
+		return voidPayment(reservationID, collections)
+	}
+
+	err = paymentGateway().Capture(payment.AuthorizationID, amount)
+	if err != nil {
+		return err
+	}
+
+	return updatePayment(*payment, bson.M{"status": PaymentCaptured, "captured": amount, "capturedAt": time.Now()}, collections.Payments)
+}
+
+// chargeCard takes the amount from the card the reservation was paid with, in a payment of its own, for fees that come after the reservation's payment was captured
+func chargeCard(reservationID primitive.ObjectID, amount int64, currency string, collections Collections) error {
+	paid, err := findPayment(reservationID, PaymentCaptured, collections.Payments)
+	if err != nil || paid == nil || paid.PaymentMethod == "" || amount <= 0 || paymentGateway() == nil {
+		return err
+	}
+
+	payment, err := authorizePaymentAs("fee-"+primitive.NewObjectID().Hex(), models.Reservation{ID: reservationID, UserID: paid.UserID}, amount, currency, paid.PaymentMethod, collections)
+	if err != nil {
+		return err
+	}
+
+	err = paymentGateway().Capture(payment.AuthorizationID, payment.Authorized)
+	if err != nil {
+		return err
+	}
+
+	return updatePayment(payment, bson.M{"fee": true, "status": PaymentCaptured, "captured": payment.Authorized, "capturedAt": time.Now()}, collections.Payments)
+}
+
+// voidPayment releases the reservation's card authorization if it was never captured
+func voidPayment(reservationID primitive.ObjectID, collections Collections) error {
+	payment, err := findPayment(reservationID, PaymentAuthorized, collections.Payments)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	return voidAuthorization(*payment, collections)
+}
+
+// voidAuthorization releases the card authorization
+func voidAuthorization(payment models.Payment, collections Collections) error {
+	err := paymentGateway().Void(payment.AuthorizationID)
+	if err != nil {
+		return err
+	}
+
+	return updatePayment(payment, bson.M{"status": PaymentVoided}, collections.Payments)
+}
+
+// refundPayment gives back whatever was captured from the reservation's card payment
+func refundPayment(reservationID primitive.ObjectID, collections Collections) error {
+	payment, err := findPayment(reservationID, PaymentCaptured, collections.Payments)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	err = paymentGateway().Refund(payment.AuthorizationID, payment.Captured)
+	if err != nil {
+		return err
+	}
+
+	return updatePayment(*payment, bson.M{"status": PaymentRefunded, "refunded": payment.Captured, "refundedAt": time.Now()}, collections.Payments)
+}
+
+// GetReservationPayment godoc
+// @Summary Get the card payment of a reservation
+// @Tags Payments
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} models.Payment
+// @Failure 404 {object} models.ErrorResponse
+// @Router /reservations/{id}/payment [get]
+func GetReservationPayment(id string, collection *mongo.Collection) (models.Payment, error) {
+	var payment models.Payment
+
+	reservationID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Payment{}, mongo.ErrNoDocuments
+	}
+
+	// A modified reservation's latest authorization replaces the voided ones before it, fees are paid for separately
+	opts := options.FindOne().SetSort(bson.M{"createdAt": -1})
+	err = collection.FindOne(context.Background(), bson.M{"reservationId": reservationID, "fee": bson.M{"$ne": true}}, opts).Decode(&payment)
+	if err != nil {
+		return models.Payment{}, err
+	}
+
+	return payment, nil
+}
+
+// PaymentWebhook godoc
+// @Summary Receive a payment gateway webhook
+// @Description Called by the payment gateway when a payment changes, signed with the webhook secret (in the Stripe-Signature header for Stripe, X-Signature for the fake gateway). Gateways can deliver an event more than once, events that were already processed are acknowledged without being applied again.
+// @Tags Payments
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /payments/webhook [post]
+func PaymentWebhook(c *gin.Context, collections Collections) {
+	gateway := paymentGateway()
+	if gateway == nil {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Card payments are disabled"})
+		return
+	}
+
+	payload, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	signature := c.GetHeader("Stripe-Signature")
+	if signature == "" {
+		signature = c.GetHeader("X-Signature")
+	}
+
+	event, err := gateway.VerifyWebhook(payload, signature)
+	if err != nil {
+		if err == payments.ErrUnknownEvent {
+			c.JSON(http.StatusOK, models.MessageResponse{Message: "Event ignored"})
+			return
+		}
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid webhook signature or payload"})
+		return
+	}
+
+	eventID := gateway.Name() + ":" + event.ID
+
+	processed, err := collections.PaymentEvents.CountDocuments(context.Background(), bson.M{"_id": eventID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch payment events"})
+		return
+	}
+	if processed > 0 {
+		c.JSON(http.StatusOK, models.MessageResponse{Message: "Event already processed"})
+		return
+	}
+
+	// Every update sets absolute values, so applying an event twice at the same time is harmless.
+	// An event only applies to the statuses it can follow, so one that arrives late or out of order
+	// doesn't turn a refunded payment back into a captured one
+	update := bson.M{"updatedAt": time.Now()}
+	var from []string
+	switch event.Type {
+	case payments.EventCaptured:
+		update["status"] = PaymentCaptured
+		update["capturedAt"] = update["updatedAt"]
+		if event.Amount > 0 {
+			update["captured"] = event.Amount
+		}
+		from = []string{PaymentAuthorized, PaymentCaptured}
+	case payments.EventVoided:
+		update["status"] = PaymentVoided
+		from = []string{PaymentAuthorized}
+	case payments.EventRefunded:
+		update["status"] = PaymentRefunded
+		update["refunded"] = event.Amount
+		update["refundedAt"] = update["updatedAt"]
+		from = []string{PaymentCaptured, PaymentRefunded}
+	case payments.EventFailed:
+		update["status"] = PaymentFailed
+		from = []string{PaymentAuthorized}
+	}
+
+	filter := bson.M{"gateway": gateway.Name(), "authorizationId": event.AuthorizationID, "status": bson.M{"$in": from}}
+	_, err = collections.Payments.UpdateOne(context.Background(), filter, bson.M{"$set": update})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the payment"})
+		return
+	}
+
+	_, err = collections.PaymentEvents.InsertOne(context.Background(), bson.M{"_id": eventID, "type": event.Type, "authorizationId": event.AuthorizationID, "receivedAt": time.Now()})
+	if err != nil && !mongo.IsDuplicateKeyError(err) {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not record the payment event"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Event processed"})
+}
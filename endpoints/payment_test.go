@@ -0,0 +1,222 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reservations/db"
+	"reservations/models"
+	"reservations/payments"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCardPayments(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	os.Setenv("PAYMENT_GATEWAY", "fake")
+	os.Setenv("PAYMENT_WEBHOOK_SECRET", "whsec_test")
+	defer os.Unsetenv("PAYMENT_GATEWAY")
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	router.POST("/charge/:cpID/:coID", func(c *gin.Context) {
+		Charge(c, collections)
+	})
+
+	router.POST("/stop/:cpID/:coID", func(c *gin.Context) {
+		StopCharging(c, collections)
+	})
+
+	router.POST("/cancel/:id", func(c *gin.Context) {
+		OperatorCancelReservation(c, collections)
+	})
+
+	router.POST("/payments/webhook", func(c *gin.Context) {
+		PaymentWebhook(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Tariffs, collections.Sessions, collections.Payments, collections.PaymentEvents, collections.NoShows, collections.Fees, collections.Wallets, collections.Ledger} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "cardUser", Name: "Card user"})
+	collections.Tariffs.InsertOne(context.Background(), models.Tariff{ID: "cardTariff", Name: "Per minute", Currency: "EUR", PerMinute: 10, SessionFee: 100})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "cardChargepoint", TariffID: "cardTariff", Connectors: []models.Connector{
+		{ID: 1, State: "Available"},
+		{ID: 2, State: "Available"},
+	}})
+
+	request := func(endpoint string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	payment := func(t *testing.T, connector int) models.Payment {
+		var reservation models.Reservation
+		err := collections.Reservations.FindOne(context.Background(), bson.M{"userId": "cardUser", "connector": connector}).Decode(&reservation)
+		if err != nil {
+			t.Fatalf("Could not find the reservation:\n%v", err)
+		}
+
+		payment, err := GetReservationPayment(fmt.Sprint(reservation.ID), collections.Payments)
+		if err != nil {
+			t.Fatalf("Could not find the payment:\n%v", err)
+		}
+		return payment
+	}
+
+	tests := []struct {
+		name      string
+		connector string
+		method    string
+		code      int
+	}{
+		{name: "NoPaymentMethod", connector: "1", code: http.StatusPaymentRequired},
+		{name: "Declined", connector: "1", method: payments.DeclinedPaymentMethod, code: http.StatusPaymentRequired},
+		{name: "Authorized", connector: "1", method: "pm_card_visa", code: http.StatusOK},
+		{name: "AuthorizedSecond", connector: "2", method: "pm_card_visa", code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := request("/reservations/cardChargepoint/"+test.connector, map[string]any{"userId": "cardUser", "minutes": 60, "paymentMethod": test.method})
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+
+	t.Run("PreAuthorized", func(t *testing.T) {
+		if p := payment(t, 1); p.Status != PaymentAuthorized || p.Authorized != 700 {
+			t.Errorf("Expected 700 to be authorized, but received %v", p)
+		}
+	})
+
+	t.Run("CapturedOnSessionEnd", func(t *testing.T) {
+		request("/charge/cardChargepoint/1", map[string]any{"userId": "cardUser"})
+		// Opening a wallet during the session doesn't change how the reservation is paid for
+		collections.Wallets.InsertOne(context.Background(), models.Wallet{UserID: "cardUser", Currency: "EUR", Balance: 5000})
+		request("/stop/cardChargepoint/1", map[string]any{"userId": "cardUser"})
+
+		if p := payment(t, 1); p.Status != PaymentCaptured || p.Captured != 100 {
+			t.Errorf("Expected the session fee of 100 to be captured, but received %v", p)
+		}
+
+		if count, _ := collections.Ledger.CountDocuments(context.Background(), bson.M{"userId": "cardUser"}); count != 0 {
+			t.Errorf("Expected nothing to be taken from the wallet, but found %d ledger entries", count)
+		}
+	})
+
+	t.Run("IdleFeeChargedSeparately", func(t *testing.T) {
+		captured := payment(t, 1)
+
+		var session models.ChargingSession
+		collections.Sessions.FindOne(context.Background(), bson.M{"reservationId": captured.ReservationID}).Decode(&session)
+		var reservation models.Reservation
+		collections.Reservations.FindOne(context.Background(), bson.M{"_id": captured.ReservationID}).Decode(&reservation)
+		// Stopping early doesn't make the rest of the reservation idle time
+		if !session.IdleSince.Equal(reservation.ChargingTime) {
+			t.Errorf("Expected the idle time to start when the reservation ends, but received %v", session)
+		}
+
+		err := applyFee(models.Fee{Type: FeeIdle, UserID: "cardUser", ReservationID: captured.ReservationID, SessionID: session.ID, Quantity: 20, Unit: "minute", UnitPrice: 10, Amount: 200, Currency: "EUR", Time: time.Now()}, collections)
+		if err != nil {
+			t.Fatalf("Could not apply the idle fee:\n%v", err)
+		}
+
+		count, _ := collections.Payments.CountDocuments(context.Background(), bson.M{"reservationId": captured.ReservationID, "fee": true, "status": PaymentCaptured, "captured": 200, "paymentMethod": "pm_card_visa"})
+		if count != 1 {
+			t.Errorf("Expected the idle fee to be captured in a payment of its own, but found %d", count)
+		}
+		if p := payment(t, 1); p.ID != captured.ID {
+			t.Errorf("Expected the reservation's own payment, but received %v", p)
+		}
+	})
+
+	t.Run("OperatorCancelVoidsAuthorization", func(t *testing.T) {
+		p := payment(t, 2)
+		if code := request(fmt.Sprintf("/cancel/%d", p.ReservationID), map[string]any{}).Code; code != http.StatusBadRequest {
+			t.Errorf("Expected code %d without a reason, but received %d", http.StatusBadRequest, code)
+		}
+		if code := request(fmt.Sprintf("/cancel/%d", p.ReservationID), map[string]any{"reason": "Connector broken"}).Code; code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, code)
+		}
+
+		if p := payment(t, 2); p.Status != PaymentVoided {
+			t.Errorf("Expected the authorization to be voided, but received %v", p)
+		}
+	})
+
+	t.Run("Webhook", func(t *testing.T) {
+		captured := payment(t, 1)
+		payload, _ := json.Marshal(payments.Event{ID: "evt_1", Type: payments.EventRefunded, AuthorizationID: captured.AuthorizationID, Amount: 100})
+		signature := paymentGateway().(*payments.FakeGateway).Sign(payload)
+
+		webhook := func(signature string) *httptest.ResponseRecorder {
+			req, _ := http.NewRequest("POST", "/payments/webhook", bytes.NewReader(payload))
+			req.Header.Set("X-Signature", signature)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+			return recorder
+		}
+
+		if code := webhook("forged").Code; code != http.StatusBadRequest {
+			t.Errorf("Expected code %d for a forged signature, but received %d", http.StatusBadRequest, code)
+		}
+
+		if recorder := webhook(signature); recorder.Code != http.StatusOK || !bytes.Contains(recorder.Body.Bytes(), []byte("Event processed")) {
+			t.Errorf("Expected the event to be processed, but received %d %s", recorder.Code, recorder.Body.String())
+		}
+
+		if recorder := webhook(signature); recorder.Code != http.StatusOK || !bytes.Contains(recorder.Body.Bytes(), []byte("already processed")) {
+			t.Errorf("Expected the repeated event to be acknowledged only, but received %d %s", recorder.Code, recorder.Body.String())
+		}
+
+		if p := payment(t, 1); p.Status != PaymentRefunded || p.Refunded != 100 {
+			t.Errorf("Expected the payment to be refunded, but received %v", p)
+		}
+
+		payload, _ = json.Marshal(payments.Event{ID: "evt_0", Type: payments.EventCaptured, AuthorizationID: captured.AuthorizationID, Amount: 500})
+		if recorder := webhook(paymentGateway().(*payments.FakeGateway).Sign(payload)); recorder.Code != http.StatusOK {
+			t.Errorf("Expected the late event to be acknowledged, but received %d %s", recorder.Code, recorder.Body.String())
+		}
+
+		if p := payment(t, 1); p.Status != PaymentRefunded || p.Refunded != 100 {
+			t.Errorf("Expected the late capture to leave the refunded payment alone, but received %v", p)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package endpoints
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"reservations/models"
+	"time"
+)
+
+// Usage is everything about a reservation that affects its price
+type Usage struct {
+	Reserved bool
+	// Charging period, both are zero when the user never charged
+	Start time.Time
+	End   time.Time
+	// Energy delivered in kWh
+	Energy float64
+	// Minutes the vehicle stayed plugged in after charging ended
+	IdleMinutes float64
+}
+
+func defaultCurrency() string {
+	currency := os.Getenv("CURRENCY")
+	if currency == "" {
+		return "EUR"
+	}
+
+	return currency
+}
+
+// parseClock parses a "HH:MM" time of day into minutes since midnight
+func parseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+func inBand(band models.TariffBand, minuteOfDay int) bool {
+	start, err := parseClock(band.Start)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(band.End)
+	if err != nil {
+		return false
+	}
+
+	if start <= end {
+		return minuteOfDay >= start && minuteOfDay < end
+	}
+
+	// The band wraps past midnight
+	return minuteOfDay >= start || minuteOfDay < end
+}
+
+// bandIndex returns the index of the first band covering the time, or -1 when the tariff's base prices apply
+func bandIndex(tariff models.Tariff, t time.Time) int {
+	minuteOfDay := t.Hour()*60 + t.Minute()
+	for i, band := range tariff.Bands {
+		if inBand(band, minuteOfDay) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// splitByBands returns how many minutes of the period fall into the tariff's base prices (the last element) and each of its bands
+func splitByBands(tariff models.Tariff, start, end time.Time) []float64 {
+	minutes := make([]float64, len(tariff.Bands)+1)
+
+	for t := start; t.Before(end); t = t.Add(time.Minute) {
+		step := time.Minute
+		if end.Sub(t) < step {
+			step = end.Sub(t)
+		}
+
+		i := bandIndex(tariff, t)
+		if i == -1 {
+			i = len(tariff.Bands)
+		}
+		minutes[i] += step.Minutes()
+	}
+
+	return minutes
+}
+
+func roundCents(amount float64) int64 {
+	return int64(math.Round(amount))
+}
+
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*100) / 100
+}
+
+// ComputePrice prices the usage under the tariff. Energy is assumed to be delivered evenly over the charging period when splitting it between time-of-day bands.
+func ComputePrice(tariff models.Tariff, usage Usage) models.Price {
+	price := models.Price{Currency: tariff.Currency, Components: []models.PriceComponent{}}
+	if price.Currency == "" {
+		price.Currency = defaultCurrency()
+	}
+
+	add := func(component models.PriceComponent) {
+		price.Components = append(price.Components, component)
+		price.Total += component.Amount
+	}
+
+	if usage.Reserved && tariff.ReservationFee > 0 {
+		add(models.PriceComponent{Type: "Reservation", Description: "Reservation fee", Quantity: 1, Unit: "reservation", UnitPrice: tariff.ReservationFee, Amount: tariff.ReservationFee})
+	}
+
+	if !usage.Start.IsZero() && usage.End.After(usage.Start) {
+		if tariff.SessionFee > 0 {
+			add(models.PriceComponent{Type: "Session", Description: "Session fee", Quantity: 1, Unit: "session", UnitPrice: tariff.SessionFee, Amount: tariff.SessionFee})
+		}
+
+		minutes := splitByBands(tariff, usage.Start, usage.End)
+		total := usage.End.Sub(usage.Start).Minutes()
+
+		for i, bandMinutes := range minutes {
+			if bandMinutes == 0 {
+				continue
+			}
+
+			name, perMinute, perKWh := "Standard", tariff.PerMinute, tariff.PerKWh
+			if i < len(tariff.Bands) {
+				band := tariff.Bands[i]
+				name = fmt.Sprintf("%s %s-%s", band.Name, band.Start, band.End)
+				perMinute, perKWh = band.PerMinute, band.PerKWh
+			}
+
+			if perMinute > 0 {
+				add(models.PriceComponent{Type: "Time", Description: "Charging time (" + name + ")", Quantity: roundQuantity(bandMinutes), Unit: "minute", UnitPrice: perMinute, Amount: roundCents(bandMinutes * float64(perMinute))})
+			}
+
+			energy := usage.Energy * bandMinutes / total
+			if perKWh > 0 && energy > 0 {
+				add(models.PriceComponent{Type: "Energy", Description: "Energy (" + name + ")", Quantity: roundQuantity(energy), Unit: "kWh", UnitPrice: perKWh, Amount: roundCents(energy * float64(perKWh))})
+			}
+		}
+	}
+
+	if usage.IdleMinutes > 0 && tariff.IdleFee > 0 {
+		add(models.PriceComponent{Type: "Idle", Description: "Idle fee after charging ended", Quantity: roundQuantity(usage.IdleMinutes), Unit: "minute", UnitPrice: tariff.IdleFee, Amount: roundCents(usage.IdleMinutes * float64(tariff.IdleFee))})
+	}
+
+	return price
+}
+
+// peakAdjustments returns an adjustment for every peak window the charging period overlaps, applying to the share of the period inside it
+func peakAdjustments(policy models.PricingPolicy, start, end time.Time) []models.PriceAdjustment {
+	adjustments := []models.PriceAdjustment{}
+
+	total := end.Sub(start).Minutes()
+	if total <= 0 {
+		return adjustments
+	}
+
+	for _, window := range policy.PeakWindows {
+		minutes := 0.0
+		for t := start; t.Before(end); t = t.Add(time.Minute) {
+			step := time.Minute
+			if end.Sub(t) < step {
+				step = end.Sub(t)
+			}
+
+			if inBand(models.TariffBand{Start: window.Start, End: window.End}, t.Hour()*60+t.Minute()) {
+				minutes += step.Minutes()
+			}
+		}
+
+		if minutes == 0 || window.Percent == 0 {
+			continue
+		}
+
+		adjustments = append(adjustments, models.PriceAdjustment{
+			Type:        "Peak",
+			Description: fmt.Sprintf("%s %s-%s, %.0f of %.0f minutes", window.Name, window.Start, window.End, minutes, total),
+			Percent:     window.Percent,
+			Share:       roundQuantity(minutes / total),
+		})
+	}
+
+	return adjustments
+}
+
+// utilizationAdjustment returns the adjustment of the highest tier reached by the site's current or forecast utilization (in percent), whichever is higher, or nil when no tier applies
+func utilizationAdjustment(policy models.PricingPolicy, current, forecast float64) *models.PriceAdjustment {
+	utilization := math.Max(current, forecast)
+
+	var tier *models.UtilizationTier
+	for i := range policy.UtilizationTiers {
+		candidate := &policy.UtilizationTiers[i]
+		if float64(candidate.MinUtilization) <= utilization && (tier == nil || candidate.MinUtilization > tier.MinUtilization) {
+			tier = candidate
+		}
+	}
+
+	if tier == nil || tier.Percent == 0 {
+		return nil
+	}
+
+	return &models.PriceAdjustment{
+		Type:        "Utilization",
+		Description: fmt.Sprintf("Site utilization, %.0f%% now and %.0f%% forecast", current, forecast),
+		Percent:     tier.Percent,
+		Share:       1,
+	}
+}
+
+// ApplyAdjustments adds a component for every adjustment to the price. Adjustments apply to the charging time and energy, discounts never take them below zero.
+func ApplyAdjustments(price models.Price, adjustments []models.PriceAdjustment) models.Price {
+	var base int64
+	for _, component := range price.Components {
+		if component.Type == "Time" || component.Type == "Energy" {
+			base += component.Amount
+		}
+	}
+
+	if base <= 0 {
+		return price
+	}
+
+	charging := base
+	for _, adjustment := range adjustments {
+		amount := roundCents(float64(base) * float64(adjustment.Percent) / 100 * adjustment.Share)
+		if charging+amount < 0 {
+			amount = -charging
+		}
+		charging += amount
+
+		price.Components = append(price.Components, models.PriceComponent{
+			Type:        adjustment.Type,
+			Description: fmt.Sprintf("%s (%+d%%)", adjustment.Description, adjustment.Percent),
+			Quantity:    roundQuantity(float64(adjustment.Percent) * adjustment.Share),
+			Unit:        "%",
+			UnitPrice:   base,
+			Amount:      amount,
+		})
+		price.Total += amount
+	}
+
+	return price
+}
@@ -0,0 +1,132 @@
+package endpoints
+
+import (
+	"reservations/models"
+	"testing"
+	"time"
+)
+
+func TestComputePrice(t *testing.T) {
+	tariff := models.Tariff{
+		Currency:       "EUR",
+		PerMinute:      10,
+		PerKWh:         30,
+		SessionFee:     100,
+		ReservationFee: 50,
+		IdleFee:        20,
+		Bands: []models.TariffBand{
+			{Name: "Night", Start: "22:00", End: "06:00", PerMinute: 5, PerKWh: 20},
+		},
+	}
+
+	day := time.Date(2023, 6, 1, 12, 0, 0, 0, time.Local)
+	evening := time.Date(2023, 6, 1, 21, 30, 0, 0, time.Local)
+
+	tests := []struct {
+		name       string
+		usage      Usage
+		total      int64
+		components int
+	}{
+		{name: "NoShow", usage: Usage{Reserved: true}, total: 50, components: 1},
+		// 50 + 100 + 60 minutes * 10 + 10 kWh * 30
+		{name: "Daytime", usage: Usage{Reserved: true, Start: day, End: day.Add(time.Hour), Energy: 10}, total: 1050, components: 4},
+		// 100 + 30 minutes * 10 + 5 kWh * 30 + 30 minutes * 5 + 5 kWh * 20
+		{name: "AcrossBands", usage: Usage{Start: evening, End: evening.Add(time.Hour), Energy: 10}, total: 800, components: 5},
+		// 100 + 60 minutes * 10 + 15 idle minutes * 20
+		{name: "Idle", usage: Usage{Start: day, End: day.Add(time.Hour), IdleMinutes: 15}, total: 1000, components: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			price := ComputePrice(tariff, test.usage)
+
+			if price.Total != test.total {
+				t.Errorf("Expected a total of %d, but received %d", test.total, price.Total)
+			}
+
+			if len(price.Components) != test.components {
+				t.Errorf("Expected %d price components, but received %d", test.components, len(price.Components))
+			}
+
+			var sum int64
+			for _, component := range price.Components {
+				sum += component.Amount
+			}
+
+			if sum != price.Total {
+				t.Errorf("Expected the components to add up to the total %d, but they add up to %d", price.Total, sum)
+			}
+		})
+	}
+
+	t.Run("FreeConnector", func(t *testing.T) {
+		price := ComputePrice(models.Tariff{}, Usage{Reserved: true, Start: day, End: day.Add(time.Hour), Energy: 10})
+		if price.Total != 0 || len(price.Components) != 0 {
+			t.Errorf("Expected a free connector to cost nothing, but received %d", price.Total)
+		}
+	})
+}
+
+func TestApplyAdjustments(t *testing.T) {
+	tariff := models.Tariff{Currency: "EUR", PerMinute: 10, ReservationFee: 50}
+	policy := models.PricingPolicy{
+		PeakWindows:      []models.PeakWindow{{Name: "Evening", Start: "17:00", End: "21:00", Percent: 20}},
+		UtilizationTiers: []models.UtilizationTier{{MinUtilization: 0, Percent: -10}, {MinUtilization: 50, Percent: 0}, {MinUtilization: 80, Percent: 25}},
+	}
+
+	// Half of the reservation falls into the evening peak
+	start := time.Date(2023, 6, 1, 16, 30, 0, 0, time.Local)
+	end := start.Add(time.Hour)
+
+	tests := []struct {
+		name        string
+		current     float64
+		forecast    float64
+		total       int64
+		adjustments int
+	}{
+		// 50 + 600 + 10% of 600 in the peak
+		{name: "Busy", current: 50, forecast: 50, total: 710, adjustments: 1},
+		// 50 + 600 + 60 - 10% of 600
+		{name: "Quiet", current: 20, forecast: 10, total: 650, adjustments: 2},
+		// 50 + 600 + 60 + 25% of 600, the forecast counts when it's higher
+		{name: "Full", current: 20, forecast: 100, total: 860, adjustments: 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			adjustments := peakAdjustments(policy, start, end)
+			if adjustment := utilizationAdjustment(policy, test.current, test.forecast); adjustment != nil {
+				adjustments = append(adjustments, *adjustment)
+			}
+
+			if len(adjustments) != test.adjustments {
+				t.Fatalf("Expected %d adjustments, but received %v", test.adjustments, adjustments)
+			}
+
+			price := ApplyAdjustments(ComputePrice(tariff, Usage{Reserved: true, Start: start, End: end}), adjustments)
+			if price.Total != test.total {
+				t.Errorf("Expected a total of %d, but received %d", test.total, price.Total)
+			}
+
+			var sum int64
+			for _, component := range price.Components {
+				sum += component.Amount
+			}
+
+			if sum != price.Total {
+				t.Errorf("Expected the components to add up to the total %d, but they add up to %d", price.Total, sum)
+			}
+		})
+	}
+
+	t.Run("DiscountFloor", func(t *testing.T) {
+		adjustments := []models.PriceAdjustment{{Type: "Peak", Percent: -90, Share: 1}, {Type: "Utilization", Percent: -50, Share: 1}}
+
+		price := ApplyAdjustments(ComputePrice(tariff, Usage{Reserved: true, Start: start, End: end}), adjustments)
+		if price.Total != 50 {
+			t.Errorf("Expected discounts to stop at the reservation fee of 50, but received %d", price.Total)
+		}
+	})
+}
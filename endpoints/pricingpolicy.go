@@ -0,0 +1,238 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// quoteValidMinutes is how long a quoted price is honoured
+func quoteValidMinutes() int {
+	return envInt("QUOTE_VALID_MINUTES", 15)
+}
+
+// SetPricingPolicy godoc
+// @Summary Set a site's pricing policy
+// @Description Adjusts the price of reservations at the site on top of its connectors' tariffs. Peak windows are "HH:MM" times in the server's time zone (a window can wrap past midnight) and apply to the share of the reservation inside them. Utilization tiers apply from the given percentage of the site's connectors in use, now or as forecast by the reservations overlapping the reservation, whichever is higher. Percentages are surcharges, negative ones are discounts. Adjustments are locked in when a reservation is quoted or made. An empty policy removes all adjustments.
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param body body models.PricingPolicy true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /sites/{id}/pricing [post]
+func SetPricingPolicy(c *gin.Context, collections Collections) {
+	var req models.PricingPolicy
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	for _, window := range req.PeakWindows {
+		start, err := parseClock(window.Start)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Peak window times must be in the HH:MM format"})
+			return
+		}
+
+		end, err := parseClock(window.End)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Peak window times must be in the HH:MM format"})
+			return
+		}
+
+		if start == end {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "A peak window's start and end must differ"})
+			return
+		}
+
+		if window.Percent <= -100 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Discounts must be less than 100 percent"})
+			return
+		}
+	}
+
+	for _, tier := range req.UtilizationTiers {
+		if tier.MinUtilization < 0 || tier.MinUtilization > 100 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Utilization tiers must start between 0 and 100 percent"})
+			return
+		}
+
+		if tier.Percent <= -100 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Discounts must be less than 100 percent"})
+			return
+		}
+	}
+
+	if req.PeakWindows == nil {
+		req.PeakWindows = []models.PeakWindow{}
+	}
+	if req.UtilizationTiers == nil {
+		req.UtilizationTiers = []models.UtilizationTier{}
+	}
+
+	result, err := collections.Sites.UpdateOne(context.Background(), bson.M{"_id": c.Param("id")}, bson.M{"$set": bson.M{"pricingPolicy": req}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the site"})
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Site not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Pricing policy updated"})
+}
+
+// siteUtilization returns the percentage of the site's connectors in use now, and the percentage reserved at some point during the period. Unavailable connectors aren't counted.
+func siteUtilization(siteID string, start, end time.Time, collections Collections) (float64, float64, error) {
+	cursor, err := collections.Chargepoints.Find(context.Background(), bson.M{"siteId": siteID})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var chargepoints []models.Chargepoint
+	if err := cursor.All(context.Background(), &chargepoints); err != nil {
+		return 0, 0, err
+	}
+
+	type connectorKey struct {
+		chargepoint string
+		connector   int
+	}
+
+	counted := map[connectorKey]bool{}
+	chargepointIDs := bson.A{}
+	busy := 0
+	for _, chargepoint := range chargepoints {
+		chargepointIDs = append(chargepointIDs, chargepoint.ID)
+		for _, connector := range chargepoint.Connectors {
+			if connector.State == "Unavailable" {
+				continue
+			}
+
+			counted[connectorKey{chargepoint.ID, connector.ID}] = true
+			if connector.State != "Available" {
+				busy++
+			}
+		}
+	}
+
+	if len(counted) == 0 {
+		return 0, 0, nil
+	}
+
+	cursor, err = collections.Reservations.Find(context.Background(), bson.M{
+		"chargepoint":         bson.M{"$in": chargepointIDs},
+		"hasFinishedCharging": false,
+		"startTime":           bson.M{"$lt": end},
+		"chargingTime":        bson.M{"$gt": start},
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var reservations []models.Reservation
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		return 0, 0, err
+	}
+
+	reserved := map[connectorKey]bool{}
+	for _, reservation := range reservations {
+		key := connectorKey{reservation.Chargepoint, reservation.Connector}
+		if counted[key] {
+			reserved[key] = true
+		}
+	}
+
+	total := float64(len(counted))
+	return roundQuantity(float64(busy) / total * 100), roundQuantity(float64(len(reserved)) / total * 100), nil
+}
+
+// dynamicAdjustments prices a reservation of the chargepoint during the period under its site's pricing policy, if it has one
+func dynamicAdjustments(chargepoint models.Chargepoint, start, end time.Time, collections Collections) ([]models.PriceAdjustment, error) {
+	if chargepoint.SiteID == "" {
+		return nil, nil
+	}
+
+	site, err := FindSiteByID(chargepoint.SiteID, collections.Sites)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if site.PricingPolicy == nil {
+		return nil, nil
+	}
+
+	adjustments := peakAdjustments(*site.PricingPolicy, start, end)
+
+	if len(site.PricingPolicy.UtilizationTiers) > 0 {
+		current, forecast, err := siteUtilization(site.ID, start, end, collections)
+		if err != nil {
+			return nil, err
+		}
+
+		if adjustment := utilizationAdjustment(*site.PricingPolicy, current, forecast); adjustment != nil {
+			adjustments = append(adjustments, *adjustment)
+		}
+	}
+
+	return adjustments, nil
+}
+
+// findValidQuote returns the user's quote when it's unused and still valid for a reservation of the connector for the given minutes, or nil otherwise. The start time is zero for reservations starting right away. It is compared at the milliseconds MongoDB keeps.
+func findValidQuote(id, userID, chargepointID string, connector, minutes int, startTime time.Time, collection *mongo.Collection) (*models.Quote, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, nil
+	}
+
+	var quote models.Quote
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&quote)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if quote.UserID != userID || !quote.ReservationID.IsZero() || quote.ExpiresAt.Before(time.Now()) || quote.Chargepoint != chargepointID || quote.Connector != connector || quote.Minutes != minutes || !quote.StartTime.Equal(startTime.Truncate(time.Millisecond)) {
+		return nil, nil
+	}
+
+	return &quote, nil
+}
+
+// useQuote marks the quote as used by the reservation, unless another reservation was made with it in the meantime
+func useQuote(quote *models.Quote, reservationID primitive.ObjectID, collection *mongo.Collection) (bool, error) {
+	result, err := collection.UpdateOne(context.Background(), bson.M{"_id": quote.ID, "reservationId": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"reservationId": reservationID}})
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// releaseQuote makes the quote usable again when the reservation using it couldn't be made
+func releaseQuote(quote *models.Quote, reservationID primitive.ObjectID, collection *mongo.Collection) error {
+	if quote == nil {
+		return nil
+	}
+
+	_, err := collection.UpdateOne(context.Background(), bson.M{"_id": quote.ID, "reservationId": reservationID}, bson.M{"$unset": bson.M{"reservationId": ""}})
+	return err
+}
@@ -0,0 +1,160 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestPricingPolicy(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/sites/:id/pricing", func(c *gin.Context) {
+		SetPricingPolicy(c, collections)
+	})
+
+	router.POST("/quotes/:cpID/:coID", func(c *gin.Context) {
+		QuotePrice(c, collections)
+	})
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Sites, collections.Tariffs, collections.Chargepoints, collections.Reservations, collections.Quotes} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "dynamicUser", Name: "Off-peak user"})
+	collections.Users.InsertOne(context.Background(), models.User{ID: "dynamicOtherUser", Name: "Other user"})
+	collections.Sites.InsertOne(context.Background(), models.Site{ID: "dynamicSite", Name: "Busy site", TariffID: "dynamicTariff"})
+	collections.Tariffs.InsertOne(context.Background(), models.Tariff{ID: "dynamicTariff", Name: "Per minute", Currency: "EUR", PerMinute: 10})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "dynamicChargepoint", SiteID: "dynamicSite", Connectors: []models.Connector{
+		{ID: 1, State: "Charging"},
+		{ID: 2, State: "Available"},
+	}})
+
+	request := func(endpoint string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		body     any
+		code     int
+	}{
+		{name: "InvalidWindow", endpoint: "/sites/dynamicSite/pricing", body: map[string]any{"peakWindows": []map[string]any{{"name": "Evening", "start": "17", "end": "21:00", "percent": 20}}}, code: http.StatusBadRequest},
+		{name: "InvalidDiscount", endpoint: "/sites/dynamicSite/pricing", body: map[string]any{"utilizationTiers": []map[string]any{{"minUtilization": 0, "percent": -100}}}, code: http.StatusBadRequest},
+		{name: "MissingSite", endpoint: "/sites/missing/pricing", body: map[string]any{}, code: http.StatusNotFound},
+		{name: "SetPolicy", endpoint: "/sites/dynamicSite/pricing", body: map[string]any{"utilizationTiers": []map[string]any{{"minUtilization": 50, "percent": 20}}}, code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := request(test.endpoint, test.body)
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			}
+		})
+	}
+
+	var quote models.Quote
+
+	t.Run("QuoteWithUtilization", func(t *testing.T) {
+		recorder := request("/quotes/dynamicChargepoint/2", map[string]any{"userId": "dynamicUser", "minutes": 60})
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+		json.Unmarshal(recorder.Body.Bytes(), &quote)
+
+		// Half of the site's connectors are in use: 60 minutes * 10 + 20%
+		if quote.Total != 720 || len(quote.Adjustments) != 1 || quote.Adjustments[0].Type != "Utilization" {
+			t.Errorf("Expected a total of 720 with a utilization adjustment, but received %d with %v", quote.Total, quote.Adjustments)
+		}
+	})
+
+	// The quote stays locked in even though the policy changes
+	request("/sites/dynamicSite/pricing", map[string]any{})
+
+	t.Run("ReserveWithExpiredQuote", func(t *testing.T) {
+		recorder := request("/reservations/dynamicChargepoint/2", map[string]any{"userId": "dynamicUser", "minutes": 60, "quoteId": "000000000000000000000000"})
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, but received %d", http.StatusBadRequest, recorder.Code)
+		}
+	})
+
+	t.Run("ReserveLaterWithQuote", func(t *testing.T) {
+		recorder := request("/reservations/dynamicChargepoint/2", map[string]any{"userId": "dynamicUser", "minutes": 60, "quoteId": quote.ID.Hex(), "startTime": time.Now().Add(24 * time.Hour)})
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected the quote for now to be refused for tomorrow with code %d, but received %d", http.StatusBadRequest, recorder.Code)
+		}
+	})
+
+	t.Run("ReserveWithSomeoneElsesQuote", func(t *testing.T) {
+		recorder := request("/reservations/dynamicChargepoint/2", map[string]any{"userId": "dynamicOtherUser", "minutes": 60, "quoteId": quote.ID.Hex()})
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected another user's quote to be refused with code %d, but received %d", http.StatusBadRequest, recorder.Code)
+		}
+	})
+
+	t.Run("ReserveWithQuote", func(t *testing.T) {
+		recorder := request("/reservations/dynamicChargepoint/2", map[string]any{"userId": "dynamicUser", "minutes": 60, "quoteId": quote.ID.Hex()})
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+
+		var reservation models.Reservation
+		err := collections.Reservations.FindOne(context.Background(), bson.M{"userId": "dynamicUser"}).Decode(&reservation)
+		if err != nil {
+			t.Fatalf("Could not find the reservation:\n%v", err)
+		}
+
+		if len(reservation.Adjustments) != 1 || reservation.Adjustments[0].Percent != 20 {
+			t.Errorf("Expected the quote's 20%% adjustment to be locked in, but received %v", reservation.Adjustments)
+		}
+
+		var used models.Quote
+		collections.Quotes.FindOne(context.Background(), bson.M{"_id": quote.ID}).Decode(&used)
+		if used.ReservationID != reservation.ID {
+			t.Errorf("Expected the quote to be used up by the reservation, but received %+v", used)
+		}
+
+		again, err := findValidQuote(quote.ID.Hex(), "dynamicUser", "dynamicChargepoint", 2, 60, time.Time{}, collections.Quotes)
+		if err != nil || again != nil {
+			t.Errorf("Expected the used quote to be refused, but received %+v %v", again, err)
+		}
+	})
+}
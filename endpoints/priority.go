@@ -0,0 +1,479 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/db"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CreatePriorityClass godoc
+// @Summary Create a new priority class
+// @Description Priority classes rank users, for example emergency services above fleets. Members of a class that can bump may take the place of reservations of users with a lower rank that haven't started charging, as long as they start at least the bump notice later. A reservation that already holds its connector can be taken over once its user hasn't plugged in for the class' take-over minutes after it started. The bumped users are refunded, notified and owed the class' compensation (in cents). Connectors can also be held for a class, see POST /chargepoints/{id}/holds.
+// @Tags Operators
+// @Accept json
+// @Produce json
+// @Param id path string true "Priority class ID"
+// @Param body body CreatePriorityClassRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /priorityclasses/{id} [post]
+func CreatePriorityClass(c *gin.Context, collection *mongo.Collection) {
+	var req CreatePriorityClassRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Name must be a non-empty string"})
+		return
+	}
+
+	if req.Rank <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The rank must be above 0, the rank of users without a class"})
+		return
+	}
+
+	if req.BumpNoticeMinutes < 0 || req.TakeOverMinutes < 0 || req.Compensation < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The bump notice, take-over minutes and compensation can't be negative"})
+		return
+	}
+
+	newClass := models.PriorityClass{
+		ID:                c.Param("id"),
+		Name:              req.Name,
+		Rank:              req.Rank,
+		CanBump:           req.CanBump,
+		BumpNoticeMinutes: req.BumpNoticeMinutes,
+		TakeOverMinutes:   req.TakeOverMinutes,
+		Compensation:      req.Compensation,
+		Currency:          req.Currency,
+	}
+	if newClass.Currency == "" {
+		newClass.Currency = defaultCurrency()
+	}
+
+	_, err := collection.InsertOne(context.Background(), newClass)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create a new priority class, perhaps an existing ID was entered"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Priority class created"})
+}
+
+type CreatePriorityClassRequest struct {
+	Name              string `json:"name"`
+	Rank              int    `json:"rank"`
+	CanBump           bool   `json:"canBump"`
+	BumpNoticeMinutes int    `json:"bumpNoticeMinutes"`
+	TakeOverMinutes   int    `json:"takeOverMinutes"`
+	Compensation      int64  `json:"compensation"`
+	Currency          string `json:"currency"`
+}
+
+// FindPriorityClassByID godoc
+// @Summary Get information about a priority class by ID
+// @Tags Operators
+// @Produce json
+// @Param id path string true "Priority class ID"
+// @Success 200 {object} models.PriorityClass
+// @Failure 404 {object} models.ErrorResponse
+// @Router /priorityclasses/{id} [get]
+func FindPriorityClassByID(id string, collection *mongo.Collection) (models.PriorityClass, error) {
+	var class models.PriorityClass
+
+	err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&class)
+	if err != nil {
+		return models.PriorityClass{}, err
+	}
+
+	return class, nil
+}
+
+// GetAllPriorityClasses godoc
+// @Summary Get all priority classes
+// @Tags Operators
+// @Produce json
+// @Success 200 {object} []models.PriorityClass
+// @Failure 500 {object} models.ErrorResponse
+// @Router /priorityclasses [get]
+func GetAllPriorityClasses(collection *mongo.Collection) ([]bson.M, error) {
+	documents, err := db.GetAllDocumentsInCollection(collection)
+	if err != nil {
+		return []bson.M{}, err
+	}
+
+	return documents, nil
+}
+
+// AssignPriorityClass godoc
+// @Summary Put a user or organization in a priority class
+// @Description Give either a user ID or an organization ID. A user's own class takes precedence over their organization's. Reservations keep the class they were made with.
+// @Tags Operators
+// @Accept json
+// @Produce json
+// @Param id path string true "Priority class ID"
+// @Param body body AssignPriorityClassRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /priorityclasses/{id}/assign [post]
+func AssignPriorityClass(c *gin.Context, collections Collections) {
+	var req AssignPriorityClassRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	class, err := FindPriorityClassByID(c.Param("id"), collections.PriorityClasses)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Priority class not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch priority classes"})
+		return
+	}
+
+	setPriorityClass(c, req.UserID, req.OrganizationID, bson.M{"$set": bson.M{"priorityClass": class.ID}}, "Priority class assigned", collections)
+}
+
+type AssignPriorityClassRequest struct {
+	UserID         string `json:"userId"`
+	OrganizationID string `json:"organizationId"`
+}
+
+// UnassignPriorityClass godoc
+// @Summary Take a user or organization out of a priority class
+// @Description Give either a user ID or an organization ID. Nothing changes when they're in another class.
+// @Tags Operators
+// @Produce json
+// @Param id path string true "Priority class ID"
+// @Param userId query string false "User ID"
+// @Param organizationId query string false "Organization ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /priorityclasses/{id}/assign [delete]
+func UnassignPriorityClass(c *gin.Context, collections Collections) {
+	setPriorityClass(c, c.Query("userId"), c.Query("organizationId"), bson.M{"$unset": bson.M{"priorityClass": ""}}, "Priority class unassigned", collections)
+}
+
+// setPriorityClass applies the update to the user or the organization, whichever is given
+func setPriorityClass(c *gin.Context, userID, organizationID string, update bson.M, message string, collections Collections) {
+	if (userID == "") == (organizationID == "") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Either a user or an organization must be given"})
+		return
+	}
+
+	collection, id, name := collections.Users, userID, "User"
+	if organizationID != "" {
+		collection, id, name = collections.Organizations, organizationID, "Organization"
+	}
+
+	// Unassigning only removes this class
+	filter := bson.M{"_id": id}
+	if _, unset := update["$unset"]; unset {
+		filter["priorityClass"] = c.Param("id")
+	}
+
+	result, err := collection.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the priority class"})
+		return
+	}
+
+	if result.MatchedCount == 0 {
+		count, err := collection.CountDocuments(context.Background(), bson.M{"_id": id})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the priority class"})
+			return
+		}
+		if count == 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("%s does not exist", name)})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: message})
+}
+
+// SetConnectorHold godoc
+// @Summary Hold a connector for a priority class
+// @Description Only users of the priority class, or of a class with a higher rank, can reserve the connector for slots starting more than the release minutes later. Closer to the slot, anyone can reserve it. Without a priority class, the hold is removed.
+// @Tags Operators
+// @Accept json
+// @Produce json
+// @Param id path string true "Chargepoint ID"
+// @Param body body ConnectorHoldRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /chargepoints/{id}/holds [post]
+func SetConnectorHold(c *gin.Context, collections Collections) {
+	var req ConnectorHoldRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(c.Param("id"), collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Chargepoint not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	if req.ConnectorID <= 0 || req.ConnectorID > len(chargepoint.Connectors) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors"})
+		return
+	}
+
+	if req.ReleaseMinutes < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The release minutes can't be negative"})
+		return
+	}
+
+	var hold *models.ConnectorHold
+	if req.PriorityClass != "" {
+		_, err := FindPriorityClassByID(req.PriorityClass, collections.PriorityClasses)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Priority class does not exist"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch priority classes"})
+			return
+		}
+
+		hold = &models.ConnectorHold{PriorityClass: req.PriorityClass, ReleaseMinutes: req.ReleaseMinutes}
+	}
+
+	chargepoint.Connectors[req.ConnectorID-1].Hold = hold
+
+	_, err = collections.Chargepoints.UpdateOne(context.Background(), bson.M{"_id": chargepoint.ID}, bson.M{"$set": bson.M{"connectors": chargepoint.Connectors}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the connector"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Connector hold set"})
+}
+
+type ConnectorHoldRequest struct {
+	ConnectorID int `json:"connectorId"`
+	// Optional, the hold is removed without it
+	PriorityClass string `json:"priorityClass"`
+	// For example 10 to release the connector 10 minutes before each slot
+	ReleaseMinutes int `json:"releaseMinutes"`
+}
+
+// GetCompensations godoc
+// @Summary Get compensations
+// @Description Lists what is owed to users whose reservations were bumped by priority users, newest first. The user filter is optional.
+// @Tags Operators
+// @Produce json
+// @Param userId query string false "User ID"
+// @Success 200 {object} []models.Compensation
+// @Failure 500 {object} models.ErrorResponse
+// @Router /compensations [get]
+func GetCompensations(userID string, collection *mongo.Collection) ([]models.Compensation, error) {
+	filter := bson.M{}
+	if userID != "" {
+		filter["userId"] = userID
+	}
+
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.M{"createdAt": -1}))
+	if err != nil {
+		return []models.Compensation{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	compensations := []models.Compensation{}
+	if err := cursor.All(context.Background(), &compensations); err != nil {
+		return []models.Compensation{}, err
+	}
+
+	return compensations, nil
+}
+
+// priorityClassOf returns the user's own priority class, or else their organization's. Users without one get an empty class with rank 0.
+func priorityClassOf(user models.User, collections Collections) (models.PriorityClass, error) {
+	classID := user.PriorityClass
+	if classID == "" && user.OrganizationID != "" {
+		organization, err := FindOrganizationByID(user.OrganizationID, collections.Organizations)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return models.PriorityClass{}, err
+		}
+		classID = organization.PriorityClass
+	}
+
+	return findPriorityClassOrNone(classID, collections.PriorityClasses)
+}
+
+// findPriorityClassOrNone returns the priority class, or an empty class with rank 0 when it doesn't exist
+func findPriorityClassOrNone(id string, collection *mongo.Collection) (models.PriorityClass, error) {
+	if id == "" {
+		return models.PriorityClass{}, nil
+	}
+
+	class, err := FindPriorityClassByID(id, collection)
+	if err == mongo.ErrNoDocuments {
+		return models.PriorityClass{}, nil
+	}
+	return class, err
+}
+
+// checkHold returns why the user's class can't reserve the connector from the start yet, or nil when it can
+func checkHold(connector models.Connector, class models.PriorityClass, start, now time.Time, collections Collections) *reservationError {
+	hold := connector.Hold
+	if hold == nil || start.Before(now.Add(time.Duration(hold.ReleaseMinutes)*time.Minute)) {
+		return nil
+	}
+
+	held, err := FindPriorityClassByID(hold.PriorityClass, collections.PriorityClasses)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch priority classes"}}
+	}
+
+	if class.ID != "" && (class.ID == held.ID || class.Rank >= held.Rank) {
+		return nil
+	}
+
+	return &reservationError{Status: http.StatusForbidden, Body: models.ErrorResponse{Error: fmt.Sprintf("The connector is held for %s until %d minutes before the reservation starts", held.Name, hold.ReleaseMinutes)}, Connector: true}
+}
+
+// bumpableReservations returns the reservations overlapping the time that the class can bump, or the first one it can't. Reservations that haven't started can be bumped with the class' notice, and those holding their connector once their user is the class' take-over minutes late.
+func bumpableReservations(class models.PriorityClass, chargepointID string, connector int, start, end, now time.Time, collections Collections) ([]models.Reservation, *models.Reservation, error) {
+	cursor, err := collections.Reservations.Find(context.Background(), bson.M{
+		"chargepoint":         chargepointID,
+		"connector":           connector,
+		"hasFinishedCharging": false,
+		"startTime":           bson.M{"$lt": end},
+		"chargingTime":        bson.M{"$gt": start},
+	}, options.Find().SetSort(bson.M{"startTime": 1}))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var reservations []models.Reservation
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		return nil, nil, err
+	}
+
+	for i, reservation := range reservations {
+		bumpable := !reservation.StartTime.Before(now.Add(time.Duration(class.BumpNoticeMinutes) * time.Minute))
+		if !reservation.StartTime.After(now) {
+			bumpable = class.TakeOverMinutes > 0 && !now.Before(reservation.StartTime.Add(time.Duration(class.TakeOverMinutes)*time.Minute))
+		}
+
+		if !class.CanBump || reservation.HasStartedCharging || reservation.WalkIn || !bumpable {
+			return nil, &reservations[i], nil
+		}
+
+		bumpedClass, err := findPriorityClassOrNone(reservation.PriorityClass, collections.PriorityClasses)
+		if err != nil {
+			return nil, nil, err
+		}
+		if bumpedClass.Rank >= class.Rank {
+			return nil, &reservations[i], nil
+		}
+	}
+
+	return reservations, nil, nil
+}
+
+// cancelBumped cancels the reservations for the priority reservation, before it's made, so they can't start charging in the meantime. When one of them started charging or was cancelled already, the others are restored and false is returned.
+func cancelBumped(reservations []models.Reservation, by models.Reservation, collections Collections) (bool, error) {
+	for i, reservation := range reservations {
+		result, err := collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": reservation.ID, "hasStartedCharging": false, "hasFinishedCharging": false}, bson.M{"$set": bson.M{
+			"hasFinishedCharging": true,
+			"cancelled":           true,
+			"cancelReason":        "Bumped by a priority reservation",
+			"bumpedBy":            by.ID,
+		}})
+		if err != nil || result.ModifiedCount == 0 {
+			if err := restoreBumped(reservations[:i], by, collections); err != nil {
+				fmt.Println("Error restoring bumped reservations: ", err)
+			}
+			return false, err
+		}
+	}
+
+	return true, nil
+}
+
+// restoreBumped undoes cancelBumped, when the priority reservation couldn't be made after all
+func restoreBumped(reservations []models.Reservation, by models.Reservation, collections Collections) error {
+	for _, reservation := range reservations {
+		_, err := collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": reservation.ID, "bumpedBy": by.ID}, bson.M{
+			"$set":   bson.M{"hasFinishedCharging": false, "cancelled": false},
+			"$unset": bson.M{"cancelReason": "", "bumpedBy": ""},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// bumpReservation refunds a reservation cancelled by cancelBumped once the priority reservation is made, and records the compensation for its user and notifies them. The connector of a reservation that already holds it is freed, unless the priority reservation takes it over.
+func bumpReservation(reservation models.Reservation, by models.Reservation, class models.PriorityClass, takeOver bool, collections Collections) error {
+	for _, refund := range []func(primitive.ObjectID, Collections) error{releaseHold, voidPayment, refundWallet, refundPayment} {
+		if err := refund(reservation.ID, collections); err != nil {
+			return err
+		}
+	}
+
+	if !takeOver && !reservation.StartTime.After(time.Now()) {
+		if _, err := setConnectorState(reservation.Chargepoint, reservation.Connector, "Reserved", "Available", collections.Chargepoints); err != nil {
+			return err
+		}
+		if err := offerConnectors(reservation.Chargepoint, collections); err != nil {
+			return err
+		}
+	}
+
+	_, err := collections.Compensations.InsertOne(context.Background(), models.Compensation{
+		UserID:        reservation.UserID,
+		ReservationID: reservation.ID,
+		BumpedBy:      by.ID,
+		PriorityClass: class.ID,
+		Amount:        class.Compensation,
+		Currency:      class.Currency,
+		CreatedAt:     time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return notify(models.Notification{
+		UserID:        reservation.UserID,
+		ReservationID: reservation.ID,
+		Type:          NotificationBumped,
+		Message:       fmt.Sprintf("Your reservation of connector %d of %s from %s was cancelled for a %s reservation and refunded", reservation.Connector, reservation.Chargepoint, reservation.StartTime.Format("Jan 2 15:04"), class.Name),
+	}, collections.Notifications)
+}
@@ -0,0 +1,176 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestPriorityReservations(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/priorityclasses/:id", func(c *gin.Context) {
+		CreatePriorityClass(c, collections.PriorityClasses)
+	})
+
+	router.POST("/priorityclasses/:id/assign", func(c *gin.Context) {
+		AssignPriorityClass(c, collections)
+	})
+
+	router.POST("/chargepoints/:id/holds", func(c *gin.Context) {
+		SetConnectorHold(c, collections)
+	})
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	router.POST("/charge/:cpID/:coID", func(c *gin.Context) {
+		Charge(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Organizations, collections.Chargepoints, collections.Reservations, collections.PriorityClasses, collections.Compensations, collections.Notifications, collections.Sessions} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "priorityCommuter", Name: "Commuter"})
+	collections.Users.InsertOne(context.Background(), models.User{ID: "priorityMedic", Name: "Medic", OrganizationID: "priorityAmbulances"})
+	collections.Organizations.InsertOne(context.Background(), models.Organization{ID: "priorityAmbulances", Name: "Ambulances", Admins: []string{"priorityMedic"}})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "priorityChargepoint", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "Type2"},
+		{ID: 2, State: "Available", PlugType: "Type2"},
+	}})
+
+	// A commuter that hasn't plugged in 6 minutes into their reservation
+	lateID := primitive.NewObjectID()
+	collections.Users.InsertOne(context.Background(), models.User{ID: "priorityLateCommuter", Name: "Late commuter"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "priorityTakeOverChargepoint", Connectors: []models.Connector{
+		{ID: 1, State: "Reserved", PlugType: "Type2"},
+	}})
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: lateID, Chargepoint: "priorityTakeOverChargepoint", Connector: 1, UserID: "priorityLateCommuter", Minutes: 60, StartTime: time.Now().Add(-6 * time.Minute), ExpiryTime: time.Now().Add(4 * time.Minute), ChargingTime: time.Now().Add(54 * time.Minute)})
+
+	request := func(endpoint string, body any) int {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	later := time.Now().Add(3 * time.Hour).Truncate(time.Minute)
+
+	tests := []struct {
+		name     string
+		endpoint string
+		body     any
+		code     int
+	}{
+		{name: "InvalidRank", endpoint: "/priorityclasses/priorityEmergency", body: map[string]any{"name": "Emergency"}, code: http.StatusBadRequest},
+		{name: "CreateClass", endpoint: "/priorityclasses/priorityEmergency", body: map[string]any{"name": "Emergency", "rank": 10, "canBump": true, "bumpNoticeMinutes": 60, "takeOverMinutes": 5, "compensation": 500}, code: http.StatusOK},
+		{name: "AssignOrganization", endpoint: "/priorityclasses/priorityEmergency/assign", body: map[string]any{"organizationId": "priorityAmbulances"}, code: http.StatusOK},
+		{name: "HoldConnector", endpoint: "/chargepoints/priorityChargepoint/holds", body: map[string]any{"connectorId": 1, "priorityClass": "priorityEmergency", "releaseMinutes": 10}, code: http.StatusOK},
+		{name: "HeldConnector", endpoint: "/reservations/priorityChargepoint/1", body: map[string]any{"userId": "priorityCommuter", "minutes": 60, "startTime": later}, code: http.StatusForbidden},
+		{name: "ReleasedConnector", endpoint: "/reservations/priorityChargepoint/1", body: map[string]any{"userId": "priorityCommuter", "minutes": 60}, code: http.StatusOK},
+		{name: "OtherConnector", endpoint: "/reservations/priorityChargepoint/2", body: map[string]any{"userId": "priorityCommuter", "minutes": 60, "startTime": later}, code: http.StatusOK},
+		{name: "WithoutBump", endpoint: "/reservations/priorityChargepoint/2", body: map[string]any{"userId": "priorityMedic", "minutes": 60, "startTime": later}, code: http.StatusBadRequest},
+		{name: "HolderNotLate", endpoint: "/reservations/priorityChargepoint/1", body: map[string]any{"userId": "priorityMedic", "minutes": 60, "bump": true}, code: http.StatusBadRequest},
+		{name: "Bump", endpoint: "/reservations/priorityChargepoint/2", body: map[string]any{"userId": "priorityMedic", "minutes": 60, "startTime": later, "bump": true}, code: http.StatusOK},
+		{name: "TakeOver", endpoint: "/reservations/priorityTakeOverChargepoint/1", body: map[string]any{"userId": "priorityMedic", "minutes": 30, "bump": true}, code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := request(test.endpoint, test.body)
+			if code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, code)
+			}
+		})
+	}
+
+	t.Run("Compensated", func(t *testing.T) {
+		var bumped models.Reservation
+		collections.Reservations.FindOne(context.Background(), bson.M{"userId": "priorityCommuter", "connector": 2}).Decode(&bumped)
+		if !bumped.Cancelled || bumped.BumpedBy.IsZero() {
+			t.Errorf("Expected the commuter's reservation to be bumped, but received %+v", bumped)
+		}
+
+		compensations, _ := GetCompensations("priorityCommuter", collections.Compensations)
+		if len(compensations) != 1 || compensations[0].Amount != 500 || compensations[0].ReservationID != bumped.ID {
+			t.Errorf("Expected a compensation of 500 for the bumped reservation, but received %v", compensations)
+		}
+
+		notifications, _ := GetUserNotifications("priorityCommuter", collections.Notifications)
+		if len(notifications) != 1 || notifications[0].Type != NotificationBumped {
+			t.Errorf("Expected the commuter to be notified, but received %v", notifications)
+		}
+	})
+
+	t.Run("TakenOver", func(t *testing.T) {
+		late, _ := FindReservationByID(lateID.Hex(), collections.Reservations)
+		if !late.Cancelled || late.BumpedBy.IsZero() {
+			t.Errorf("Expected the late commuter's reservation to be taken over, but received %+v", late)
+		}
+
+		chargepoint, _ := FindChargepointByID("priorityTakeOverChargepoint", collections.Chargepoints)
+		if chargepoint.Connectors[0].State != "Reserved" {
+			t.Errorf("Expected the connector to stay reserved for the medic, but it's %s", chargepoint.Connectors[0].State)
+		}
+	})
+
+	t.Run("BumpedHolderCantCharge", func(t *testing.T) {
+		if code := request("/charge/priorityTakeOverChargepoint/1", map[string]any{"userId": "priorityLateCommuter"}); code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, but received %d", http.StatusBadRequest, code)
+		}
+
+		chargepoint, _ := FindChargepointByID("priorityTakeOverChargepoint", collections.Chargepoints)
+		if chargepoint.Connectors[0].State != "Reserved" {
+			t.Errorf("Expected the connector to stay reserved for the medic, but it's %s", chargepoint.Connectors[0].State)
+		}
+	})
+	t.Run("StartedChargingMeanwhile", func(t *testing.T) {
+		waiting := models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "priorityChargepoint", Connector: 2, UserID: "priorityCommuter"}
+		charging := models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "priorityChargepoint", Connector: 2, UserID: "priorityCommuter", HasStartedCharging: true}
+		for _, reservation := range []models.Reservation{waiting, charging} {
+			collections.Reservations.InsertOne(context.Background(), reservation)
+		}
+
+		cancelled, err := cancelBumped([]models.Reservation{waiting, charging}, models.Reservation{ID: primitive.NewObjectID()}, collections)
+		if err != nil || cancelled {
+			t.Fatalf("Expected the bump to conflict with the charging reservation, but received %v %v", cancelled, err)
+		}
+
+		restored, _ := FindReservationByID(waiting.ID.Hex(), collections.Reservations)
+		if restored.Cancelled || restored.HasFinishedCharging || !restored.BumpedBy.IsZero() {
+			t.Errorf("Expected the other reservation to be restored, but received %+v", restored)
+		}
+	})
+}
@@ -0,0 +1,150 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reservations/models"
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Codes returned in models.LimitErrorResponse when a quota is hit
+const (
+	QuotaMaxActiveReservations = "MAX_ACTIVE_RESERVATIONS"
+	QuotaMaxDailyMinutes       = "MAX_DAILY_MINUTES"
+	QuotaMaxUpcoming           = "MAX_UPCOMING_RESERVATIONS"
+)
+
+// QuotaPolicy limits how much a single user can reserve. A limit of 0 disables the check.
+type QuotaPolicy struct {
+	MaxActiveReservations int
+	MaxDailyMinutes       int
+	// Reservations booked ahead that haven't started yet
+	MaxUpcomingReservations int
+}
+
+// LoadQuotaPolicy reads the quota policy from the environment, falling back to the defaults for any unset variable
+func LoadQuotaPolicy() QuotaPolicy {
+	return QuotaPolicy{
+		MaxActiveReservations:   envInt("MAX_ACTIVE_RESERVATIONS", 2),
+		MaxDailyMinutes:         envInt("MAX_DAILY_RESERVED_MINUTES", 360),
+		MaxUpcomingReservations: envInt("MAX_UPCOMING_RESERVATIONS", 20),
+	}
+}
+
+func envInt(name string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(name))
+	if err != nil {
+		return fallback
+	}
+
+	return value
+}
+
+// checkQuotas returns a non-nil response describing the first limit the user would exceed by reserving the given amount of minutes. A reservation being changed is passed as except, so it doesn't count against itself.
+func checkQuotas(userID string, minutes int, except primitive.ObjectID, policy QuotaPolicy, reservationsCollection *mongo.Collection) (*models.LimitErrorResponse, error) {
+	now := time.Now()
+
+	if policy.MaxActiveReservations > 0 {
+		active, err := reservationsCollection.CountDocuments(context.Background(), bson.M{"_id": bson.M{"$ne": except}, "userId": userID, "hasFinishedCharging": false, "walkIn": bson.M{"$ne": true}, "startTime": bson.M{"$lte": now}})
+		if err != nil {
+			return nil, err
+		}
+
+		if int(active) >= policy.MaxActiveReservations {
+			return &models.LimitErrorResponse{
+				Error:   fmt.Sprintf("A user can have at most %d active reservations", policy.MaxActiveReservations),
+				Code:    QuotaMaxActiveReservations,
+				Limit:   policy.MaxActiveReservations,
+				Current: int(active),
+			}, nil
+		}
+	}
+
+	if policy.MaxDailyMinutes > 0 {
+		startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		reserved, err := sumReservedMinutes(bson.M{"_id": bson.M{"$ne": except}, "userId": userID, "createdAt": bson.M{"$gte": startOfDay}, "walkIn": bson.M{"$ne": true}, "startTime": bson.M{"$lte": now}}, reservationsCollection)
+		if err != nil {
+			return nil, err
+		}
+
+		if reserved+minutes > policy.MaxDailyMinutes {
+			tomorrow := startOfDay.AddDate(0, 0, 1)
+			return &models.LimitErrorResponse{
+				Error:      fmt.Sprintf("A user can reserve at most %d minutes per day", policy.MaxDailyMinutes),
+				Code:       QuotaMaxDailyMinutes,
+				Limit:      policy.MaxDailyMinutes,
+				Current:    reserved,
+				RetryAfter: &tomorrow,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// checkUpcomingQuotas is checkQuotas for reservations booked ahead. Those are limited by their own count, and their minutes count towards the day they start on.
+func checkUpcomingQuotas(userID string, start time.Time, minutes int, except primitive.ObjectID, policy QuotaPolicy, reservationsCollection *mongo.Collection) (*models.LimitErrorResponse, error) {
+	if policy.MaxUpcomingReservations > 0 {
+		upcoming, err := reservationsCollection.CountDocuments(context.Background(), bson.M{"_id": bson.M{"$ne": except}, "userId": userID, "hasFinishedCharging": false, "startTime": bson.M{"$gt": time.Now()}})
+		if err != nil {
+			return nil, err
+		}
+
+		if int(upcoming) >= policy.MaxUpcomingReservations {
+			return &models.LimitErrorResponse{
+				Error:   fmt.Sprintf("A user can have at most %d upcoming reservations", policy.MaxUpcomingReservations),
+				Code:    QuotaMaxUpcoming,
+				Limit:   policy.MaxUpcomingReservations,
+				Current: int(upcoming),
+			}, nil
+		}
+	}
+
+	if policy.MaxDailyMinutes > 0 {
+		startOfDay := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, start.Location())
+
+		reserved, err := sumReservedMinutes(bson.M{"_id": bson.M{"$ne": except}, "userId": userID, "startTime": bson.M{"$gte": startOfDay, "$lt": startOfDay.AddDate(0, 0, 1)}, "walkIn": bson.M{"$ne": true}}, reservationsCollection)
+		if err != nil {
+			return nil, err
+		}
+
+		if reserved+minutes > policy.MaxDailyMinutes {
+			return &models.LimitErrorResponse{
+				Error:   fmt.Sprintf("A user can reserve at most %d minutes per day", policy.MaxDailyMinutes),
+				Code:    QuotaMaxDailyMinutes,
+				Limit:   policy.MaxDailyMinutes,
+				Current: reserved,
+			}, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// sumReservedMinutes adds up the minutes of every reservation matching the filter
+func sumReservedMinutes(filter bson.M, reservationsCollection *mongo.Collection) (int, error) {
+	cursor, err := reservationsCollection.Find(context.Background(), filter)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	var reservations []models.Reservation
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, reservation := range reservations {
+		total += reservation.Minutes
+	}
+
+	return total, nil
+}
@@ -0,0 +1,91 @@
+package endpoints
+
+import (
+	"context"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestQuotas(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	reservationsCollection := client.Database("TestDB").Collection("reservations")
+
+	defer func() {
+		err := db.ClearCollection(reservationsCollection)
+		if err != nil {
+			t.Fatalf("Failed to clear collection:\n%v", err)
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	policy := QuotaPolicy{
+		MaxActiveReservations: 1,
+		MaxDailyMinutes:       120,
+	}
+
+	tests := []struct {
+		name         string
+		userID       string
+		reservations []models.Reservation
+		minutes      int
+		code         string
+	}{
+		{name: "WithinQuota", userID: "quotaFree", minutes: 60, code: ""},
+		{
+			name:   "MaxActiveReservations",
+			userID: "quotaActive",
+			reservations: []models.Reservation{
+				{ID: primitive.NewObjectID(), UserID: "quotaActive", Minutes: 30, CreatedAt: time.Now()},
+			},
+			minutes: 30,
+			code:    QuotaMaxActiveReservations,
+		},
+		{
+			name:   "MaxDailyMinutes",
+			userID: "quotaDaily",
+			reservations: []models.Reservation{
+				{ID: primitive.NewObjectID(), UserID: "quotaDaily", Minutes: 90, CreatedAt: time.Now(), HasStartedCharging: true, HasFinishedCharging: true},
+			},
+			minutes: 60,
+			code:    QuotaMaxDailyMinutes,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for _, reservation := range test.reservations {
+				_, err := reservationsCollection.InsertOne(context.Background(), reservation)
+				if err != nil {
+					t.Fatalf("Could not insert reservation:\n%v", err)
+				}
+			}
+
+			limit, err := checkQuotas(test.userID, test.minutes, primitive.NilObjectID, policy, reservationsCollection)
+			if err != nil {
+				t.Fatalf("Could not check quotas:\n%v", err)
+			}
+
+			code := ""
+			if limit != nil {
+				code = limit.Code
+			}
+
+			if code != test.code {
+				t.Errorf("Expected limit code %q, but received %q", test.code, code)
+			}
+		})
+	}
+}
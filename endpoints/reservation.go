@@ -3,7 +3,9 @@ package endpoints
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
+	"reservations/bookingcode"
 	"reservations/db"
 	"reservations/models"
 	"strconv"
@@ -11,7 +13,9 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // CreateReservation godoc
@@ -22,13 +26,16 @@ import (
 // @Param chargepointID path string true "Chargepoint ID"
 // @Param connectorID path int true "Connector ID"
 // @Param body body ReservationRequest true "Request body"
-// @Success 200 {object} models.MessageResponse
+// @Param Idempotency-Key header string false "Optional, retries with the same key get the first response instead of handling the request again"
+// @Success 200 {object} models.ReservationResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.LimitErrorResponse
+// @Failure 402 {object} models.LimitErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
 // @Router /reservations/{chargepointID}/{connectorID} [post]
-func CreateReservation(c *gin.Context, reservationsCollection, chargepointsCollection, usersCollection *mongo.Collection) {
-	var newReservation models.Reservation
-
+func CreateReservation(c *gin.Context, collections Collections) {
 	var req ReservationRequest
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -36,14 +43,7 @@ func CreateReservation(c *gin.Context, reservationsCollection, chargepointsColle
 		return
 	}
 
-	// See suggestion in models/models.go#Reservation
-	newReservation.ID = int(time.Now().UnixNano())
-
-	chargepointID := c.Param("cpID")
-	newReservation.Chargepoint = chargepointID
-	connectorID := c.Param("coID")
-
-	chargepoint, err := FindChargepointByID(chargepointID, chargepointsCollection)
+	chargepoint, err := FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
 			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
@@ -53,7 +53,7 @@ func CreateReservation(c *gin.Context, reservationsCollection, chargepointsColle
 		return
 	}
 
-	connectorNumber, err := strconv.Atoi(connectorID)
+	connectorNumber, err := strconv.Atoi(c.Param("coID"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector must be a number"})
 		return
@@ -64,53 +64,467 @@ func CreateReservation(c *gin.Context, reservationsCollection, chargepointsColle
 		return
 	}
 
+	reservation, warnings, reserveErr := reserve(req, chargepoint, connectorNumber, false, collections)
+	if reserveErr != nil {
+		c.JSON(reserveErr.Status, reserveErr.Body)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ReservationResponse{
+		Message:       "Reservation created",
+		Warnings:      warnings,
+		ReservationID: reservation.ID,
+		Code:          reservation.Code,
+		Chargepoint:   reservation.Chargepoint,
+		Connector:     reservation.Connector,
+	})
+}
+
+// reservationError is why a reservation couldn't be made, with the status and body to respond with
+type reservationError struct {
+	Status int
+	Body   any
+	// Whether it's the connector that can't be reserved, so another connector might do
+	Connector bool
+}
+
+func (e *reservationError) Error() string {
+	switch body := e.Body.(type) {
+	case models.ErrorResponse:
+		return body.Error
+	case *models.LimitErrorResponse:
+		return body.Error
+	}
+
+	return http.StatusText(e.Status)
+}
+
+// checkAccess checks whether the user may take the connector from the start for the minutes: the connector's reservation policy and hold, and the user's quotas, organization limits and no-show penalties. It returns the user's priority class and the warnings to pass on.
+// Walk-ins start charging right away, so they can't be no-shows and don't need a deposit.
+func checkAccess(user models.User, chargepoint models.Chargepoint, connectorNumber int, policy models.ConnectorPolicy, start time.Time, minutes int, deposit int64, walkIn bool, collections Collections) (models.PriorityClass, []string, *reservationError) {
+	now := time.Now()
+
+	if !allowsUser(policy, user) {
+		return models.PriorityClass{}, nil, &reservationError{Status: http.StatusForbidden, Body: models.ErrorResponse{Error: "The connector can only be reserved by certain organizations"}, Connector: true}
+	}
+
+	class, err := priorityClassOf(user, collections)
+	if err != nil {
+		return models.PriorityClass{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not fetch the user's priority class"}}
+	}
+
+	if holdErr := checkHold(chargepoint.Connectors[connectorNumber-1], class, start, now, collections); holdErr != nil {
+		return models.PriorityClass{}, nil, holdErr
+	}
+
+	var limit *models.LimitErrorResponse
+	if start.After(now) {
+		limit, err = checkUpcomingQuotas(user.ID, start, minutes, primitive.NilObjectID, LoadQuotaPolicy(), collections.Reservations)
+	} else {
+		limit, err = checkQuotas(user.ID, minutes, primitive.NilObjectID, LoadQuotaPolicy(), collections.Reservations)
+	}
+	if err != nil {
+		return models.PriorityClass{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not check the user's reservation quotas"}}
+	}
+	if limit != nil {
+		return models.PriorityClass{}, nil, &reservationError{Status: http.StatusForbidden, Body: limit}
+	}
+
+	limit, err = checkOrganizationLimits(user, chargepoint, minutes, collections)
+	if err != nil {
+		return models.PriorityClass{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not check the organization's limits"}}
+	}
+	if limit != nil {
+		return models.PriorityClass{}, nil, &reservationError{Status: http.StatusForbidden, Body: limit}
+	}
+
+	var warnings []string
+	limit, warning, err := checkPenalty(user.ID, deposit, LoadPenaltyPolicy(), collections.NoShows)
+	if err != nil {
+		return models.PriorityClass{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not check the user's no-show penalties"}}
+	}
+	if limit != nil && !(walkIn && limit.Code == QuotaDepositRequired) {
+		return models.PriorityClass{}, nil, &reservationError{Status: http.StatusForbidden, Body: limit}
+	}
+	if warning != "" && !walkIn {
+		warnings = append(warnings, warning)
+	}
+
+	return class, warnings, nil
+}
+
+// reserve reserves the chargepoint's connector as requested, or returns why it can't. A claimed connector was already set to "Reserved" by the caller, so it isn't required to be available.
+func reserve(req ReservationRequest, chargepoint models.Chargepoint, connectorNumber int, claimed bool, collections Collections) (models.Reservation, []string, *reservationError) {
+	var newReservation models.Reservation
+
+	newReservation.ID = primitive.NewObjectID()
+	newReservation.Chargepoint = chargepoint.ID
 	newReservation.Connector = connectorNumber
 
-	if chargepoint.Connectors[connectorNumber-1].State != "Available" {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector must be available"})
-		return
+	policy, err := resolveReservationPolicy(chargepoint, connectorNumber, collections)
+	if err != nil {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch the connector's reservation policy"}}
 	}
 
-	_, err = FindUserByID(req.UserID, usersCollection)
+	// Reservations start right away, unless they're booked ahead
+	now := time.Now()
+	start := now
+	if req.StartTime != nil && req.StartTime.After(now) {
+		if req.StartTime.After(now.AddDate(0, 0, policy.HorizonDays)) {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: fmt.Sprintf("Reservations can start at most %d days ahead", policy.HorizonDays)}, Connector: true}
+		}
+		start = *req.StartTime
+	}
+	upcoming := start.After(now)
+
+	// A connector offered from the waitlist can only be reserved by the user it's offered to, which accepts the offer
+	var offer *models.WaitlistEntry
+	if !upcoming && chargepoint.Connectors[connectorNumber-1].State == "Offered" {
+		offer, err = findOffer(chargepoint.ID, connectorNumber, req.UserID, collections.Waitlist)
+		if err != nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch the waitlist"}}
+		}
+	}
+
+	// A priority user bumping the reservation that holds the connector takes it over, see bumpableReservations
+	state := chargepoint.Connectors[connectorNumber-1].State
+	takeOver := req.Bump && state == "Reserved" && offer == nil && !claimed && !upcoming
+	if state != "Available" && offer == nil && !claimed && !upcoming && !takeOver {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Connector must be available"}}
+	}
+
+	user, err := FindUserByID(req.UserID, collections.Users)
 	newReservation.UserID = req.UserID
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not exist"})
-			return
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "User does not exist"}}
 		}
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch users"})
-		return
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not fetch users"}}
 	}
 
-	if req.Minutes < 30 || req.Minutes > 180 {
-		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The reservation time must be between 30 and 180 minutes"})
-		return
+	var warnings []string
+	var reservedVehicle *models.Vehicle
+
+	if req.VehicleID != "" {
+		vehicle, err := FindVehicleByID(req.VehicleID, collections.Vehicles)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Vehicle does not exist"}}
+			}
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not fetch vehicles"}}
+		}
+
+		if vehicle.UserID != req.UserID {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Vehicle does not belong to the user"}}
+		}
+
+		compatible, compatibilityWarnings := checkCompatibility(vehicle, chargepoint.Connectors[connectorNumber-1])
+		if !compatible {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Connector is not compatible with the vehicle"}, Connector: true}
+		}
+
+		warnings = append(warnings, compatibilityWarnings...)
+		newReservation.VehicleID = req.VehicleID
+		reservedVehicle = &vehicle
+	}
+
+	// A departure without a reservation time reserves the connector until then
+	if req.Departure != nil {
+		if !req.Departure.After(start) {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "The departure must be in the future"}}
+		}
+
+		untilDeparture := int(math.Ceil(req.Departure.Sub(start).Minutes()))
+		if req.Minutes == 0 {
+			req.Minutes = untilDeparture
+		} else if untilDeparture > req.Minutes {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "The departure must be before the end of the reservation"}}
+		}
+
+		newReservation.Departure = *req.Departure
+	}
+
+	if message := checkDuration(policy, req.Minutes); message != "" {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: message}, Connector: true}
+	}
+
+	if req.TargetEnergy < 0 {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "The target energy can't be negative"}}
+	}
+
+	if reservedVehicle != nil && reservedVehicle.BatteryCapacity > 0 && req.TargetEnergy > reservedVehicle.BatteryCapacity {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "The target energy can't be more than the vehicle's battery capacity"}}
 	}
+	newReservation.TargetEnergy = req.TargetEnergy
 
-	newReservation.ExpiryTime = time.Now().Add(10 * time.Minute)
-	newReservation.ChargingTime = time.Now().Add(time.Duration(req.Minutes) * time.Minute)
+	class, accessWarnings, accessErr := checkAccess(user, chargepoint, connectorNumber, policy, start, req.Minutes, req.Deposit, false, collections)
+	if accessErr != nil {
+		return models.Reservation{}, nil, accessErr
+	}
+	warnings = append(warnings, accessWarnings...)
 
-	_, err = reservationsCollection.InsertOne(context.Background(), newReservation)
+	tariff, err := resolveTariff(chargepoint, connectorNumber, collections)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create a reservation"})
-		return
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch the connector's tariff"}}
+	}
+
+	newReservation.TariffID = tariff.ID
+	newReservation.OrganizationID = user.OrganizationID
+	newReservation.Deposit = req.Deposit
+	newReservation.Minutes = req.Minutes
+	newReservation.CreatedAt = now
+	newReservation.StartTime = start
+	newReservation.ExpiryTime = start.Add(time.Duration(policy.GraceMinutes) * time.Minute)
+	newReservation.ChargingTime = start.Add(time.Duration(req.Minutes) * time.Minute)
+
+	newReservation.Priority = user.Priority
+	newReservation.PriorityClass = class.ID
+	newReservation.SeriesID = req.seriesID
+
+	// The buffer keeps the connector free for the previous car to leave and the next one to arrive
+	buffer := time.Duration(policy.BufferMinutes) * time.Minute
+	booked, err := findOverlapping(chargepoint.ID, connectorNumber, newReservation.StartTime.Add(-buffer), newReservation.ChargingTime.Add(buffer), primitive.NilObjectID, collections.Reservations)
+	if err != nil {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch reservations"}}
 	}
 
-	chargepoint.Connectors[connectorNumber-1].State = "Reserved"
+	// Priority users asking to bump take the place of the overlapping reservations they outrank
+	var bumped []models.Reservation
+	if booked != nil && req.Bump {
+		bumped, booked, err = bumpableReservations(class, chargepoint.ID, connectorNumber, newReservation.StartTime.Add(-buffer), newReservation.ChargingTime.Add(buffer), now, collections)
+		if err != nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch reservations"}}
+		}
+	}
+	if booked != nil {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: fmt.Sprintf("The connector is reserved from %s to %s", booked.StartTime.Format("Jan 2 15:04"), booked.ChargingTime.Format("15:04"))}, Connector: true}
+	}
 
-	filter := bson.M{"_id": chargepoint.ID}
-	_, err = chargepointsCollection.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"connectors": chargepoint.Connectors}})
+	if takeOver {
+		holder := false
+		for _, reservation := range bumped {
+			if !reservation.StartTime.After(now) {
+				holder = true
+			}
+		}
+		if !holder {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "Connector must be available"}}
+		}
+	}
+
+	maintenance, err := underMaintenance(chargepoint.ID, connectorNumber, newReservation.StartTime, newReservation.ChargingTime, collections.Maintenance)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the state of the connector"})
-		return
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch maintenance windows"}}
+	}
+	if maintenance != nil {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: fmt.Sprintf("The connector is under maintenance from %s to %s", maintenance.Start.Format("15:04"), maintenance.End.Format("15:04"))}, Connector: true}
+	}
+
+	// Sites sharing a grid connection downgrade the reservation to the power that's left during it, or refuse it when too little is left
+	reservedConnector := chargepoint.Connectors[connectorNumber-1]
+	demand := reservedConnector.MaxPower
+	if reservedVehicle != nil {
+		demand = chargingPower(*reservedVehicle, reservedConnector)
+	}
+
+	powerLimit, limit, err := checkSiteCapacity(chargepoint, connectorNumber, demand, newReservation.StartTime, newReservation.ChargingTime, collections)
+	if err != nil {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not check the site's capacity"}}
+	}
+	if limit != nil {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusForbidden, Body: limit, Connector: true}
+	}
+	if powerLimit > 0 {
+		newReservation.PowerLimit = powerLimit
+		reservedConnector.MaxPower = powerLimit
+		warnings = append(warnings, fmt.Sprintf("Charging is limited to %.1f kW because the site's grid connection is shared", powerLimit))
+	}
+
+	newReservation.PaymentMode, err = paymentModeOf(user, collections)
+	if err != nil {
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not fetch the user's wallet"}}
+	}
+
+	// A valid quote locks in the adjustments it was priced with, otherwise the site's pricing policy is applied now
+	var quote *models.Quote
+	if req.QuoteID != "" {
+		var startTime time.Time
+		if upcoming {
+			startTime = start
+		}
+
+		quote, err = findValidQuote(req.QuoteID, req.UserID, chargepoint.ID, connectorNumber, req.Minutes, startTime, collections.Quotes)
+		if err != nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to fetch quotes"}}
+		}
+		if quote == nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "The quote has expired, was used already or doesn't match the reservation"}, Connector: true}
+		}
+		newReservation.Adjustments = quote.Adjustments
+	} else {
+		newReservation.Adjustments, err = dynamicAdjustments(chargepoint, newReservation.StartTime, newReservation.ChargingTime, collections)
+		if err != nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Unable to apply the site's pricing policy"}}
+		}
+	}
+
+	energy := estimateEnergy(reservedVehicle, reservedConnector, req.Minutes)
+	if newReservation.TargetEnergy > 0 && newReservation.TargetEnergy < energy {
+		energy = newReservation.TargetEnergy
+	}
+
+	estimate := ApplyAdjustments(ComputePrice(tariff, Usage{
+		Reserved: true,
+		Start:    newReservation.StartTime,
+		End:      newReservation.ChargingTime,
+		Energy:   energy,
+	}), newReservation.Adjustments)
+
+	// The quote is used up first, so two reservations made at once can't both lock in its price
+	if quote != nil {
+		used, err := useQuote(quote, newReservation.ID, collections.Quotes)
+		if err != nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not use the quote"}}
+		}
+		if !used {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusBadRequest, Body: models.ErrorResponse{Error: "The quote has expired, was used already or doesn't match the reservation"}, Connector: true}
+		}
+	}
+
+	// The bumped reservations are cancelled first, so none of them can start charging on the connector once it's taken
+	cancelled, err := cancelBumped(bumped, newReservation, collections)
+	if err != nil || !cancelled {
+		if err := releaseQuote(quote, newReservation.ID, collections.Quotes); err != nil {
+			fmt.Println("Error releasing quote: ", err)
+		}
+		if err != nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not bump the overlapping reservations"}}
+		}
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusConflict, Body: models.ErrorResponse{Error: "An overlapping reservation started charging or was cancelled in the meantime, try again"}}
+	}
+
+	if paymentErr := securePayment(user, newReservation, estimate, req.PaymentMethod, collections); paymentErr != nil {
+		if err := restoreBumped(bumped, newReservation, collections); err != nil {
+			fmt.Println("Error restoring bumped reservations: ", err)
+		}
+		if err := releaseQuote(quote, newReservation.ID, collections.Quotes); err != nil {
+			fmt.Println("Error releasing quote: ", err)
+		}
+		return models.Reservation{}, nil, paymentErr
+	}
+
+	err = insertReservation(&newReservation, collections.Reservations)
+	if err != nil {
+		if err := releaseHold(newReservation.ID, collections); err != nil {
+			fmt.Println("Error releasing wallet hold: ", err)
+		}
+		if err := voidPayment(newReservation.ID, collections); err != nil {
+			fmt.Println("Error voiding payment: ", err)
+		}
+		if err := restoreBumped(bumped, newReservation, collections); err != nil {
+			fmt.Println("Error restoring bumped reservations: ", err)
+		}
+		if err := releaseQuote(quote, newReservation.ID, collections.Quotes); err != nil {
+			fmt.Println("Error releasing quote: ", err)
+		}
+		return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Failed to create a reservation"}}
+	}
+
+	// The reservation is made and the bumped ones are cancelled for good, so a failure to refund one doesn't undo it
+	for _, reservation := range bumped {
+		if err := bumpReservation(reservation, newReservation, class, !upcoming, collections); err != nil {
+			fmt.Println("Error bumping reservation: ", err)
+		}
+	}
+	if len(bumped) > 0 {
+		warnings = append(warnings, fmt.Sprintf("Bumped %d reservations of users with a lower priority", len(bumped)))
 	}
 
-	c.JSON(http.StatusOK, models.MessageResponse{Message: "Reservation created"})
+	// Reservations booked ahead take the connector once they start, see activateReservations
+	if !upcoming {
+		filter := bson.M{"_id": chargepoint.ID, "connectors._id": connectorNumber}
+		_, err = collections.Chargepoints.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"connectors.$.state": "Reserved"}})
+		if err != nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not update the state of the connector"}}
+		}
+	}
+
+	if offer != nil {
+		_, err = collections.Waitlist.UpdateOne(context.Background(), bson.M{"_id": offer.ID}, bson.M{"$set": bson.M{"status": WaitlistAccepted}})
+		if err != nil {
+			return models.Reservation{}, nil, &reservationError{Status: http.StatusInternalServerError, Body: models.ErrorResponse{Error: "Could not accept the waitlist offer"}}
+		}
+	}
+
+	return newReservation, warnings, nil
 }
 
 type ReservationRequest struct {
 	UserID  string `json:"userId"`
 	Minutes int    `json:"minutes"`
+	// Deposit in cents, only required from users with too many no-shows (see GET /users/{id}/reliability). It is held on the wallet or pre-authorized on the card with the estimated price, kept on a no-show and given back once the user charges.
+	Deposit int64 `json:"deposit"`
+	// Optional, the reservation is refused when the connector doesn't fit the vehicle
+	VehicleID string `json:"vehicleId"`
+	// The gateway's payment method, required when card payments are enabled and the user has no wallet or organization
+	PaymentMethod string `json:"paymentMethod"`
+	// Optional smart charging: the energy (in kWh) needed by the departure, see GET /reservations/{id}/schedule. Without minutes, the connector is reserved until the departure.
+	TargetEnergy float64    `json:"targetEnergy"`
+	Departure    *time.Time `json:"departure"`
+	// Optional, locks in the price of a quote (see POST /quotes/{chargepointID}/{connectorID}) that hasn't expired
+	QuoteID string `json:"quoteId"`
+	// Optional, for users of a priority class that can bump: takes the place of the overlapping reservations of users with a lower rank, or takes over the connector from a late one, see POST /priorityclasses/{id}
+	Bump bool `json:"bump"`
+	// Optional, books the connector ahead (up to the booking horizon of the connector's reservation policy) instead of reserving it right away
+	StartTime *time.Time `json:"startTime"`
+	// Set when booking an occurrence of a series
+	seriesID string
+}
+
+// insertReservation stores the reservation with a booking code that no other reservation has
+func insertReservation(reservation *models.Reservation, collection *mongo.Collection) error {
+	var err error
+	for attempt := 0; attempt < 5; attempt++ {
+		reservation.Code, err = bookingcode.New()
+		if err != nil {
+			return err
+		}
+
+		_, err = collection.InsertOne(context.Background(), reservation)
+		if !mongo.IsDuplicateKeyError(err) {
+			return err
+		}
+	}
+
+	return err
+}
+
+// bookingHorizonDays is how far ahead reservations can be booked
+func bookingHorizonDays() int {
+	return envInt("BOOKING_HORIZON_DAYS", 30)
+}
+
+// findOverlapping returns a reservation of the connector, other than the excepted one, that hasn't finished and overlaps the period, or nil when there is none
+func findOverlapping(chargepointID string, connector int, start, end time.Time, except primitive.ObjectID, collection *mongo.Collection) (*models.Reservation, error) {
+	filter := bson.M{
+		"_id":                 bson.M{"$ne": except},
+		"chargepoint":         chargepointID,
+		"connector":           connector,
+		"hasFinishedCharging": false,
+		"startTime":           bson.M{"$lt": end},
+		"chargingTime":        bson.M{"$gt": start},
+	}
+
+	var reservation models.Reservation
+	err := collection.FindOne(context.Background(), filter, options.FindOne().SetSort(bson.M{"startTime": 1})).Decode(&reservation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &reservation, nil
 }
 
 // GetAllReservations godoc
@@ -129,19 +543,259 @@ func GetAllReservations(collection *mongo.Collection) ([]bson.M, error) {
 	return documents, nil
 }
 
-func CheckReservations(reservationsCollection, chargepointsCollection *mongo.Collection) {
+// FindReservationByID godoc
+// @Summary Get information about a reservation by ID
+// @Tags Reservations
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} models.Reservation
+// @Failure 404 {object} models.ErrorResponse
+// @Router /reservations/{id} [get]
+func FindReservationByID(id string, collection *mongo.Collection) (models.Reservation, error) {
+	var reservation models.Reservation
+
+	// Reservations made before they got ObjectIDs can still be found by their old numeric ID
+	filter := bson.M{}
+	if reservationID, err := primitive.ObjectIDFromHex(id); err == nil {
+		filter["_id"] = reservationID
+	} else if legacyID, err := strconv.Atoi(id); err == nil {
+		filter["legacyId"] = legacyID
+	} else {
+		return models.Reservation{}, mongo.ErrNoDocuments
+	}
+
+	err := collection.FindOne(context.Background(), filter).Decode(&reservation)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+// FindReservationByCode godoc
+// @Summary Look up a reservation by its booking code
+// @Description For the cashier or a tablet at the site. The code can be typed in any case, with or without the dash.
+// @Tags Reservations
+// @Produce json
+// @Param code path string true "Booking code, like 7KQ-4MZ"
+// @Success 200 {object} models.Reservation
+// @Failure 404 {object} models.ErrorResponse
+// @Router /reservations/code/{code} [get]
+func FindReservationByCode(code string, collection *mongo.Collection) (models.Reservation, error) {
+	var reservation models.Reservation
+
+	normalized, ok := bookingcode.Normalize(code)
+	if !ok {
+		return models.Reservation{}, mongo.ErrNoDocuments
+	}
+
+	err := collection.FindOne(context.Background(), bson.M{"code": normalized}).Decode(&reservation)
+	if err != nil {
+		return models.Reservation{}, err
+	}
+
+	return reservation, nil
+}
+
+// CancelReservation godoc
+// @Summary Cancel a reservation
+// @Description Users can cancel their reservations until they start charging. The connector becomes available again and any amount held from the user's wallet is released.
+// @Tags Reservations
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Param userId query string true "User ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /reservations/{id} [delete]
+func CancelReservation(c *gin.Context, collections Collections) {
+	reservation, err := FindReservationByID(c.Param("id"), collections.Reservations)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Reservation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch reservations"})
+		return
+	}
+
+	if reservation.UserID != c.Query("userId") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The reservation belongs to another user"})
+		return
+	}
+
+	if reservation.HasStartedCharging || reservation.HasFinishedCharging {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Only reservations that haven't started charging can be cancelled"})
+		return
+	}
+
+	err = cancelReservation(reservation, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not cancel the reservation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Reservation cancelled"})
+}
+
+// cancelReservation finishes the reservation, releases its wallet hold and card authorization, and frees the connector if the reservation was holding it
+func cancelReservation(reservation models.Reservation, collections Collections) error {
+	_, err := collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{"hasFinishedCharging": true, "cancelled": true}})
+	if err != nil {
+		return err
+	}
+
+	err = releaseHold(reservation.ID, collections)
+	if err != nil {
+		return err
+	}
+
+	err = voidPayment(reservation.ID, collections)
+	if err != nil {
+		return err
+	}
+
+	chargepoint, err := FindChargepointByID(reservation.Chargepoint, collections.Chargepoints)
+	if err != nil {
+		return err
+	}
+
+	// Reservations booked ahead don't hold the connector until they start
+	if state := chargepoint.Connectors[reservation.Connector-1].State; (state != "Reserved" && state != "Charging") || reservation.StartTime.After(time.Now()) {
+		return nil
+	}
+
+	chargepoint.Connectors[reservation.Connector-1].State = "Available"
+
+	_, err = collections.Chargepoints.UpdateOne(context.Background(), bson.M{"_id": chargepoint.ID}, bson.M{"$set": bson.M{"connectors": chargepoint.Connectors}})
+	if err != nil {
+		return err
+	}
+
+	return offerConnectors(chargepoint.ID, collections)
+}
+
+// OperatorCancelReservation godoc
+// @Summary Cancel a reservation as an operator
+// @Description Operators can cancel any reservation that hasn't finished, for example when the connector breaks down. An active charging session is stopped, and the user gets back everything they paid for the reservation, from their wallet or card.
+// @Tags Operators
+// @Accept json
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Param body body OperatorCancelRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /cancel/{id} [post]
+func OperatorCancelReservation(c *gin.Context, collections Collections) {
+	var req OperatorCancelRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.Reason == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Reason must be a non-empty string"})
+		return
+	}
+
+	reservation, err := FindReservationByID(c.Param("id"), collections.Reservations)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Reservation not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch reservations"})
+		return
+	}
+
+	if reservation.HasFinishedCharging {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The reservation has already finished"})
+		return
+	}
+
+	if reservation.HasStartedCharging {
+		err = stopSession(reservation.Chargepoint, reservation.Connector, 0, StopReasonCancelled, collections)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not stop the charging session"})
+			return
+		}
+	}
+
+	err = cancelReservation(reservation, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not cancel the reservation"})
+		return
+	}
+
+	_, err = collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{"cancelReason": req.Reason}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the reservation"})
+		return
+	}
+
+	err = refundWallet(reservation.ID, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not refund the user's wallet"})
+		return
+	}
+
+	err = refundPayment(reservation.ID, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not refund the payment"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Reservation cancelled and refunded"})
+}
+
+type OperatorCancelRequest struct {
+	Reason string `json:"reason"`
+}
+
+func CheckReservations(collections Collections) {
 
 	// Runs reservation checks every 1 minute
 	for range time.NewTicker(1 * time.Minute).C {
-		checkNonChargingReservations(reservationsCollection, chargepointsCollection)
-		checkFinishedReservations(reservationsCollection, chargepointsCollection)
+		activateReservations(collections)
+		checkNonChargingReservations(collections)
+		checkFinishedReservations(collections)
+		checkIdleSessions(collections)
+		checkLateSessions(collections)
+		checkWaitlists(collections)
+		extendSeries(collections)
+	}
+
+}
+
+// activateReservations makes the connectors of reservations booked ahead "Reserved" once they start
+func activateReservations(collections Collections) {
+	cursor, err := collections.Reservations.Find(context.Background(), bson.M{"startTime": bson.M{"$lte": time.Now()}, "expiryTime": bson.M{"$gt": time.Now()}, "hasStartedCharging": false, "hasFinishedCharging": false})
+	if err != nil {
+		fmt.Println("Error getting reservations: ", err)
+		return
+	}
+
+	var reservations []models.Reservation
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		fmt.Println("Error decoding reservations: ", err)
+		return
 	}
 
+	for _, reservation := range reservations {
+		_, err := setConnectorState(reservation.Chargepoint, reservation.Connector, "Available", "Reserved", collections.Chargepoints)
+		if err != nil {
+			fmt.Println("Error updating chargepoint connector state: ", err)
+		}
+	}
 }
 
-func checkNonChargingReservations(reservationsCollection, chargepointsCollection *mongo.Collection) {
+func checkNonChargingReservations(collections Collections) {
 	// Get all of the expired reservations that never started charging (expiry time has passed, they haven't started charging)
-	reservations, err := reservationsCollection.Find(context.Background(), bson.M{"expiryTime": bson.M{"$lte": time.Now()}, "hasStartedCharging": false, "hasFinishedCharging": false})
+	reservations, err := collections.Reservations.Find(context.Background(), bson.M{"expiryTime": bson.M{"$lte": time.Now()}, "hasStartedCharging": false, "hasFinishedCharging": false})
 	if err != nil {
 		fmt.Println("Error getting reservations: ", err)
 		return
@@ -157,34 +811,49 @@ func checkNonChargingReservations(reservationsCollection, chargepointsCollection
 		}
 
 		// Set it to a finished reservation
-		_, err = reservationsCollection.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{"hasFinishedCharging": true}})
+		_, err = collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{"hasFinishedCharging": true}})
 		if err != nil {
 			fmt.Println("Error updating reservation charging status: ", err)
 			continue
 		}
 
-		chargepoint, err := FindChargepointByID(reservation.Chargepoint, chargepointsCollection)
+		// Set the connector to "Available" again, ready for future reservations. A connector that isn't reserved never was for this reservation (the previous vehicle didn't leave, or someone charged without a reservation), so the user couldn't have charged.
+		freed, err := setConnectorState(reservation.Chargepoint, reservation.Connector, "Reserved", "Available", collections.Chargepoints)
 		if err != nil {
-			fmt.Println("Error getting chargepoint: ", err)
-			continue
+			fmt.Println("Error updating chargepoint connector state: ", err)
 		}
 
-		// Set the connector to "Available" again, ready for future reservations
-		chargepoint.Connectors[reservation.Connector-1].State = "Available"
+		if freed {
+			// Keep track of the no-show, it counts towards the user's penalties
+			err = recordNoShow(reservation, collections.NoShows)
+			if err != nil {
+				fmt.Println("Error recording no-show: ", err)
+			}
+
+			// The fee is taken before the rest of the hold or authorization is given back
+			err = applyNoShowFee(reservation, collections)
+			if err != nil {
+				fmt.Println("Error applying no-show fee: ", err)
+			}
+		}
 
-		_, err = chargepointsCollection.UpdateOne(context.Background(), bson.M{"_id": chargepoint.ID}, bson.M{"$set": bson.M{"connectors": chargepoint.Connectors}})
+		err = releaseHold(reservation.ID, collections)
 		if err != nil {
-			fmt.Println("Error updating chargepoint connector state: ", err)
-			continue
+			fmt.Println("Error releasing wallet hold: ", err)
+		}
+
+		err = voidPayment(reservation.ID, collections)
+		if err != nil {
+			fmt.Println("Error voiding payment: ", err)
 		}
 	}
 
 	reservations.Close(context.Background())
 }
 
-func checkFinishedReservations(reservationsCollection, chargepointsCollection *mongo.Collection) {
+func checkFinishedReservations(collections Collections) {
 	// Get all of the outdated reservations that should be finished (charging time has passed, they haven't stopped charging, but they started charging)
-	reservations, err := reservationsCollection.Find(context.Background(), bson.M{"chargingTime": bson.M{"$lte": time.Now()}, "hasStartedCharging": true, "hasFinishedCharging": false})
+	reservations, err := collections.Reservations.Find(context.Background(), bson.M{"chargingTime": bson.M{"$lte": time.Now()}, "hasStartedCharging": true, "hasFinishedCharging": false})
 	if err != nil {
 		fmt.Println("Error getting reservations: ", err)
 		return
@@ -200,13 +869,18 @@ func checkFinishedReservations(reservationsCollection, chargepointsCollection *m
 		}
 
 		// Set it to a finished reservation
-		_, err = reservationsCollection.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{"hasFinishedCharging": true}})
+		_, err = collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": reservation.ID}, bson.M{"$set": bson.M{"hasFinishedCharging": true}})
 		if err != nil {
 			fmt.Println("Error updating reservation charging status: ", err)
 			continue
 		}
 
-		chargepoint, err := FindChargepointByID(reservation.Chargepoint, chargepointsCollection)
+		err = stopSession(reservation.Chargepoint, reservation.Connector, 0, StopReasonReservationEnded, collections)
+		if err != nil {
+			fmt.Println("Error stopping charging session: ", err)
+		}
+
+		chargepoint, err := FindChargepointByID(reservation.Chargepoint, collections.Chargepoints)
 		if err != nil {
 			fmt.Println("Error getting chargepoint: ", err)
 			continue
@@ -215,7 +889,7 @@ func checkFinishedReservations(reservationsCollection, chargepointsCollection *m
 		// Set the connector to "Available" again, ready for future reservations
 		chargepoint.Connectors[reservation.Connector-1].State = "Available"
 
-		_, err = chargepointsCollection.UpdateOne(context.Background(), bson.M{"_id": chargepoint.ID}, bson.M{"$set": bson.M{"connectors": chargepoint.Connectors}})
+		_, err = collections.Chargepoints.UpdateOne(context.Background(), bson.M{"_id": chargepoint.ID}, bson.M{"$set": bson.M{"connectors": chargepoint.Connectors}})
 		if err != nil {
 			fmt.Println("Error updating chargepoint connector state: ", err)
 			continue
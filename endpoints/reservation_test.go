@@ -15,6 +15,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func TestReservations(t *testing.T) {
@@ -27,14 +28,15 @@ func TestReservations(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
 	}
-	chargepointsCollection := client.Database("TestDB").Collection("chargepoints")
-	usersCollection := client.Database("TestDB").Collection("users")
-	reservationsCollection := client.Database("TestDB").Collection("reservations")
+	collections := NewCollections(client.Database("TestDB"))
+	chargepointsCollection := collections.Chargepoints
+	usersCollection := collections.Users
+	reservationsCollection := collections.Reservations
 
 	router := gin.Default()
 
 	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
-		CreateReservation(c, reservationsCollection, chargepointsCollection, usersCollection)
+		CreateReservation(c, collections)
 	})
 
 	usersCollection.InsertOne(context.Background(), models.User{ID: "customer", Name: "Customer"})
@@ -91,6 +93,10 @@ func TestReservations(t *testing.T) {
 		if err != nil {
 			t.Fatalf("Failed to clear collection:\n%v", err)
 		}
+		err = db.ClearCollection(collections.NoShows)
+		if err != nil {
+			t.Fatalf("Failed to clear collection:\n%v", err)
+		}
 		client.Disconnect(context.Background())
 	}()
 
@@ -121,8 +127,9 @@ func TestReservations(t *testing.T) {
 			t.Fatalf("Could not insert chargepoint:\n%v", err)
 		}
 
+		reservationID := primitive.NewObjectID()
 		_, err = reservationsCollection.InsertOne(context.Background(), models.Reservation{
-			ID:                  123,
+			ID:                  reservationID,
 			Chargepoint:         "chargingTestChargepoint",
 			Connector:           1,
 			UserID:              "customer",
@@ -134,10 +141,10 @@ func TestReservations(t *testing.T) {
 			t.Fatalf("Could not insert reservation:\n%v", err)
 		}
 
-		checkNonChargingReservations(reservationsCollection, chargepointsCollection)
+		checkNonChargingReservations(collections)
 
 		var updatedReservation models.Reservation
-		err = reservationsCollection.FindOne(context.Background(), bson.M{"_id": 123}).Decode(&updatedReservation)
+		err = reservationsCollection.FindOne(context.Background(), bson.M{"_id": reservationID}).Decode(&updatedReservation)
 		if err != nil {
 			t.Fatalf("Could not find non-charging reservation:\n%v", err)
 		}
@@ -155,6 +162,41 @@ func TestReservations(t *testing.T) {
 		if updatedChargepoint.Connectors[0].State != "Available" {
 			t.Errorf("Expected the chargepoint connector state to be %s, but received %s", "Available", updatedChargepoint.Connectors[0].State)
 		}
+
+		var noShow models.NoShow
+		err = collections.NoShows.FindOne(context.Background(), bson.M{"reservationId": reservationID}).Decode(&noShow)
+		if err != nil {
+			t.Fatalf("Could not find the recorded no-show:\n%v", err)
+		}
+
+		if noShow.UserID != "customer" {
+			t.Errorf("Expected the no-show to belong to %s, but it belongs to %s", "customer", noShow.UserID)
+		}
+	})
+
+	t.Run("NeverReserved", func(t *testing.T) {
+		// Someone else is still charging, so the connector never was reserved for the reservation
+		chargepointsCollection.InsertOne(context.Background(), models.Chargepoint{ID: "occupiedTestChargepoint", Connectors: []models.Connector{
+			{ID: 1, State: "Charging"},
+		}})
+
+		reservationID := primitive.NewObjectID()
+		reservationsCollection.InsertOne(context.Background(), models.Reservation{ID: reservationID, Chargepoint: "occupiedTestChargepoint", Connector: 1, UserID: "customer", ExpiryTime: time.Now().Add(-time.Hour)})
+
+		checkNonChargingReservations(collections)
+
+		updated, _ := FindReservationByID(reservationID.Hex(), reservationsCollection)
+		if !updated.HasFinishedCharging {
+			t.Error("Expected the reservation to have a finished charging state")
+		}
+
+		if count, _ := collections.NoShows.CountDocuments(context.Background(), bson.M{"reservationId": reservationID}); count != 0 {
+			t.Errorf("Expected no no-show to be recorded, but %d were", count)
+		}
+
+		if chargepoint, _ := FindChargepointByID("occupiedTestChargepoint", chargepointsCollection); chargepoint.Connectors[0].State != "Charging" {
+			t.Errorf("Expected the connector to keep charging, but it's %s", chargepoint.Connectors[0].State)
+		}
 	})
 
 	t.Run("CheckFinishedReservations", func(t *testing.T) {
@@ -165,8 +207,9 @@ func TestReservations(t *testing.T) {
 			},
 		}})
 
+		reservationID := primitive.NewObjectID()
 		_, err := reservationsCollection.InsertOne(context.Background(), models.Reservation{
-			ID:                  1234,
+			ID:                  reservationID,
 			Chargepoint:         "finishedTestChargepoint",
 			Connector:           1,
 			UserID:              "customer",
@@ -178,10 +221,10 @@ func TestReservations(t *testing.T) {
 			t.Fatalf("Could not insert non-charging reservation:\n%v", err)
 		}
 
-		checkFinishedReservations(reservationsCollection, chargepointsCollection)
+		checkFinishedReservations(collections)
 
 		var updatedReservation models.Reservation
-		err = reservationsCollection.FindOne(context.Background(), bson.M{"_id": 1234}).Decode(&updatedReservation)
+		err = reservationsCollection.FindOne(context.Background(), bson.M{"_id": reservationID}).Decode(&updatedReservation)
 		if err != nil {
 			t.Fatalf("Could not find non-charging reservation:\n%v", err)
 		}
@@ -0,0 +1,336 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// defaultReservationPolicy holds the rules for connectors whose site, chargepoint and connector don't set them
+func defaultReservationPolicy() models.ConnectorPolicy {
+	return models.ConnectorPolicy{
+		GraceMinutes: 10,
+		MinMinutes:   30,
+		MaxMinutes:   180,
+		HorizonDays:  bookingHorizonDays(),
+	}
+}
+
+// inheritPolicy returns the policy with the fields the more specific policy sets overridden
+func inheritPolicy(policy models.ConnectorPolicy, specific *models.ReservationPolicy) models.ConnectorPolicy {
+	if specific == nil {
+		return policy
+	}
+
+	if specific.GraceMinutes != nil {
+		policy.GraceMinutes = *specific.GraceMinutes
+	}
+	if specific.MinMinutes != nil {
+		policy.MinMinutes = *specific.MinMinutes
+	}
+	if specific.MaxMinutes != nil {
+		policy.MaxMinutes = *specific.MaxMinutes
+	}
+	if specific.HorizonDays != nil {
+		policy.HorizonDays = *specific.HorizonDays
+	}
+	if specific.BufferMinutes != nil {
+		policy.BufferMinutes = *specific.BufferMinutes
+	}
+	if len(specific.AllowedOrganizations) > 0 {
+		policy.AllowedOrganizations = specific.AllowedOrganizations
+	}
+
+	return policy
+}
+
+// connectorPolicy combines the policies of the connector, its chargepoint and the chargepoint's site, which is empty when it has none
+func connectorPolicy(site models.Site, chargepoint models.Chargepoint, connectorNumber int) models.ConnectorPolicy {
+	policy := inheritPolicy(defaultReservationPolicy(), site.ReservationPolicy)
+	policy = inheritPolicy(policy, chargepoint.ReservationPolicy)
+	return inheritPolicy(policy, chargepoint.Connectors[connectorNumber-1].ReservationPolicy)
+}
+
+// resolveReservationPolicy combines the policies of the connector, its chargepoint and its site into the rules that apply to reserving it
+func resolveReservationPolicy(chargepoint models.Chargepoint, connectorNumber int, collections Collections) (models.ConnectorPolicy, error) {
+	var site models.Site
+	if chargepoint.SiteID != "" {
+		var err error
+		site, err = FindSiteByID(chargepoint.SiteID, collections.Sites)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return models.ConnectorPolicy{}, err
+		}
+	}
+
+	return connectorPolicy(site, chargepoint, connectorNumber), nil
+}
+
+// connectorPolicies resolves the policies of all of the chargepoints' connectors at once, by chargepoint and connector
+func connectorPolicies(chargepoints []models.Chargepoint, collections Collections) (map[string]map[int]models.ConnectorPolicy, error) {
+	siteIDs := bson.A{}
+	for _, chargepoint := range chargepoints {
+		if chargepoint.SiteID != "" {
+			siteIDs = append(siteIDs, chargepoint.SiteID)
+		}
+	}
+
+	cursor, err := collections.Sites.Find(context.Background(), bson.M{"_id": bson.M{"$in": siteIDs}})
+	if err != nil {
+		return nil, err
+	}
+
+	var sites []models.Site
+	if err := cursor.All(context.Background(), &sites); err != nil {
+		return nil, err
+	}
+
+	sitesByID := map[string]models.Site{}
+	for _, site := range sites {
+		sitesByID[site.ID] = site
+	}
+
+	policies := map[string]map[int]models.ConnectorPolicy{}
+	for _, chargepoint := range chargepoints {
+		policies[chargepoint.ID] = map[int]models.ConnectorPolicy{}
+		for i, connector := range chargepoint.Connectors {
+			policies[chargepoint.ID][connector.ID] = connectorPolicy(sitesByID[chargepoint.SiteID], chargepoint, i+1)
+		}
+	}
+
+	return policies, nil
+}
+
+// checkDuration returns why the reservation time doesn't fit the policy, or an empty string when it does
+func checkDuration(policy models.ConnectorPolicy, minutes int) string {
+	if minutes < policy.MinMinutes || minutes > policy.MaxMinutes {
+		return fmt.Sprintf("The reservation time must be between %d and %d minutes", policy.MinMinutes, policy.MaxMinutes)
+	}
+
+	return ""
+}
+
+// allowsUser tells whether the policy lets the user reserve
+func allowsUser(policy models.ConnectorPolicy, user models.User) bool {
+	if len(policy.AllowedOrganizations) == 0 {
+		return true
+	}
+
+	for _, organizationID := range policy.AllowedOrganizations {
+		if organizationID == user.OrganizationID {
+			return true
+		}
+	}
+
+	return false
+}
+
+// policyProblem returns why no reservation could be made under the combined policy, or an empty string when one can
+func policyProblem(policy models.ConnectorPolicy) string {
+	if policy.MaxMinutes <= 0 {
+		return "The longest reservation time must be positive"
+	}
+	if policy.MinMinutes > policy.MaxMinutes {
+		return fmt.Sprintf("The shortest reservation time (%d minutes) can't be longer than the longest (%d minutes)", policy.MinMinutes, policy.MaxMinutes)
+	}
+
+	return ""
+}
+
+// chargepointPolicyProblem checks the policy the chargepoint's own connectors inherit, and the combined policy of each of its connectors
+func chargepointPolicyProblem(site models.Site, chargepoint models.Chargepoint) string {
+	policy := inheritPolicy(inheritPolicy(defaultReservationPolicy(), site.ReservationPolicy), chargepoint.ReservationPolicy)
+	if problem := policyProblem(policy); problem != "" {
+		return problem
+	}
+
+	for i, connector := range chargepoint.Connectors {
+		if problem := policyProblem(connectorPolicy(site, chargepoint, i+1)); problem != "" {
+			return fmt.Sprintf("%s, for connector %d of %s", problem, connector.ID, chargepoint.ID)
+		}
+	}
+
+	return ""
+}
+
+// SetReservationPolicy godoc
+// @Summary Set the reservation policy of a site, chargepoint or connector
+// @Description Give either a site ID, a chargepoint ID, or a chargepoint ID with a connector ID. The fields a connector's policy sets take precedence over its chargepoint's, which take precedence over its site's, the ones left out are inherited, and 0 overrides like any other value. The combined policy of every connector the change reaches is checked, so a minimum above an inherited maximum is rejected. Connectors without any policy can be reserved for 30 to 180 minutes, up to BOOKING_HORIZON_DAYS ahead, with a 10 minute grace period, no buffer and by everyone. Without a policy, the existing one is removed. The grace period is locked in when a reservation is made.
+// @Tags Operators
+// @Accept json
+// @Produce json
+// @Param body body SetReservationPolicyRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /policies [post]
+func SetReservationPolicy(c *gin.Context, collections Collections) {
+	var req SetReservationPolicyRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if (req.SiteID == "") == (req.ChargepointID == "") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Either a site or a chargepoint must be given"})
+		return
+	}
+
+	if policy := req.Policy; policy != nil {
+		for _, value := range []*int{policy.GraceMinutes, policy.MinMinutes, policy.MaxMinutes, policy.HorizonDays, policy.BufferMinutes} {
+			if value != nil && *value < 0 {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The policy's values can't be negative"})
+				return
+			}
+		}
+	}
+
+	update := bson.M{"$set": bson.M{"reservationPolicy": req.Policy}}
+	if req.Policy == nil {
+		update = bson.M{"$unset": bson.M{"reservationPolicy": ""}}
+	}
+
+	if req.SiteID != "" {
+		site, err := FindSiteByID(req.SiteID, collections.Sites)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Site does not exist"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not set the policy"})
+			return
+		}
+
+		cursor, err := collections.Chargepoints.Find(context.Background(), bson.M{"siteId": site.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+			return
+		}
+
+		var chargepoints []models.Chargepoint
+		if err := cursor.All(context.Background(), &chargepoints); err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+			return
+		}
+
+		// The site's own policy is checked too, it's what chargepoints added later start with
+		site.ReservationPolicy = req.Policy
+		problem := policyProblem(inheritPolicy(defaultReservationPolicy(), site.ReservationPolicy))
+		for _, chargepoint := range chargepoints {
+			if problem != "" {
+				break
+			}
+			problem = chargepointPolicyProblem(site, chargepoint)
+		}
+
+		if problem != "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: problem})
+			return
+		}
+
+		_, err = collections.Sites.UpdateOne(context.Background(), bson.M{"_id": site.ID}, update)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not set the policy"})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.MessageResponse{Message: "Reservation policy set"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(req.ChargepointID, collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	if req.ConnectorID != 0 {
+		if req.ConnectorID < 0 || req.ConnectorID > len(chargepoint.Connectors) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors"})
+			return
+		}
+
+		chargepoint.Connectors[req.ConnectorID-1].ReservationPolicy = req.Policy
+		update = bson.M{"$set": bson.M{"connectors": chargepoint.Connectors}}
+	} else {
+		chargepoint.ReservationPolicy = req.Policy
+	}
+
+	var site models.Site
+	if chargepoint.SiteID != "" {
+		site, err = FindSiteByID(chargepoint.SiteID, collections.Sites)
+		if err != nil && err != mongo.ErrNoDocuments {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not set the policy"})
+			return
+		}
+	}
+
+	if problem := chargepointPolicyProblem(site, chargepoint); problem != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: problem})
+		return
+	}
+
+	_, err = collections.Chargepoints.UpdateOne(context.Background(), bson.M{"_id": chargepoint.ID}, update)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not set the policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Reservation policy set"})
+}
+
+type SetReservationPolicyRequest struct {
+	SiteID        string `json:"siteId"`
+	ChargepointID string `json:"chargepointId"`
+	ConnectorID   int    `json:"connectorId"`
+	// Optional, the policy is removed without it
+	Policy *models.ReservationPolicy `json:"policy"`
+}
+
+// GetReservationPolicy godoc
+// @Summary Get the reservation policy that applies to a connector
+// @Description Combines the policies of the connector, its chargepoint and its site with the defaults, so every field is filled in.
+// @Tags Reservations
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param connectorID path int true "Connector ID"
+// @Success 200 {object} models.ConnectorPolicy
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /policies/{chargepointID}/{connectorID} [get]
+func GetReservationPolicy(c *gin.Context, collections Collections) {
+	chargepoint, err := FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Chargepoint not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	connectorNumber, err := strconv.Atoi(c.Param("coID"))
+	if err != nil || connectorNumber <= 0 || connectorNumber > len(chargepoint.Connectors) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors"})
+		return
+	}
+
+	policy, err := resolveReservationPolicy(chargepoint, connectorNumber, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connector's reservation policy"})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
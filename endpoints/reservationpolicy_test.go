@@ -0,0 +1,138 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestReservationPolicy(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/policies", func(c *gin.Context) {
+		SetReservationPolicy(c, collections)
+	})
+
+	router.GET("/policies/:cpID/:coID", func(c *gin.Context) {
+		GetReservationPolicy(c, collections)
+	})
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Sites, collections.Chargepoints, collections.Reservations} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "policyDriver", Name: "Driver"})
+	collections.Users.InsertOne(context.Background(), models.User{ID: "policyFleetDriver", Name: "Fleet driver", OrganizationID: "policyFleet"})
+	collections.Sites.InsertOne(context.Background(), models.Site{ID: "policyDepot", Name: "Depot"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "policyChargepoint", SiteID: "policyDepot", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "Type2"},
+		{ID: 2, State: "Available", PlugType: "Type2"},
+	}})
+
+	request := func(method, endpoint string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	setTests := []struct {
+		name string
+		body any
+		code int
+	}{
+		{name: "NoTarget", body: map[string]any{"policy": map[string]any{"graceMinutes": 30}}, code: http.StatusBadRequest},
+		{name: "UnknownSite", body: map[string]any{"siteId": "nowhere", "policy": map[string]any{"graceMinutes": 30}}, code: http.StatusBadRequest},
+		{name: "MinAboveMax", body: map[string]any{"siteId": "policyDepot", "policy": map[string]any{"minMinutes": 120, "maxMinutes": 60}}, code: http.StatusBadRequest},
+		{name: "MinAboveInheritedMax", body: map[string]any{"siteId": "policyDepot", "policy": map[string]any{"minMinutes": 240}}, code: http.StatusBadRequest},
+		{name: "MaxBelowInheritedMin", body: map[string]any{"chargepointId": "policyChargepoint", "policy": map[string]any{"maxMinutes": 20}}, code: http.StatusBadRequest},
+		{name: "NegativeValue", body: map[string]any{"chargepointId": "policyChargepoint", "policy": map[string]any{"bufferMinutes": -5}}, code: http.StatusBadRequest},
+		{name: "Site", body: map[string]any{"siteId": "policyDepot", "policy": map[string]any{"graceMinutes": 30, "maxMinutes": 720}}, code: http.StatusOK},
+		{name: "Connector", body: map[string]any{"chargepointId": "policyChargepoint", "connectorId": 2, "policy": map[string]any{"graceMinutes": 0, "allowedOrganizations": []string{"policyFleet"}}}, code: http.StatusOK},
+	}
+
+	for _, test := range setTests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := request("POST", "/policies", test.body)
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			}
+		})
+	}
+
+	t.Run("Inherited", func(t *testing.T) {
+		var policy models.ConnectorPolicy
+		json.Unmarshal(request("GET", "/policies/policyChargepoint/2", nil).Body.Bytes(), &policy)
+
+		if policy.GraceMinutes != 0 || policy.MinMinutes != 30 || policy.MaxMinutes != 720 || len(policy.AllowedOrganizations) != 1 {
+			t.Errorf("Expected the connector to inherit the site's policy and the defaults, but with no grace period, but received %+v", policy)
+		}
+
+		json.Unmarshal(request("GET", "/policies/policyChargepoint/1", nil).Body.Bytes(), &policy)
+		if policy.GraceMinutes != 30 {
+			t.Errorf("Expected the other connector to keep the site's grace period, but received %+v", policy)
+		}
+	})
+
+	reserveTests := []struct {
+		name      string
+		connector string
+		body      any
+		code      int
+	}{
+		{name: "LongerThanDefault", connector: "1", body: map[string]any{"userId": "policyDriver", "minutes": 600}, code: http.StatusOK},
+		{name: "NotInOrganization", connector: "2", body: map[string]any{"userId": "policyDriver", "minutes": 60}, code: http.StatusForbidden},
+		{name: "InOrganization", connector: "2", body: map[string]any{"userId": "policyFleetDriver", "minutes": 60}, code: http.StatusOK},
+	}
+
+	for _, test := range reserveTests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := request("POST", "/reservations/policyChargepoint/"+test.connector, test.body)
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			}
+		})
+	}
+
+	t.Run("GracePeriod", func(t *testing.T) {
+		var reservation models.Reservation
+		collections.Reservations.FindOne(context.Background(), bson.M{"userId": "policyDriver"}).Decode(&reservation)
+
+		if grace := reservation.ExpiryTime.Sub(reservation.StartTime); grace != 30*time.Minute {
+			t.Errorf("Expected a grace period of 30 minutes, but received %v", grace)
+		}
+	})
+}
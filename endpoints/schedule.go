@@ -0,0 +1,174 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"reservations/loadmanagement"
+	"reservations/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Length of the periods a charging schedule is planned in
+const scheduleSlot = 15 * time.Minute
+
+// energyPrice is the tariff's price per kWh at the time, in cents
+func energyPrice(tariff models.Tariff, t time.Time) float64 {
+	if i := bandIndex(tariff, t); i != -1 {
+		return float64(tariff.Bands[i].PerKWh)
+	}
+
+	return float64(tariff.PerKWh)
+}
+
+// GetChargingSchedule godoc
+// @Summary Get the charging schedule of a reservation
+// @Description Plans the power the connector should deliver from now (or the start of the reservation) until the reservation ends or the vehicle departs, whichever is first. The energy the vehicle still needs is charged when the tariff's energy price is lowest, without going over the connector's and vehicle's power or what's left of the site's capacity. Without a target energy, the schedule charges at full power. The schedule is shaped like an OCPP SetChargingProfile schedule with limits in W, so it can be pushed to chargers as-is.
+// @Tags Reservations
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} models.ChargingSchedule
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /reservations/{id}/schedule [get]
+func GetChargingSchedule(reservation models.Reservation, collections Collections) (models.ChargingSchedule, error) {
+	schedule := models.ChargingSchedule{
+		ReservationID:    reservation.ID,
+		ChargingRateUnit: "W",
+		Periods:          []models.ChargingSchedulePeriod{},
+		TargetEnergy:     reservation.TargetEnergy,
+	}
+
+	chargepoint, err := FindChargepointByID(reservation.Chargepoint, collections.Chargepoints)
+	if err != nil {
+		return models.ChargingSchedule{}, err
+	}
+	connector := chargepoint.Connectors[reservation.Connector-1]
+
+	tariff, err := findTariffOrFree(reservation.TariffID, collections.Tariffs)
+	if err != nil {
+		return models.ChargingSchedule{}, err
+	}
+	schedule.Currency = tariff.Currency
+	if schedule.Currency == "" {
+		schedule.Currency = defaultCurrency()
+	}
+
+	start := reservation.StartTime
+	if now := time.Now(); now.After(start) {
+		start = now
+	}
+	end := reservation.ChargingTime
+	if !reservation.Departure.IsZero() && reservation.Departure.Before(end) {
+		end = reservation.Departure
+	}
+	schedule.StartSchedule = start
+
+	if reservation.HasFinishedCharging || !end.After(start) {
+		schedule.Warnings = append(schedule.Warnings, "The reservation has ended")
+		return schedule, nil
+	}
+	schedule.Duration = int(end.Sub(start).Seconds())
+
+	// Only what the vehicle still needs is planned
+	var session models.ChargingSession
+	err = collections.Sessions.FindOne(context.Background(), bson.M{"reservationId": reservation.ID, "status": SessionCharging}).Decode(&session)
+	if err == nil && schedule.TargetEnergy > 0 {
+		schedule.TargetEnergy = roundQuantity(schedule.TargetEnergy - (session.MeterStop-session.MeterStart)/1000)
+		if schedule.TargetEnergy <= 0 {
+			schedule.TargetEnergy = 0
+			schedule.Warnings = append(schedule.Warnings, "The vehicle already got its target energy")
+			schedule.Periods = append(schedule.Periods, models.ChargingSchedulePeriod{StartPeriod: 0, Limit: 0})
+			return schedule, nil
+		}
+	} else if err != nil && err != mongo.ErrNoDocuments {
+		return models.ChargingSchedule{}, err
+	}
+
+	connector.MaxPower = connectorDemand(connector, reservation.PowerLimit)
+	maxPower := connector.MaxPower
+	if reservation.VehicleID != "" {
+		vehicle, err := FindVehicleByID(reservation.VehicleID, collections.Vehicles)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return models.ChargingSchedule{}, err
+		}
+		if err == nil {
+			maxPower = chargingPower(vehicle, connector)
+		}
+	}
+
+	if maxPower <= 0 {
+		schedule.Warnings = append(schedule.Warnings, "The connector's power is unknown, so charging can't be planned")
+		return schedule, nil
+	}
+
+	var site *models.Site
+	var siteChargepoints []models.Chargepoint
+	if chargepoint.SiteID != "" {
+		found, err := FindSiteByID(chargepoint.SiteID, collections.Sites)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return models.ChargingSchedule{}, err
+		}
+		if err == nil && found.MaxPower > 0 {
+			site = &found
+			siteChargepoints, err = findSiteChargepoints(site.ID, collections.Chargepoints)
+			if err != nil {
+				return models.ChargingSchedule{}, err
+			}
+		}
+	}
+
+	slots := []loadmanagement.Slot{}
+	for slotStart := start; slotStart.Before(end); slotStart = slotStart.Add(scheduleSlot) {
+		slot := loadmanagement.Slot{Start: slotStart, Duration: scheduleSlot, MaxPower: maxPower, Price: energyPrice(tariff, slotStart)}
+		if end.Sub(slotStart) < scheduleSlot {
+			slot.Duration = end.Sub(slotStart)
+		}
+
+		// Other reservations at the site take their share of its capacity first
+		if site != nil {
+			load, err := projectedLoad(siteChargepoints, chargepoint.ID, reservation.Connector, slotStart, slotStart.Add(slot.Duration), collections.Reservations)
+			if err != nil {
+				return models.ChargingSchedule{}, err
+			}
+
+			if headroom := site.MaxPower - load; headroom < slot.MaxPower {
+				slot.MaxPower = headroom
+			}
+			if slot.MaxPower < 0 {
+				slot.MaxPower = 0
+			}
+		}
+
+		slots = append(slots, slot)
+	}
+
+	power, energy := loadmanagement.Schedule(slots, schedule.TargetEnergy)
+	schedule.PlannedEnergy = roundQuantity(energy)
+
+	cost := 0.0
+	for i, slot := range slots {
+		cost += power[i] * slot.Duration.Hours() * slot.Price
+
+		period := models.ChargingSchedulePeriod{
+			StartPeriod: int(slot.Start.Sub(start).Seconds()),
+			Limit:       roundQuantity(power[i] * 1000),
+			PricePerKWh: slot.Price,
+		}
+
+		// Consecutive slots with the same limit and price make up a single period
+		if last := len(schedule.Periods) - 1; last >= 0 && schedule.Periods[last].Limit == period.Limit && schedule.Periods[last].PricePerKWh == period.PricePerKWh {
+			continue
+		}
+		schedule.Periods = append(schedule.Periods, period)
+	}
+	schedule.EnergyCost = roundCents(cost)
+
+	if schedule.TargetEnergy > 0 && schedule.PlannedEnergy < schedule.TargetEnergy {
+		schedule.Warnings = append(schedule.Warnings, fmt.Sprintf("The vehicle can only get %.1f of the %.1f kWh it needs before %s", schedule.PlannedEnergy, schedule.TargetEnergy, end.Format("15:04")))
+	}
+
+	return schedule, nil
+}
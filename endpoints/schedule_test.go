@@ -0,0 +1,123 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestChargingSchedule(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Tariffs, collections.Chargepoints, collections.Reservations} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	// Energy is cheap during the second hour from now
+	now := time.Now()
+	collections.Users.InsertOne(context.Background(), models.User{ID: "scheduleUser", Name: "Fleet driver"})
+	collections.Tariffs.InsertOne(context.Background(), models.Tariff{ID: "scheduleTariff", Name: "Off-peak", Currency: "EUR", PerKWh: 40, Bands: []models.TariffBand{
+		{Name: "Off-peak", Start: now.Add(time.Hour).Format("15:04"), End: now.Add(2 * time.Hour).Format("15:04"), PerKWh: 20},
+	}})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "scheduleChargepoint", TariffID: "scheduleTariff", Connectors: []models.Connector{
+		{ID: 1, State: "Reserved", MaxPower: 11},
+		{ID: 2, State: "Available", MaxPower: 11},
+	}})
+
+	request := func(endpoint string, body any) int {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	tests := []struct {
+		name string
+		body any
+		code int
+	}{
+		{name: "PastDeparture", body: map[string]any{"userId": "scheduleUser", "departure": now.Add(-time.Hour), "targetEnergy": 10}, code: http.StatusBadRequest},
+		{name: "DepartureAfterReservation", body: map[string]any{"userId": "scheduleUser", "minutes": 60, "departure": now.Add(2 * time.Hour), "targetEnergy": 10}, code: http.StatusBadRequest},
+		{name: "NegativeTarget", body: map[string]any{"userId": "scheduleUser", "minutes": 60, "targetEnergy": -1}, code: http.StatusBadRequest},
+		{name: "ReserveUntilDeparture", body: map[string]any{"userId": "scheduleUser", "departure": now.Add(2 * time.Hour), "targetEnergy": 10}, code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := request("/reservations/scheduleChargepoint/2", test.body)
+			if code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, code)
+			}
+		})
+	}
+
+	t.Run("CheapestHours", func(t *testing.T) {
+		reservation := models.Reservation{
+			ID:           primitive.NewObjectID(),
+			Chargepoint:  "scheduleChargepoint",
+			Connector:    1,
+			UserID:       "scheduleUser",
+			TariffID:     "scheduleTariff",
+			StartTime:    now,
+			ExpiryTime:   now.Add(10 * time.Minute),
+			ChargingTime: now.Add(3 * time.Hour),
+			Minutes:      180,
+			TargetEnergy: 11,
+		}
+		collections.Reservations.InsertOne(context.Background(), reservation)
+
+		schedule, err := GetChargingSchedule(reservation, collections)
+		if err != nil {
+			t.Fatalf("Could not plan the schedule:\n%v", err)
+		}
+
+		// Nothing in the first hour, the connector's full power during the off-peak hour, then nothing again
+		limits := []float64{0, 11000, 0}
+		if len(schedule.Periods) != len(limits) {
+			t.Fatalf("Expected %d periods, but received %v", len(limits), schedule.Periods)
+		}
+
+		for i, period := range schedule.Periods {
+			if period.Limit != limits[i] {
+				t.Errorf("Expected period %d to be limited to %.0f W, but received %.0f", i, limits[i], period.Limit)
+			}
+		}
+
+		if schedule.PlannedEnergy != 11 || schedule.EnergyCost != 220 {
+			t.Errorf("Expected 11 kWh for 220, but received %.2f kWh for %d", schedule.PlannedEnergy, schedule.EnergyCost)
+		}
+	})
+}
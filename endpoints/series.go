@@ -0,0 +1,500 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"reservations/recurrence"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// bookOccurrences books the occurrences of the series that haven't been booked yet and start within the booking horizon, recording for each of them the reservation or why it couldn't be made
+func bookOccurrences(series *models.ReservationSeries, collections Collections) error {
+	rule, err := recurrence.Parse(series.Rule)
+	if err != nil {
+		return err
+	}
+
+	// Occurrences starting within the next minute would start right away instead of being booked ahead
+	now := time.Now()
+	from := series.GeneratedUntil
+	if from.Before(now.Add(time.Minute)) {
+		from = now.Add(time.Minute)
+	}
+
+	chargepoint, err := FindChargepointByID(series.Chargepoint, collections.Chargepoints)
+	if err != nil {
+		return err
+	}
+
+	policy, err := resolveReservationPolicy(chargepoint, series.Connector, collections)
+	if err != nil {
+		return err
+	}
+	until := now.AddDate(0, 0, policy.HorizonDays)
+
+	occurrences := []models.SeriesOccurrence{}
+	starts := rule.Between(series.Start, from, until)
+	if len(starts) > 0 {
+		for _, start := range starts {
+			start := start
+			occurrence := models.SeriesOccurrence{Start: start}
+
+			reservation, _, reserveErr := reserve(ReservationRequest{
+				UserID:        series.UserID,
+				Minutes:       series.Minutes,
+				VehicleID:     series.VehicleID,
+				PaymentMethod: series.PaymentMethod,
+				StartTime:     &start,
+				seriesID:      series.ID.Hex(),
+			}, chargepoint, series.Connector, false, collections)
+			if reserveErr != nil {
+				occurrence.Error = reserveErr.Error()
+			} else {
+				occurrence.ReservationID = reservation.ID
+			}
+
+			occurrences = append(occurrences, occurrence)
+		}
+	}
+
+	series.GeneratedUntil = until
+	series.Occurrences = append(series.Occurrences, occurrences...)
+
+	_, err = collections.Series.UpdateOne(context.Background(), bson.M{"_id": series.ID}, bson.M{
+		"$set":  bson.M{"generatedUntil": until},
+		"$push": bson.M{"occurrences": bson.M{"$each": occurrences}},
+	})
+	return err
+}
+
+// cancelOccurrences cancels the reservations of the series that haven't started yet
+func cancelOccurrences(series models.ReservationSeries, collections Collections) error {
+	cursor, err := collections.Reservations.Find(context.Background(), bson.M{
+		"seriesId":            series.ID.Hex(),
+		"startTime":           bson.M{"$gt": time.Now()},
+		"hasStartedCharging":  false,
+		"hasFinishedCharging": false,
+	})
+	if err != nil {
+		return err
+	}
+
+	var reservations []models.Reservation
+	if err := cursor.All(context.Background(), &reservations); err != nil {
+		return err
+	}
+
+	for _, reservation := range reservations {
+		if err := cancelReservation(reservation, collections); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extendSeries books the occurrences of every active series that came within the booking horizon
+func extendSeries(collections Collections) {
+	cursor, err := collections.Series.Find(context.Background(), bson.M{"cancelled": false})
+	if err != nil {
+		fmt.Println("Error getting reservation series: ", err)
+		return
+	}
+
+	var series []models.ReservationSeries
+	if err := cursor.All(context.Background(), &series); err != nil {
+		fmt.Println("Error decoding reservation series: ", err)
+		return
+	}
+
+	for i := range series {
+		if err := bookOccurrences(&series[i], collections); err != nil {
+			fmt.Println("Error booking series occurrences: ", err)
+		}
+	}
+}
+
+// CreateSeries godoc
+// @Summary Create a recurring reservation
+// @Description Reserves the connector repeatedly, following an iCalendar RRULE with a DAILY or WEEKLY frequency, an optional INTERVAL and BYDAY, and an UNTIL date or COUNT. For example "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR" reserves it every weekday at the time of the first occurrence. Occurrences are booked as ordinary reservations once they come within the booking horizon of the connector's reservation policy, each of them is checked on its own, and the series lists which ones couldn't be booked and why.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param connectorID path int true "Connector ID"
+// @Param body body SeriesRequest true "Request body"
+// @Success 200 {object} models.ReservationSeries
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /series/{chargepointID}/{connectorID} [post]
+func CreateSeries(c *gin.Context, collections Collections) {
+	var req SeriesRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	connectorNumber, err := strconv.Atoi(c.Param("coID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector must be a number"})
+		return
+	}
+
+	if connectorNumber <= 0 || connectorNumber > len(chargepoint.Connectors) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors"})
+		return
+	}
+
+	_, err = FindUserByID(req.UserID, collections.Users)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch users"})
+		return
+	}
+
+	if _, err := recurrence.Parse(req.Rule); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid recurrence rule: %v", err)})
+		return
+	}
+
+	if !req.Start.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The first occurrence must start in the future"})
+		return
+	}
+
+	policy, err := resolveReservationPolicy(chargepoint, connectorNumber, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connector's reservation policy"})
+		return
+	}
+
+	if message := checkDuration(policy, req.Minutes); message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: message})
+		return
+	}
+
+	series := models.ReservationSeries{
+		UserID:        req.UserID,
+		Chargepoint:   chargepoint.ID,
+		Connector:     connectorNumber,
+		Rule:          req.Rule,
+		Start:         req.Start,
+		Minutes:       req.Minutes,
+		VehicleID:     req.VehicleID,
+		PaymentMethod: req.PaymentMethod,
+		CreatedAt:     time.Now(),
+		Occurrences:   []models.SeriesOccurrence{},
+	}
+
+	result, err := collections.Series.InsertOne(context.Background(), series)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create the series"})
+		return
+	}
+	series.ID = result.InsertedID.(primitive.ObjectID)
+
+	if err := bookOccurrences(&series, collections); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not book the series' occurrences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+type SeriesRequest struct {
+	UserID string `json:"userId"`
+	// When the first occurrence starts
+	Start   time.Time `json:"start"`
+	Minutes int       `json:"minutes"`
+	// For example "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR;UNTIL=20231231" or "FREQ=WEEKLY;BYDAY=SA;COUNT=10"
+	Rule string `json:"rule"`
+	// Optional, see POST /reservations/{chargepointID}/{connectorID}
+	VehicleID     string `json:"vehicleId"`
+	PaymentMethod string `json:"paymentMethod"`
+}
+
+// FindSeriesByID godoc
+// @Summary Get a recurring reservation by ID
+// @Description Includes every occurrence booked so far, with its reservation or why it couldn't be booked.
+// @Tags Reservations
+// @Produce json
+// @Param id path string true "Series ID"
+// @Success 200 {object} models.ReservationSeries
+// @Failure 404 {object} models.ErrorResponse
+// @Router /series/{id} [get]
+func FindSeriesByID(id string, collection *mongo.Collection) (models.ReservationSeries, error) {
+	var series models.ReservationSeries
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.ReservationSeries{}, mongo.ErrNoDocuments
+	}
+
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&series)
+	if err != nil {
+		return models.ReservationSeries{}, err
+	}
+
+	return series, nil
+}
+
+// GetUserSeries godoc
+// @Summary Get a user's recurring reservations
+// @Tags Users
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} []models.ReservationSeries
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/series [get]
+func GetUserSeries(userID string, collection *mongo.Collection) ([]models.ReservationSeries, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"userId": userID}, options.Find().SetSort(bson.M{"createdAt": 1}))
+	if err != nil {
+		return []models.ReservationSeries{}, err
+	}
+
+	series := []models.ReservationSeries{}
+	if err := cursor.All(context.Background(), &series); err != nil {
+		return []models.ReservationSeries{}, err
+	}
+
+	return series, nil
+}
+
+// findUserSeries fetches the series of the request and checks that it belongs to the user, responding with an error when it can't be changed
+func findUserSeries(c *gin.Context, userID string, collections Collections) (models.ReservationSeries, bool) {
+	series, err := FindSeriesByID(c.Param("id"), collections.Series)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Series not found"})
+			return models.ReservationSeries{}, false
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch series"})
+		return models.ReservationSeries{}, false
+	}
+
+	if series.UserID != userID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The series belongs to another user"})
+		return models.ReservationSeries{}, false
+	}
+
+	if series.Cancelled {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The series has been cancelled"})
+		return models.ReservationSeries{}, false
+	}
+
+	return series, true
+}
+
+// CancelSeries godoc
+// @Summary Cancel a recurring reservation
+// @Description Cancels every occurrence that hasn't started yet, and stops booking new ones. A single occurrence is cancelled like any other reservation, with DELETE /reservations/{id}.
+// @Tags Reservations
+// @Produce json
+// @Param id path string true "Series ID"
+// @Param userId query string true "User ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /series/{id} [delete]
+func CancelSeries(c *gin.Context, collections Collections) {
+	series, ok := findUserSeries(c, c.Query("userId"), collections)
+	if !ok {
+		return
+	}
+
+	_, err := collections.Series.UpdateOne(context.Background(), bson.M{"_id": series.ID}, bson.M{"$set": bson.M{"cancelled": true}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not cancel the series"})
+		return
+	}
+
+	if err := cancelOccurrences(series, collections); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not cancel the series' occurrences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Series cancelled"})
+}
+
+// UpdateSeries godoc
+// @Summary Edit a recurring reservation
+// @Description Changes the rule, the start of the first occurrence or the reservation time of the whole series. The occurrences that haven't started yet are cancelled and booked again following the new series, past occurrences are kept. Fields that are left out keep their value.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Series ID"
+// @Param body body UpdateSeriesRequest true "Request body"
+// @Success 200 {object} models.ReservationSeries
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /series/{id} [put]
+func UpdateSeries(c *gin.Context, collections Collections) {
+	var req UpdateSeriesRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	series, ok := findUserSeries(c, req.UserID, collections)
+	if !ok {
+		return
+	}
+
+	if req.Rule != "" {
+		if _, err := recurrence.Parse(req.Rule); err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Invalid recurrence rule: %v", err)})
+			return
+		}
+		series.Rule = req.Rule
+	}
+
+	if req.Start != nil {
+		series.Start = *req.Start
+	}
+
+	if req.Minutes != 0 {
+		chargepoint, err := FindChargepointByID(series.Chargepoint, collections.Chargepoints)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+			return
+		}
+
+		policy, err := resolveReservationPolicy(chargepoint, series.Connector, collections)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connector's reservation policy"})
+			return
+		}
+
+		if message := checkDuration(policy, req.Minutes); message != "" {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: message})
+			return
+		}
+		series.Minutes = req.Minutes
+	}
+
+	if err := cancelOccurrences(series, collections); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not cancel the series' occurrences"})
+		return
+	}
+
+	// Only the occurrences that already started stay, the others are booked again
+	now := time.Now()
+	past := []models.SeriesOccurrence{}
+	for _, occurrence := range series.Occurrences {
+		if !occurrence.Start.After(now) {
+			past = append(past, occurrence)
+		}
+	}
+	series.Occurrences = past
+	series.GeneratedUntil = time.Time{}
+
+	_, err := collections.Series.UpdateOne(context.Background(), bson.M{"_id": series.ID}, bson.M{"$set": bson.M{
+		"rule":           series.Rule,
+		"start":          series.Start,
+		"minutes":        series.Minutes,
+		"occurrences":    series.Occurrences,
+		"generatedUntil": series.GeneratedUntil,
+	}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the series"})
+		return
+	}
+
+	if err := bookOccurrences(&series, collections); err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not book the series' occurrences"})
+		return
+	}
+
+	c.JSON(http.StatusOK, series)
+}
+
+type UpdateSeriesRequest struct {
+	UserID  string     `json:"userId"`
+	Rule    string     `json:"rule"`
+	Start   *time.Time `json:"start"`
+	Minutes int        `json:"minutes"`
+}
+
+// UpdateOccurrence godoc
+// @Summary Move an occurrence of a recurring reservation
+// @Description Moves a single occurrence that hasn't started yet to another time on the same connector, for example when the user leaves later one day, see PUT /reservations/{id}. The rest of the series is left as it is.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param id path string true "Series ID"
+// @Param reservationID path string true "Reservation ID of the occurrence"
+// @Param body body UpdateOccurrenceRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /series/{id}/occurrences/{reservationID} [put]
+func UpdateOccurrence(c *gin.Context, collections Collections) {
+	var req UpdateOccurrenceRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	series, ok := findUserSeries(c, req.UserID, collections)
+	if !ok {
+		return
+	}
+
+	reservation, err := FindReservationByID(c.Param("reservationID"), collections.Reservations)
+	if err != nil || reservation.SeriesID != series.ID.Hex() {
+		if err == nil || err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Occurrence not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch reservations"})
+		return
+	}
+
+	if !reservation.StartTime.After(time.Now()) || reservation.HasFinishedCharging {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Only occurrences that haven't started can be moved"})
+		return
+	}
+
+	warnings, modifyErr := modifyReservation(reservation, ModifyReservationRequest{UserID: req.UserID, StartTime: &req.StartTime, Minutes: req.Minutes}, collections)
+	if modifyErr != nil {
+		c.JSON(modifyErr.Status, modifyErr.Body)
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Occurrence moved", Warnings: warnings})
+}
+
+type UpdateOccurrenceRequest struct {
+	UserID    string    `json:"userId"`
+	StartTime time.Time `json:"startTime"`
+	// Optional, changes the reservation time of this occurrence only
+	Minutes int `json:"minutes"`
+}
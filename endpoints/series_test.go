@@ -0,0 +1,135 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestReservationSeries(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/series/:cpID/:coID", func(c *gin.Context) {
+		CreateSeries(c, collections)
+	})
+
+	router.PUT("/series/:id/occurrences/:reservationID", func(c *gin.Context) {
+		UpdateOccurrence(c, collections)
+	})
+
+	router.DELETE("/series/:id", func(c *gin.Context) {
+		CancelSeries(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Series} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	first := time.Now().AddDate(0, 0, 1).Truncate(time.Hour)
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "seriesCommuter", Name: "Commuter"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "seriesChargepoint", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "Type2"},
+	}})
+
+	// Someone else already booked the connector when the second occurrence starts
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), UserID: "someoneElse", Chargepoint: "seriesChargepoint", Connector: 1, StartTime: first.AddDate(0, 0, 1), ChargingTime: first.AddDate(0, 0, 1).Add(time.Hour)})
+
+	request := func(method, endpoint string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	t.Run("InvalidRule", func(t *testing.T) {
+		recorder := request("POST", "/series/seriesChargepoint/1", map[string]any{"userId": "seriesCommuter", "start": first, "minutes": 60, "rule": "FREQ=MONTHLY"})
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, but received %d", http.StatusBadRequest, recorder.Code)
+		}
+	})
+
+	var series models.ReservationSeries
+
+	t.Run("Create", func(t *testing.T) {
+		recorder := request("POST", "/series/seriesChargepoint/1", map[string]any{"userId": "seriesCommuter", "start": first, "minutes": 60, "rule": "FREQ=DAILY;COUNT=3"})
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+
+		json.Unmarshal(recorder.Body.Bytes(), &series)
+		if len(series.Occurrences) != 3 {
+			t.Fatalf("Expected 3 occurrences, but received %v", series.Occurrences)
+		}
+
+		for i, occurrence := range series.Occurrences {
+			failed := occurrence.Error != ""
+			if failed != (i == 1) || failed == (!occurrence.ReservationID.IsZero()) {
+				t.Errorf("Expected only the second occurrence to fail, but received %v", occurrence)
+			}
+		}
+	})
+
+	t.Run("MoveOccurrence", func(t *testing.T) {
+		if len(series.Occurrences) != 3 {
+			t.Skip("The series wasn't created")
+		}
+		endpoint := "/series/" + series.ID.Hex() + "/occurrences/" + series.Occurrences[2].ReservationID.Hex()
+
+		recorder := request("PUT", endpoint, map[string]any{"userId": "seriesCommuter", "startTime": first.AddDate(0, 0, 1).Add(30 * time.Minute)})
+		if recorder.Code != http.StatusBadRequest {
+			t.Errorf("Expected moving onto the other reservation to fail with code %d, but received %d", http.StatusBadRequest, recorder.Code)
+		}
+
+		recorder = request("PUT", endpoint, map[string]any{"userId": "seriesCommuter", "startTime": first.AddDate(0, 0, 2).Add(2 * time.Hour)})
+		if recorder.Code != http.StatusOK {
+			t.Errorf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		if len(series.Occurrences) != 3 {
+			t.Skip("The series wasn't created")
+		}
+
+		recorder := request("DELETE", "/series/"+series.ID.Hex()+"?userId=seriesCommuter", nil)
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+
+		count, _ := collections.Reservations.CountDocuments(context.Background(), bson.M{"seriesId": series.ID.Hex(), "cancelled": false})
+		if count != 0 {
+			t.Errorf("Expected every occurrence to be cancelled, but %d are left", count)
+		}
+	})
+}
@@ -0,0 +1,415 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Session statuses
+const (
+	SessionCharging  = "Charging"
+	SessionCompleted = "Completed"
+)
+
+// Reasons a session stopped
+const (
+	StopReasonLocal            = "Local"
+	StopReasonReservationEnded = "ReservationEnded"
+	StopReasonCancelled        = "Cancelled"
+)
+
+func startSession(reservation models.Reservation, meterStart float64, sessionsCollection *mongo.Collection) (models.ChargingSession, error) {
+	session := models.ChargingSession{
+		ID:             primitive.NewObjectID(),
+		ReservationID:  reservation.ID,
+		UserID:         reservation.UserID,
+		OrganizationID: reservation.OrganizationID,
+		Chargepoint:    reservation.Chargepoint,
+		Connector:      reservation.Connector,
+		TariffID:       reservation.TariffID,
+		WalkIn:         reservation.WalkIn,
+		Adjustments:    reservation.Adjustments,
+		PowerLimit:     reservation.PowerLimit,
+		Priority:       reservation.Priority,
+		Status:         SessionCharging,
+		StartTime:      time.Now(),
+		MeterStart:     meterStart,
+		MeterStop:      meterStart,
+	}
+
+	// A new session means the previous vehicle left the connector, even if its unplugging wasn't reported
+	err := markUnplugged(reservation.Chargepoint, reservation.Connector, sessionsCollection)
+	if err != nil {
+		return models.ChargingSession{}, err
+	}
+
+	_, err = sessionsCollection.InsertOne(context.Background(), session)
+	return session, err
+}
+
+func findActiveSession(chargepointID string, connector int, sessionsCollection *mongo.Collection) (models.ChargingSession, error) {
+	var session models.ChargingSession
+	err := sessionsCollection.FindOne(context.Background(), bson.M{"chargepoint": chargepointID, "connector": connector, "status": SessionCharging}).Decode(&session)
+	return session, err
+}
+
+// stopSession closes the connector's active session, if there is one, computes the energy it delivered and settles its payment. A meter value of 0 keeps the last known meter value.
+func stopSession(chargepointID string, connector int, meterStop float64, reason string, collections Collections) error {
+	session, err := findActiveSession(chargepointID, connector, collections.Sessions)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+
+	if meterStop <= 0 {
+		meterStop = session.MeterStop
+	}
+
+	session.Status = SessionCompleted
+	session.StopTime = time.Now()
+	session.StopReason = reason
+	session.MeterStop = meterStop
+	session.Energy = (meterStop - session.MeterStart) / 1000
+
+	update := bson.M{
+		"status":     session.Status,
+		"stopTime":   session.StopTime,
+		"stopReason": session.StopReason,
+		"meterStop":  session.MeterStop,
+		"energy":     session.Energy,
+	}
+
+	// The vehicle only takes up the connector once the reservation's charging time is over, or from when charging stops after it. Operators cancelling the session don't make the user pay for idling.
+	if reason != StopReasonCancelled {
+		reservation, err := FindReservationByID(session.ReservationID.Hex(), collections.Reservations)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return err
+		}
+
+		session.IdleSince = session.StopTime
+		if reservation.ChargingTime.After(session.IdleSince) {
+			session.IdleSince = reservation.ChargingTime
+		}
+		update["idleSince"] = session.IdleSince
+	}
+
+	_, err = collections.Sessions.UpdateOne(context.Background(), bson.M{"_id": session.ID}, bson.M{"$set": update})
+	if err != nil {
+		return err
+	}
+
+	return settleSession(session, collections)
+}
+
+// settleSession pays for the session the way its reservation is paid for, from the user's wallet or by capturing their card payment
+func settleSession(session models.ChargingSession, collections Collections) error {
+	mode, err := reservationPaymentMode(session.ReservationID, session.UserID, collections)
+	if err != nil {
+		return err
+	}
+
+	switch mode {
+	case PaymentModeWallet:
+		return captureSession(session, collections)
+	case PaymentModeCard:
+		return capturePayment(session, collections)
+	}
+
+	return nil
+}
+
+// StopCharging godoc
+// @Summary Stop charging
+// @Description Ends the user's active charging session on the connector, finishing their reservation and making the connector available again. The meter value (in Wh) is optional.
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param connectorID path int true "Connector ID"
+// @Param body body StopChargingRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /stop/{chargepointID}/{connectorID} [post]
+func StopCharging(c *gin.Context, collections Collections) {
+	var req StopChargingRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	connectorNumber, err := strconv.Atoi(c.Param("coID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be a number"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	if connectorNumber <= 0 || connectorNumber > len(chargepoint.Connectors) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors"})
+		return
+	}
+
+	session, err := findActiveSession(chargepoint.ID, connectorNumber, collections.Sessions)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "There is no active charging session on the connector"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch sessions"})
+		return
+	}
+
+	if session.UserID != req.UserID {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The charging session belongs to another user"})
+		return
+	}
+
+	if req.MeterStop != 0 && req.MeterStop < session.MeterStart {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The meter value can't be lower than at the start of the session"})
+		return
+	}
+
+	err = stopSession(chargepoint.ID, connectorNumber, req.MeterStop, StopReasonLocal, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not stop the charging session"})
+		return
+	}
+
+	_, err = collections.Reservations.UpdateOne(context.Background(), bson.M{"_id": session.ReservationID}, bson.M{"$set": bson.M{"hasFinishedCharging": true}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update charging state of reservation"})
+		return
+	}
+
+	_, err = setConnectorState(chargepoint.ID, connectorNumber, "Charging", "Available", collections.Chargepoints)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not update the state of the connector"})
+		return
+	}
+
+	err = offerConnectors(chargepoint.ID, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not offer the connector to the waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Stopped charging on the connector"})
+}
+
+type StopChargingRequest struct {
+	UserID    string  `json:"userId"`
+	MeterStop float64 `json:"meterStop"`
+}
+
+// FindSessionByID godoc
+// @Summary Get information about a charging session by ID
+// @Tags Sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} models.ChargingSession
+// @Failure 404 {object} models.ErrorResponse
+// @Router /sessions/{id} [get]
+func FindSessionByID(id string, collection *mongo.Collection) (models.ChargingSession, error) {
+	var session models.ChargingSession
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.ChargingSession{}, mongo.ErrNoDocuments
+	}
+
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&session)
+	if err != nil {
+		return models.ChargingSession{}, err
+	}
+
+	return session, nil
+}
+
+// GetSessions godoc
+// @Summary Get charging sessions
+// @Description Lists every charging session, newest first. The user, reservation and status filters are optional.
+// @Tags Sessions
+// @Produce json
+// @Param userId query string false "User ID"
+// @Param reservationId query string false "Reservation ID"
+// @Param status query string false "Status (Charging or Completed)"
+// @Success 200 {object} []models.ChargingSession
+// @Failure 500 {object} models.ErrorResponse
+// @Router /sessions [get]
+func GetSessions(filter bson.M, collection *mongo.Collection) ([]models.ChargingSession, error) {
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.M{"startTime": -1}))
+	if err != nil {
+		return []models.ChargingSession{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	sessions := []models.ChargingSession{}
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		return []models.ChargingSession{}, err
+	}
+
+	return sessions, nil
+}
+
+// SessionsFilter builds the GetSessions filter out of the request's query parameters
+func SessionsFilter(c *gin.Context) bson.M {
+	filter := bson.M{}
+
+	if userID := c.Query("userId"); userID != "" {
+		filter["userId"] = userID
+	}
+
+	if reservationID, err := primitive.ObjectIDFromHex(c.Query("reservationId")); err == nil {
+		filter["reservationId"] = reservationID
+	}
+
+	if status := c.Query("status"); status != "" {
+		filter["status"] = status
+	}
+
+	return filter
+}
+
+// sessionUsage is what the session used so far, as far as pricing is concerned
+func sessionUsage(session models.ChargingSession) Usage {
+	end := session.StopTime
+	if session.Status == SessionCharging {
+		end = time.Now()
+	}
+
+	return Usage{
+		Start:  session.StartTime,
+		End:    end,
+		Energy: (session.MeterStop - session.MeterStart) / 1000,
+	}
+}
+
+// GetSessionPrice godoc
+// @Summary Get the price of a charging session
+// @Description Prices the session under the tariff that applied when its reservation was made. Active sessions are priced up to now.
+// @Tags Sessions
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} models.Price
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /sessions/{id}/price [get]
+func GetSessionPrice(session models.ChargingSession, collections Collections) (models.Price, error) {
+	tariff, err := findTariffOrFree(session.TariffID, collections.Tariffs)
+	if err != nil {
+		return models.Price{}, err
+	}
+
+	usage := sessionUsage(session)
+	usage.Reserved = !session.ReservationID.IsZero() && !session.WalkIn
+
+	return ApplyAdjustments(ComputePrice(tariff, usage), session.Adjustments), nil
+}
+
+// GetOrganizationSessions godoc
+// @Summary Get all charging sessions of an organization's members
+// @Description Only the organization's admins can view its sessions.
+// @Tags Organizations
+// @Produce json
+// @Param id path string true "Organization ID"
+// @Param adminId query string true "Admin's user ID"
+// @Success 200 {object} []models.ChargingSession
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /organizations/{id}/sessions [get]
+func GetOrganizationSessions(c *gin.Context, collections Collections) {
+	organization, ok := findOrganizationAsAdmin(c, c.Param("id"), c.Query("adminId"), collections.Organizations)
+	if !ok {
+		return
+	}
+
+	sessions, err := GetSessions(bson.M{"organizationId": organization.ID}, collections.Sessions)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch sessions"})
+		return
+	}
+
+	c.JSON(http.StatusOK, sessions)
+}
+
+// lateSessionWarningMinutes is how soon the next reservation has to start for its holder to be warned about a vehicle that's still plugged in
+func lateSessionWarningMinutes() int {
+	return envInt("LATE_SESSION_WARNING_MINUTES", 30)
+}
+
+// checkLateSessions warns the holders of upcoming reservations when the vehicle before them is still plugged in after its reservation ended. Each late session is only reported once.
+func checkLateSessions(collections Collections) {
+	cursor, err := collections.Sessions.Find(context.Background(), bson.M{"idleSince": bson.M{"$lte": time.Now()}, "unpluggedAt": bson.M{"$exists": false}, "lateWarned": bson.M{"$ne": true}})
+	if err != nil {
+		fmt.Println("Error getting late sessions: ", err)
+		return
+	}
+
+	var sessions []models.ChargingSession
+	if err := cursor.All(context.Background(), &sessions); err != nil {
+		fmt.Println("Error decoding late sessions: ", err)
+		return
+	}
+
+	now := time.Now()
+	for _, session := range sessions {
+		filter := bson.M{
+			"chargepoint":         session.Chargepoint,
+			"connector":           session.Connector,
+			"hasStartedCharging":  false,
+			"hasFinishedCharging": false,
+			"expiryTime":          bson.M{"$gt": now},
+			"startTime":           bson.M{"$lte": now.Add(time.Duration(lateSessionWarningMinutes()) * time.Minute)},
+		}
+
+		// Nobody is waiting for the connector yet, the session is checked again on the next run
+		var next models.Reservation
+		err := collections.Reservations.FindOne(context.Background(), filter, options.FindOne().SetSort(bson.M{"startTime": 1})).Decode(&next)
+		if err != nil {
+			if err != mongo.ErrNoDocuments {
+				fmt.Println("Error getting the next reservation: ", err)
+			}
+			continue
+		}
+
+		// Claim the session first, so the next holder is never warned twice
+		result, err := collections.Sessions.UpdateOne(context.Background(), bson.M{"_id": session.ID, "lateWarned": bson.M{"$ne": true}}, bson.M{"$set": bson.M{"lateWarned": true}})
+		if err != nil || result.ModifiedCount == 0 {
+			continue
+		}
+
+		err = notify(models.Notification{
+			UserID:        next.UserID,
+			ReservationID: next.ID,
+			Type:          NotificationLateSession,
+			Message:       fmt.Sprintf("The vehicle before you is still plugged in to connector %d of %s, your reservation from %s may start late", next.Connector, next.Chargepoint, next.StartTime.Format("15:04")),
+		}, collections.Notifications)
+		if err != nil {
+			fmt.Println("Error notifying the next reservation holder: ", err)
+		}
+	}
+}
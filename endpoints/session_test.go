@@ -0,0 +1,139 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestSessions(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/charge/:cpID/:coID", func(c *gin.Context) {
+		Charge(c, collections)
+	})
+
+	router.POST("/stop/:cpID/:coID", func(c *gin.Context) {
+		StopCharging(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Sessions} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "sessionUser", Name: "Session user"})
+	collections.Users.InsertOne(context.Background(), models.User{ID: "otherUser", Name: "Other user"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "sessionChargepoint", Connectors: []models.Connector{
+		{ID: 1, State: "Reserved"},
+		{ID: 2, State: "Charging"},
+	}})
+	reservationID := primitive.NewObjectID()
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{
+		ID:           reservationID,
+		Chargepoint:  "sessionChargepoint",
+		Connector:    1,
+		UserID:       "sessionUser",
+		ExpiryTime:   time.Now().Add(10 * time.Minute),
+		ChargingTime: time.Now().Add(time.Hour),
+	})
+
+	request := func(endpoint string, body any) int {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	t.Run("ChargeStartsSession", func(t *testing.T) {
+		code := request("/charge/sessionChargepoint/1", map[string]any{"userId": "sessionUser", "meterStart": 1000})
+		if code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, code)
+		}
+
+		sessions, err := GetSessions(bson.M{"reservationId": reservationID}, collections.Sessions)
+		if err != nil {
+			t.Fatalf("Could not fetch sessions:\n%v", err)
+		}
+
+		if len(sessions) != 1 || sessions[0].Status != SessionCharging || sessions[0].MeterStart != 1000 {
+			t.Errorf("Expected one charging session starting at 1000 Wh, but received %v", sessions)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		connector string
+		body      map[string]any
+		code      int
+	}{
+		{name: "StopWithoutSession", connector: "2", body: map[string]any{"userId": "sessionUser"}, code: http.StatusBadRequest},
+		{name: "StopOtherUsersSession", connector: "1", body: map[string]any{"userId": "otherUser"}, code: http.StatusBadRequest},
+		{name: "StopWithLowerMeter", connector: "1", body: map[string]any{"userId": "sessionUser", "meterStop": 500}, code: http.StatusBadRequest},
+		{name: "StopCharging", connector: "1", body: map[string]any{"userId": "sessionUser", "meterStop": 21000}, code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := request("/stop/sessionChargepoint/"+test.connector, test.body)
+			if code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+
+	t.Run("SessionCompleted", func(t *testing.T) {
+		sessions, err := GetSessions(bson.M{"reservationId": reservationID}, collections.Sessions)
+		if err != nil {
+			t.Fatalf("Could not fetch sessions:\n%v", err)
+		}
+
+		if len(sessions) != 1 || sessions[0].Status != SessionCompleted || sessions[0].StopReason != StopReasonLocal {
+			t.Fatalf("Expected one session stopped locally, but received %v", sessions)
+		}
+
+		if energy := sessionUsage(sessions[0]).Energy; energy != 20 {
+			t.Errorf("Expected 20 kWh to be delivered, but received %.2f kWh", energy)
+		}
+
+		chargepoint, err := FindChargepointByID("sessionChargepoint", collections.Chargepoints)
+		if err != nil {
+			t.Fatalf("Could not find the test chargepoint:\n%v", err)
+		}
+
+		if chargepoint.Connectors[0].State != "Available" {
+			t.Errorf("Expected the chargepoint connector state to be %s, but received %s", "Available", chargepoint.Connectors[0].State)
+		}
+	})
+}
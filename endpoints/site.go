@@ -0,0 +1,98 @@
+package endpoints
+
+import (
+	"context"
+	"net/http"
+	"reservations/db"
+	"reservations/models"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateSite godoc
+// @Summary Create a new site
+// @Description A site groups chargepoints at the same location. Chargepoints are added to a site when they are created.
+// @Tags Sites
+// @Accept json
+// @Produce json
+// @Param id path string true "Site ID"
+// @Param body body CreateSiteRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /sites/{id} [post]
+func CreateSite(c *gin.Context, collection *mongo.Collection) {
+	var req CreateSiteRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Name must be a non-empty string"})
+		return
+	}
+
+	if req.Location != nil && (req.Location.Latitude < -90 || req.Location.Latitude > 90 || req.Location.Longitude < -180 || req.Location.Longitude > 180) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The latitude must be between -90 and 90 and the longitude between -180 and 180"})
+		return
+	}
+
+	newSite := models.Site{
+		ID:       c.Param("id"),
+		Name:     req.Name,
+		Location: req.Location,
+	}
+
+	_, err := collection.InsertOne(context.Background(), newSite)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create a new site, perhaps an existing ID was entered"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Site created"})
+}
+
+type CreateSiteRequest struct {
+	Name string `json:"name"`
+	// Optional, lets drivers search for sites near them
+	Location *models.Location `json:"location"`
+}
+
+// FindSiteByID godoc
+// @Summary Get information about a site by ID
+// @Tags Sites
+// @Produce json
+// @Param id path string true "Site ID"
+// @Success 200 {object} models.Site
+// @Failure 404 {object} models.ErrorResponse
+// @Router /sites/{id} [get]
+func FindSiteByID(id string, collection *mongo.Collection) (models.Site, error) {
+	var site models.Site
+
+	err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&site)
+	if err != nil {
+		return models.Site{}, err
+	}
+
+	return site, nil
+}
+
+// GetAllSites godoc
+// @Summary Get all sites
+// @Tags Sites
+// @Produce json
+// @Success 200 {object} []models.Site
+// @Failure 500 {object} models.ErrorResponse
+// @Router /sites [get]
+func GetAllSites(collection *mongo.Collection) ([]bson.M, error) {
+	documents, err := db.GetAllDocumentsInCollection(collection)
+	if err != nil {
+		return []bson.M{}, err
+	}
+
+	return documents, nil
+}
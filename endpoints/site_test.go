@@ -0,0 +1,119 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+)
+
+func TestSites(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/sites/:id", func(c *gin.Context) {
+		CreateSite(c, collections.Sites)
+	})
+
+	router.GET("/sites/:id", func(c *gin.Context) {
+		site, err := FindSiteByID(c.Param("id"), collections.Sites)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Site not found"})
+			return
+		}
+		c.JSON(http.StatusOK, site)
+	})
+
+	router.POST("/chargepoints/:id", func(c *gin.Context) {
+		CreateChargepoint(c, collections)
+	})
+
+	tests := []struct {
+		id         string
+		name       string
+		createCode int
+		getCode    int
+	}{
+		{id: "highway", name: "Highway", createCode: http.StatusOK, getCode: http.StatusOK},
+		{id: "nameless", name: "", createCode: http.StatusBadRequest, getCode: http.StatusNotFound},
+	}
+
+	defer func() {
+		err := db.ClearCollection(collections.Sites)
+		if err != nil {
+			t.Fatalf("Failed to clear collection:\n%v", err)
+		}
+		err = db.ClearCollection(collections.Chargepoints)
+		if err != nil {
+			t.Fatalf("Failed to clear collection:\n%v", err)
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	for _, test := range tests {
+		t.Run("CreateSite", func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{"name": test.name})
+			req, _ := http.NewRequest("POST", "/sites/"+test.id, bytes.NewReader(body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.createCode {
+				t.Errorf("Expected code %d, but received %d", test.createCode, recorder.Code)
+			} else {
+				t.Logf("Received the correct code %d", test.createCode)
+			}
+		})
+
+		t.Run("GetSite", func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/sites/"+test.id, nil)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.getCode {
+				t.Errorf("Expected code %d, but got %d", test.getCode, recorder.Code)
+			} else {
+				t.Logf("Received the correct code %d", test.getCode)
+			}
+		})
+	}
+
+	chargepointTests := []struct {
+		id     string
+		siteID string
+		code   int
+	}{
+		{id: "highwayCp", siteID: "highway", code: http.StatusOK},
+		{id: "nowhereCp", siteID: "nowhere", code: http.StatusBadRequest},
+	}
+
+	for _, test := range chargepointTests {
+		t.Run("CreateChargepointOnSite", func(t *testing.T) {
+			body, _ := json.Marshal(map[string]any{"connectors": 2, "siteId": test.siteID})
+			req, _ := http.NewRequest("POST", "/chargepoints/"+test.id, bytes.NewReader(body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,456 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/db"
+	"reservations/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// CreateTariff godoc
+// @Summary Create a new tariff
+// @Description All prices are in cents. Bands override the per-minute and per-kWh prices during part of the day, with "HH:MM" start and end times in the server's time zone (a band can wrap past midnight, like 22:00 to 06:00). The idle fee is charged per minute the vehicle stays plugged in after its reservation ends (or charging stops after it), and the no-show fee once when a reservation expires without charging.
+// @Tags Tariffs
+// @Accept json
+// @Produce json
+// @Param id path string true "Tariff ID"
+// @Param body body CreateTariffRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /tariffs/{id} [post]
+func CreateTariff(c *gin.Context, collection *mongo.Collection) {
+	var req CreateTariffRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Name must be a non-empty string"})
+		return
+	}
+
+	if req.PerMinute < 0 || req.PerKWh < 0 || req.SessionFee < 0 || req.ReservationFee < 0 || req.IdleFee < 0 || req.NoShowFee < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Prices can't be negative"})
+		return
+	}
+
+	for _, band := range req.Bands {
+		start, err := parseClock(band.Start)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Band times must be in the HH:MM format"})
+			return
+		}
+
+		end, err := parseClock(band.End)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Band times must be in the HH:MM format"})
+			return
+		}
+
+		if start == end {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "A band's start and end must differ"})
+			return
+		}
+
+		if band.PerMinute < 0 || band.PerKWh < 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Prices can't be negative"})
+			return
+		}
+	}
+
+	newTariff := models.Tariff{
+		ID:             c.Param("id"),
+		Name:           req.Name,
+		Currency:       req.Currency,
+		PerMinute:      req.PerMinute,
+		PerKWh:         req.PerKWh,
+		SessionFee:     req.SessionFee,
+		ReservationFee: req.ReservationFee,
+		IdleFee:        req.IdleFee,
+		NoShowFee:      req.NoShowFee,
+		Bands:          req.Bands,
+	}
+	if newTariff.Currency == "" {
+		newTariff.Currency = defaultCurrency()
+	}
+	if newTariff.Bands == nil {
+		newTariff.Bands = []models.TariffBand{}
+	}
+
+	_, err := collection.InsertOne(context.Background(), newTariff)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create a new tariff, perhaps an existing ID was entered"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Tariff created"})
+}
+
+type CreateTariffRequest struct {
+	Name           string              `json:"name"`
+	Currency       string              `json:"currency"`
+	PerMinute      int64               `json:"perMinute"`
+	PerKWh         int64               `json:"perKWh"`
+	SessionFee     int64               `json:"sessionFee"`
+	ReservationFee int64               `json:"reservationFee"`
+	IdleFee        int64               `json:"idleFee"`
+	NoShowFee      int64               `json:"noShowFee"`
+	Bands          []models.TariffBand `json:"bands"`
+}
+
+// FindTariffByID godoc
+// @Summary Get information about a tariff by ID
+// @Tags Tariffs
+// @Produce json
+// @Param id path string true "Tariff ID"
+// @Success 200 {object} models.Tariff
+// @Failure 404 {object} models.ErrorResponse
+// @Router /tariffs/{id} [get]
+func FindTariffByID(id string, collection *mongo.Collection) (models.Tariff, error) {
+	var tariff models.Tariff
+
+	err := collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&tariff)
+	if err != nil {
+		return models.Tariff{}, err
+	}
+
+	return tariff, nil
+}
+
+// GetAllTariffs godoc
+// @Summary Get all tariffs
+// @Tags Tariffs
+// @Produce json
+// @Success 200 {object} []models.Tariff
+// @Failure 500 {object} models.ErrorResponse
+// @Router /tariffs [get]
+func GetAllTariffs(collection *mongo.Collection) ([]bson.M, error) {
+	documents, err := db.GetAllDocumentsInCollection(collection)
+	if err != nil {
+		return []bson.M{}, err
+	}
+
+	return documents, nil
+}
+
+// AttachTariff godoc
+// @Summary Attach a tariff to a site, chargepoint or connector
+// @Description Give either a site ID, a chargepoint ID, or a chargepoint ID with a connector ID. A connector's own tariff takes precedence over its chargepoint's, which takes precedence over its site's. Connectors without any tariff are free.
+// @Tags Tariffs
+// @Accept json
+// @Produce json
+// @Param id path string true "Tariff ID"
+// @Param body body AttachTariffRequest true "Request body"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /tariffs/{id}/attach [post]
+func AttachTariff(c *gin.Context, collections Collections) {
+	var req AttachTariffRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	tariff, err := FindTariffByID(c.Param("id"), collections.Tariffs)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Tariff does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch tariffs"})
+		return
+	}
+
+	if (req.SiteID == "") == (req.ChargepointID == "") {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Either a site or a chargepoint must be given"})
+		return
+	}
+
+	if req.SiteID != "" {
+		result, err := collections.Sites.UpdateOne(context.Background(), bson.M{"_id": req.SiteID}, bson.M{"$set": bson.M{"tariffId": tariff.ID}})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not attach the tariff"})
+			return
+		}
+
+		if result.MatchedCount == 0 {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Site does not exist"})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.MessageResponse{Message: "Tariff attached"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(req.ChargepointID, collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	update := bson.M{"tariffId": tariff.ID}
+	if req.ConnectorID != 0 {
+		if req.ConnectorID < 0 || req.ConnectorID > len(chargepoint.Connectors) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors"})
+			return
+		}
+
+		chargepoint.Connectors[req.ConnectorID-1].TariffID = tariff.ID
+		update = bson.M{"connectors": chargepoint.Connectors}
+	}
+
+	_, err = collections.Chargepoints.UpdateOne(context.Background(), bson.M{"_id": chargepoint.ID}, bson.M{"$set": update})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not attach the tariff"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Tariff attached"})
+}
+
+type AttachTariffRequest struct {
+	SiteID        string `json:"siteId"`
+	ChargepointID string `json:"chargepointId"`
+	ConnectorID   int    `json:"connectorId"`
+}
+
+// resolveTariff finds the tariff that applies to a connector: its own, its chargepoint's or its site's, in that order. Connectors without any tariff are free.
+func resolveTariff(chargepoint models.Chargepoint, connectorNumber int, collections Collections) (models.Tariff, error) {
+	tariffID := chargepoint.Connectors[connectorNumber-1].TariffID
+	if tariffID == "" {
+		tariffID = chargepoint.TariffID
+	}
+
+	if tariffID == "" && chargepoint.SiteID != "" {
+		site, err := FindSiteByID(chargepoint.SiteID, collections.Sites)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return models.Tariff{}, err
+		}
+		tariffID = site.TariffID
+	}
+
+	if tariffID == "" {
+		return models.Tariff{Currency: defaultCurrency()}, nil
+	}
+
+	return FindTariffByID(tariffID, collections.Tariffs)
+}
+
+// QuotePrice godoc
+// @Summary Estimate the price of a reservation
+// @Description Prices a reservation of the connector starting now, or at the start time when it's booked ahead, without making it. The energy (in kWh) is optional: when a vehicle is given instead, the energy is estimated from the power the vehicle can draw from the connector, capped at its battery capacity. The site's pricing policy adjustments are included as components, and are honoured when the same user makes a reservation with the same connector, start time and minutes with the quote's ID before it expires (after QUOTE_VALID_MINUTES). A quote can only be used for one reservation.
+// @Tags Reservations
+// @Accept json
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param connectorID path int true "Connector ID"
+// @Param body body QuoteRequest true "Request body"
+// @Success 200 {object} models.Quote
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /quotes/{chargepointID}/{connectorID} [post]
+func QuotePrice(c *gin.Context, collections Collections) {
+	var req QuoteRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if _, err := FindUserByID(req.UserID, collections.Users); err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch users"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	connectorNumber, err := strconv.Atoi(c.Param("coID"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector must be a number"})
+		return
+	}
+
+	if connectorNumber <= 0 || connectorNumber > len(chargepoint.Connectors) {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Connector ID must be between 1 and the amount of the chargepoint's connectors"})
+		return
+	}
+
+	policy, err := resolveReservationPolicy(chargepoint, connectorNumber, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connector's reservation policy"})
+		return
+	}
+
+	if message := checkDuration(policy, req.Minutes); message != "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: message})
+		return
+	}
+
+	if req.Energy < 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Energy can't be negative"})
+		return
+	}
+
+	energy := req.Energy
+	if energy == 0 && req.VehicleID != "" {
+		vehicle, err := FindVehicleByID(req.VehicleID, collections.Vehicles)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Vehicle does not exist"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch vehicles"})
+			return
+		}
+
+		energy = estimateEnergy(&vehicle, chargepoint.Connectors[connectorNumber-1], req.Minutes)
+	}
+
+	tariff, err := resolveTariff(chargepoint, connectorNumber, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connector's tariff"})
+		return
+	}
+
+	// Quotes are only valid for reservations starting when the quote does, see findValidQuote
+	now := time.Now()
+	start := now
+	var startTime time.Time
+	if req.StartTime != nil && req.StartTime.After(now) {
+		if req.StartTime.After(now.AddDate(0, 0, policy.HorizonDays)) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Reservations can start at most %d days ahead", policy.HorizonDays)})
+			return
+		}
+		start = *req.StartTime
+		startTime = start
+	}
+	end := start.Add(time.Duration(req.Minutes) * time.Minute)
+
+	adjustments, err := dynamicAdjustments(chargepoint, start, end, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to apply the site's pricing policy"})
+		return
+	}
+
+	quote := models.Quote{
+		ID:          primitive.NewObjectID(),
+		UserID:      req.UserID,
+		Chargepoint: chargepoint.ID,
+		Connector:   connectorNumber,
+		Minutes:     req.Minutes,
+		StartTime:   startTime,
+		Adjustments: adjustments,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(time.Duration(quoteValidMinutes()) * time.Minute),
+		Price: ApplyAdjustments(ComputePrice(tariff, Usage{
+			Reserved: true,
+			Start:    start,
+			End:      end,
+			Energy:   energy,
+		}), adjustments),
+	}
+	if quote.Adjustments == nil {
+		quote.Adjustments = []models.PriceAdjustment{}
+	}
+
+	_, err = collections.Quotes.InsertOne(context.Background(), quote)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not save the quote"})
+		return
+	}
+
+	c.JSON(http.StatusOK, quote)
+}
+
+type QuoteRequest struct {
+	// The user the quote is for, only they can reserve with it
+	UserID    string  `json:"userId"`
+	Minutes   int     `json:"minutes"`
+	Energy    float64 `json:"energy"`
+	VehicleID string  `json:"vehicleId"`
+	// Optional, prices a reservation booked ahead instead of one starting now
+	StartTime *time.Time `json:"startTime"`
+}
+
+// estimateEnergy is how many kWh the vehicle can take from the connector in the given minutes. Without a vehicle, the connector is assumed to deliver its maximum power.
+func estimateEnergy(vehicle *models.Vehicle, connector models.Connector, minutes int) float64 {
+	if vehicle == nil {
+		return connector.MaxPower * float64(minutes) / 60
+	}
+
+	energy := chargingPower(*vehicle, connector) * float64(minutes) / 60
+	if vehicle.BatteryCapacity > 0 && energy > vehicle.BatteryCapacity {
+		energy = vehicle.BatteryCapacity
+	}
+
+	return energy
+}
+
+// findTariffOrFree fetches the tariff, or returns a free one when there is no tariff ID
+func findTariffOrFree(id string, collection *mongo.Collection) (models.Tariff, error) {
+	if id == "" {
+		return models.Tariff{Currency: defaultCurrency()}, nil
+	}
+
+	return FindTariffByID(id, collection)
+}
+
+// GetReservationPrice godoc
+// @Summary Get the price of a reservation
+// @Description Prices the reservation and its charging session under the tariff that applied when the reservation was made, with the pricing policy adjustments locked in then. Active sessions are priced up to now.
+// @Tags Reservations
+// @Produce json
+// @Param id path string true "Reservation ID"
+// @Success 200 {object} models.Price
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /reservations/{id}/price [get]
+func GetReservationPrice(reservation models.Reservation, collections Collections) (models.Price, error) {
+	tariff, err := findTariffOrFree(reservation.TariffID, collections.Tariffs)
+	if err != nil {
+		return models.Price{}, err
+	}
+
+	usage := Usage{}
+
+	var session models.ChargingSession
+	err = collections.Sessions.FindOne(context.Background(), bson.M{"reservationId": reservation.ID}).Decode(&session)
+	if err == nil {
+		usage = sessionUsage(session)
+	} else if err != mongo.ErrNoDocuments {
+		return models.Price{}, err
+	}
+
+	usage.Reserved = !reservation.WalkIn
+
+	return ApplyAdjustments(ComputePrice(tariff, usage), reservation.Adjustments), nil
+}
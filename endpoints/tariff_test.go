@@ -0,0 +1,119 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestTariffs(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/tariffs/:id", func(c *gin.Context) {
+		CreateTariff(c, collections.Tariffs)
+	})
+
+	router.POST("/tariffs/:id/attach", func(c *gin.Context) {
+		AttachTariff(c, collections)
+	})
+
+	router.POST("/quotes/:cpID/:coID", func(c *gin.Context) {
+		QuotePrice(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Tariffs, collections.Sites, collections.Chargepoints, collections.Quotes} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "tariffUser", Name: "Quoted user"})
+	collections.Sites.InsertOne(context.Background(), models.Site{ID: "tariffSite", Name: "Tariff site"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "tariffChargepoint", SiteID: "tariffSite", Connectors: []models.Connector{
+		{ID: 1, State: "Available"},
+		{ID: 2, State: "Available"},
+	}})
+
+	request := func(endpoint string, body any) *httptest.ResponseRecorder {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	tests := []struct {
+		name     string
+		endpoint string
+		body     any
+		code     int
+	}{
+		{name: "CreateTariff", endpoint: "/tariffs/site", body: map[string]any{"name": "Site", "reservationFee": 100}, code: http.StatusOK},
+		{name: "CreateConnectorTariff", endpoint: "/tariffs/fast", body: map[string]any{"name": "Fast", "reservationFee": 300}, code: http.StatusOK},
+		{name: "CreateNegativeTariff", endpoint: "/tariffs/negative", body: map[string]any{"name": "Negative", "perMinute": -1}, code: http.StatusBadRequest},
+		{name: "CreateInvalidBandTariff", endpoint: "/tariffs/band", body: map[string]any{"name": "Band", "bands": []map[string]any{{"name": "Night", "start": "22", "end": "06:00"}}}, code: http.StatusBadRequest},
+		{name: "AttachToSite", endpoint: "/tariffs/site/attach", body: map[string]any{"siteId": "tariffSite"}, code: http.StatusOK},
+		{name: "AttachToConnector", endpoint: "/tariffs/fast/attach", body: map[string]any{"chargepointId": "tariffChargepoint", "connectorId": 2}, code: http.StatusOK},
+		{name: "AttachToNothing", endpoint: "/tariffs/fast/attach", body: map[string]any{}, code: http.StatusBadRequest},
+		{name: "AttachMissingTariff", endpoint: "/tariffs/missing/attach", body: map[string]any{"siteId": "tariffSite"}, code: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := request(test.endpoint, test.body)
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+
+	quotes := []struct {
+		connector string
+		total     int64
+	}{
+		{connector: "1", total: 100},
+		{connector: "2", total: 300},
+	}
+
+	for _, quote := range quotes {
+		t.Run("QuotePrice", func(t *testing.T) {
+			recorder := request("/quotes/tariffChargepoint/"+quote.connector, map[string]any{"userId": "tariffUser", "minutes": 60})
+			if recorder.Code != http.StatusOK {
+				t.Fatalf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+			}
+
+			var price models.Price
+			json.Unmarshal(recorder.Body.Bytes(), &price)
+
+			if price.Total != quote.total {
+				t.Errorf("Expected connector %s to be quoted %d, but received %d", quote.connector, quote.total, price.Total)
+			}
+		})
+	}
+}
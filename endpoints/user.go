@@ -18,9 +18,12 @@ import (
 // @Produce json
 // @Param id path string true "User ID"
 // @Param body body CreateUserRequest true "Request body"
+// @Param Idempotency-Key header string false "Optional, retries with the same key get the first response instead of handling the request again"
 // @Success 200 {object} models.MessageResponse
 // @Failure 500 {object} models.ErrorResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 422 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
 // @Router /users/{id} [post]
 func CreateUser(c *gin.Context, collection *mongo.Collection) {
 	var newUser models.User
@@ -46,6 +49,7 @@ func CreateUser(c *gin.Context, collection *mongo.Collection) {
 
 	newUser.ID = id
 	newUser.Name = req.Name
+	newUser.Priority = req.Priority
 
 	_, err := collection.InsertOne(context.Background(), newUser)
 	if err != nil {
@@ -58,6 +62,8 @@ func CreateUser(c *gin.Context, collection *mongo.Collection) {
 
 type CreateUserRequest struct {
 	Name string `json:"name"`
+	// Optional, sessions of users with a higher priority get power first at sites that share it by priority
+	Priority int `json:"priority"`
 }
 
 // FindUserByID godoc
@@ -0,0 +1,254 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"sort"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var plugTypes = []string{"Type1", "Type2", "CCS1", "CCS2", "CHAdeMO", "NACS", "GBT"}
+
+func isPlugType(plugType string) bool {
+	for _, known := range plugTypes {
+		if plugType == known {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CreateVehicle godoc
+// @Summary Add a vehicle to a user
+// @Description The plug types can be "Type1", "Type2", "CCS1", "CCS2", "CHAdeMO", "NACS" or "GBT". Charge rates are in kW and the battery capacity is in kWh.
+// @Tags Vehicles
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param body body CreateVehicleRequest true "Request body"
+// @Success 200 {object} models.Vehicle
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /users/{id}/vehicles [post]
+func CreateVehicle(c *gin.Context, collections Collections) {
+	var req CreateVehicleRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	_, err := FindUserByID(c.Param("id"), collections.Users)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch users"})
+		return
+	}
+
+	if req.Make == "" || req.Model == "" {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Make and model must be non-empty strings"})
+		return
+	}
+
+	if len(req.PlugTypes) == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "A vehicle must support at least one plug type"})
+		return
+	}
+
+	for _, plugType := range req.PlugTypes {
+		if !isPlugType(plugType) {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Unknown plug type %s", plugType)})
+			return
+		}
+	}
+
+	if req.MaxACPower < 0 || req.MaxDCPower < 0 || req.BatteryCapacity <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Charge rates can't be negative and the battery capacity must exceed 0"})
+		return
+	}
+
+	newVehicle := models.Vehicle{
+		ID:              primitive.NewObjectID(),
+		UserID:          c.Param("id"),
+		Make:            req.Make,
+		Model:           req.Model,
+		PlugTypes:       req.PlugTypes,
+		MaxACPower:      req.MaxACPower,
+		MaxDCPower:      req.MaxDCPower,
+		BatteryCapacity: req.BatteryCapacity,
+	}
+
+	_, err = collections.Vehicles.InsertOne(context.Background(), newVehicle)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to create a new vehicle"})
+		return
+	}
+
+	c.JSON(http.StatusOK, newVehicle)
+}
+
+type CreateVehicleRequest struct {
+	Make            string   `json:"make"`
+	Model           string   `json:"model"`
+	PlugTypes       []string `json:"plugTypes"`
+	MaxACPower      float64  `json:"maxACPower"`
+	MaxDCPower      float64  `json:"maxDCPower"`
+	BatteryCapacity float64  `json:"batteryCapacity"`
+}
+
+// FindVehicleByID godoc
+// @Summary Get information about a vehicle by ID
+// @Tags Vehicles
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Success 200 {object} models.Vehicle
+// @Failure 404 {object} models.ErrorResponse
+// @Router /vehicles/{id} [get]
+func FindVehicleByID(id string, collection *mongo.Collection) (models.Vehicle, error) {
+	var vehicle models.Vehicle
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return models.Vehicle{}, mongo.ErrNoDocuments
+	}
+
+	err = collection.FindOne(context.Background(), bson.M{"_id": objectID}).Decode(&vehicle)
+	if err != nil {
+		return models.Vehicle{}, err
+	}
+
+	return vehicle, nil
+}
+
+// GetUserVehicles godoc
+// @Summary Get all of a user's vehicles
+// @Tags Vehicles
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} []models.Vehicle
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/vehicles [get]
+func GetUserVehicles(userID string, collection *mongo.Collection) ([]models.Vehicle, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"userId": userID})
+	if err != nil {
+		return []models.Vehicle{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	vehicles := []models.Vehicle{}
+	if err := cursor.All(context.Background(), &vehicles); err != nil {
+		return []models.Vehicle{}, err
+	}
+
+	return vehicles, nil
+}
+
+// checkCompatibility reports whether the vehicle can charge on the connector, and warns about anything that limits charging. Connectors without details are assumed to be compatible.
+func checkCompatibility(vehicle models.Vehicle, connector models.Connector) (bool, []string) {
+	if connector.PlugType == "" {
+		return true, []string{"The connector's plug type is unknown, make sure it fits the vehicle"}
+	}
+
+	supported := false
+	for _, plugType := range vehicle.PlugTypes {
+		if plugType == connector.PlugType {
+			supported = true
+			break
+		}
+	}
+
+	if !supported {
+		return false, nil
+	}
+
+	vehiclePower := vehicle.MaxACPower
+	if connector.CurrentType == "DC" {
+		vehiclePower = vehicle.MaxDCPower
+	}
+
+	if vehiclePower == 0 {
+		return false, nil
+	}
+
+	var warnings []string
+	if connector.MaxPower > vehiclePower {
+		warnings = append(warnings, fmt.Sprintf("The vehicle can only charge at %.1f kW out of the connector's %.1f kW", vehiclePower, connector.MaxPower))
+	}
+
+	return true, warnings
+}
+
+// chargingPower is the power (in kW) the vehicle can draw from a compatible connector
+func chargingPower(vehicle models.Vehicle, connector models.Connector) float64 {
+	vehiclePower := vehicle.MaxACPower
+	if connector.CurrentType == "DC" {
+		vehiclePower = vehicle.MaxDCPower
+	}
+
+	if connector.MaxPower == 0 || vehiclePower < connector.MaxPower {
+		return vehiclePower
+	}
+
+	return connector.MaxPower
+}
+
+// SuggestConnectors godoc
+// @Summary Suggest available connectors for a vehicle
+// @Description Lists every available connector the vehicle is compatible with, fastest first. Connectors without details (plug type, power) are left out since their compatibility is unknown.
+// @Tags Vehicles
+// @Produce json
+// @Param id path string true "Vehicle ID"
+// @Success 200 {object} []models.CompatibleConnector
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /vehicles/{id}/connectors [get]
+func SuggestConnectors(vehicle models.Vehicle, chargepointsCollection *mongo.Collection) ([]models.CompatibleConnector, error) {
+	cursor, err := chargepointsCollection.Find(context.Background(), bson.M{"connectors": bson.M{"$elemMatch": bson.M{"state": "Available", "plugType": bson.M{"$in": vehicle.PlugTypes}}}})
+	if err != nil {
+		return []models.CompatibleConnector{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	var chargepoints []models.Chargepoint
+	if err := cursor.All(context.Background(), &chargepoints); err != nil {
+		return []models.CompatibleConnector{}, err
+	}
+
+	suggestions := []models.CompatibleConnector{}
+	for _, chargepoint := range chargepoints {
+		for _, connector := range chargepoint.Connectors {
+			if connector.State != "Available" || connector.PlugType == "" {
+				continue
+			}
+
+			if compatible, _ := checkCompatibility(vehicle, connector); !compatible {
+				continue
+			}
+
+			suggestions = append(suggestions, models.CompatibleConnector{
+				Chargepoint: chargepoint.ID,
+				Connector:   connector.ID,
+				PlugType:    connector.PlugType,
+				CurrentType: connector.CurrentType,
+				MaxPower:    connector.MaxPower,
+				Power:       chargingPower(vehicle, connector),
+			})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Power > suggestions[j].Power
+	})
+
+	return suggestions, nil
+}
@@ -0,0 +1,137 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	vehicle := models.Vehicle{PlugTypes: []string{"Type2", "CCS2"}, MaxACPower: 11, MaxDCPower: 150}
+
+	tests := []struct {
+		connector  models.Connector
+		compatible bool
+		warnings   int
+		power      float64
+	}{
+		{connector: models.Connector{PlugType: "Type2", CurrentType: "AC", MaxPower: 11}, compatible: true, warnings: 0, power: 11},
+		{connector: models.Connector{PlugType: "Type2", CurrentType: "AC", MaxPower: 22}, compatible: true, warnings: 1, power: 11},
+		{connector: models.Connector{PlugType: "CCS2", CurrentType: "DC", MaxPower: 50}, compatible: true, warnings: 0, power: 50},
+		{connector: models.Connector{PlugType: "CHAdeMO", CurrentType: "DC", MaxPower: 50}, compatible: false},
+		{connector: models.Connector{}, compatible: true, warnings: 1},
+	}
+
+	for _, test := range tests {
+		compatible, warnings := checkCompatibility(vehicle, test.connector)
+		if compatible != test.compatible {
+			t.Errorf("Expected compatibility %t with %s, but received %t", test.compatible, test.connector.PlugType, compatible)
+		}
+
+		if len(warnings) != test.warnings {
+			t.Errorf("Expected %d warnings with %s, but received %d", test.warnings, test.connector.PlugType, len(warnings))
+		}
+
+		if compatible && test.connector.PlugType != "" && chargingPower(vehicle, test.connector) != test.power {
+			t.Errorf("Expected %.1f kW with %s, but received %.1f kW", test.power, test.connector.PlugType, chargingPower(vehicle, test.connector))
+		}
+	}
+}
+
+func TestVehicles(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/users/:id/vehicles", func(c *gin.Context) {
+		CreateVehicle(c, collections)
+	})
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Vehicles} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "driver", Name: "Driver"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "vehicleChargepoint", Connectors: []models.Connector{
+		{ID: 1, State: "Available", PlugType: "CHAdeMO", CurrentType: "DC", MaxPower: 50},
+		{ID: 2, State: "Available", PlugType: "CCS2", CurrentType: "DC", MaxPower: 150},
+	}})
+
+	var vehicle models.Vehicle
+
+	t.Run("CreateVehicle", func(t *testing.T) {
+		body, _ := json.Marshal(map[string]any{"make": "Volkswagen", "model": "ID.3", "plugTypes": []string{"Type2", "CCS2"}, "maxACPower": 11, "maxDCPower": 120, "batteryCapacity": 58})
+		req, _ := http.NewRequest("POST", "/users/driver/vehicles", bytes.NewReader(body))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, recorder.Code)
+		}
+
+		json.Unmarshal(recorder.Body.Bytes(), &vehicle)
+	})
+
+	t.Run("SuggestConnectors", func(t *testing.T) {
+		suggestions, err := SuggestConnectors(vehicle, collections.Chargepoints)
+		if err != nil {
+			t.Fatalf("Could not suggest connectors:\n%v", err)
+		}
+
+		if len(suggestions) != 1 || suggestions[0].Connector != 2 {
+			t.Errorf("Expected only connector 2 to be suggested, but received %v", suggestions)
+		}
+	})
+
+	tests := []struct {
+		connector  int
+		createCode int
+	}{
+		{connector: 1, createCode: http.StatusBadRequest},
+		{connector: 2, createCode: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run("CreateReservationWithVehicle", func(t *testing.T) {
+			body, _ := json.Marshal(map[string]any{"userId": "driver", "minutes": 45, "vehicleId": vehicle.ID.Hex()})
+			req, _ := http.NewRequest("POST", fmt.Sprint("/reservations/vehicleChargepoint/", test.connector), bytes.NewReader(body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.createCode {
+				t.Errorf("Expected code %d, but received %d", test.createCode, recorder.Code)
+			} else {
+				t.Logf("Received the correct code %d", test.createCode)
+			}
+		})
+	}
+}
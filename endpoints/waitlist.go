@@ -0,0 +1,375 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Waitlist entry statuses
+const (
+	WaitlistWaiting  = "Waiting"
+	WaitlistOffered  = "Offered"
+	WaitlistAccepted = "Accepted"
+	WaitlistExpired  = "Expired"
+	WaitlistLeft     = "Left"
+)
+
+// waitlistOfferMinutes is how long a freed connector is held for the user at the head of the queue
+func waitlistOfferMinutes() int {
+	return envInt("WAITLIST_OFFER_MINUTES", 5)
+}
+
+// setConnectorState changes the connector's state only if it's still in the expected one, telling whether it did
+func setConnectorState(chargepointID string, connector int, from, to string, collection *mongo.Collection) (bool, error) {
+	filter := bson.M{"_id": chargepointID, "connectors": bson.M{"$elemMatch": bson.M{"_id": connector, "state": from}}}
+	result, err := collection.UpdateOne(context.Background(), filter, bson.M{"$set": bson.M{"connectors.$.state": to}})
+	if err != nil {
+		return false, err
+	}
+
+	return result.ModifiedCount > 0, nil
+}
+
+// offerConnectors offers every available connector of the chargepoint to the longest waiting user it suits. The connector is held for them until the offer expires.
+func offerConnectors(chargepointID string, collections Collections) error {
+	chargepoint, err := FindChargepointByID(chargepointID, collections.Chargepoints)
+	if err != nil {
+		return err
+	}
+
+	for _, connector := range chargepoint.Connectors {
+		if connector.State != "Available" {
+			continue
+		}
+
+		// The connector has to stay free for the offer and a short reservation
+		booking, err := nextBooking(chargepoint.ID, connector.ID, collections.Reservations)
+		if err != nil {
+			return err
+		}
+		if booking != nil && booking.StartTime.Before(time.Now().Add(time.Duration(waitlistOfferMinutes()+30)*time.Minute)) {
+			continue
+		}
+
+		filter := bson.M{
+			"chargepoint": chargepoint.ID,
+			"status":      WaitlistWaiting,
+			"$or":         bson.A{bson.M{"plugType": bson.M{"$exists": false}}, bson.M{"plugType": connector.PlugType}},
+		}
+
+		var entry models.WaitlistEntry
+		err = collections.Waitlist.FindOne(context.Background(), filter, options.FindOne().SetSort(bson.M{"joinedAt": 1})).Decode(&entry)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				continue
+			}
+			return err
+		}
+
+		claimed, err := setConnectorState(chargepoint.ID, connector.ID, "Available", "Offered", collections.Chargepoints)
+		if err != nil {
+			return err
+		}
+
+		// The connector was taken in the meantime, the user waits for the next one
+		if !claimed {
+			continue
+		}
+
+		update := bson.M{"status": WaitlistOffered, "offeredConnector": connector.ID, "offerExpiresAt": time.Now().Add(time.Duration(waitlistOfferMinutes()) * time.Minute)}
+		result, err := collections.Waitlist.UpdateOne(context.Background(), bson.M{"_id": entry.ID, "status": WaitlistWaiting}, bson.M{"$set": update})
+		if err != nil {
+			return err
+		}
+
+		// The user left the queue in the meantime
+		if result.ModifiedCount == 0 {
+			if _, err := setConnectorState(chargepoint.ID, connector.ID, "Offered", "Available", collections.Chargepoints); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// findOffer returns the user's offer of the connector, or nil when there is none
+func findOffer(chargepointID string, connector int, userID string, collection *mongo.Collection) (*models.WaitlistEntry, error) {
+	var entry models.WaitlistEntry
+	err := collection.FindOne(context.Background(), bson.M{
+		"chargepoint":      chargepointID,
+		"offeredConnector": connector,
+		"userId":           userID,
+		"status":           WaitlistOffered,
+		"offerExpiresAt":   bson.M{"$gt": time.Now()},
+	}).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &entry, nil
+}
+
+// checkWaitlists expires the offers that weren't taken in time, then offers free connectors to the next users in line
+func checkWaitlists(collections Collections) {
+	cursor, err := collections.Waitlist.Find(context.Background(), bson.M{"status": WaitlistOffered, "offerExpiresAt": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		fmt.Println("Error getting waitlist offers: ", err)
+		return
+	}
+
+	var expired []models.WaitlistEntry
+	if err := cursor.All(context.Background(), &expired); err != nil {
+		fmt.Println("Error decoding waitlist offers: ", err)
+		return
+	}
+
+	for _, entry := range expired {
+		result, err := collections.Waitlist.UpdateOne(context.Background(), bson.M{"_id": entry.ID, "status": WaitlistOffered}, bson.M{"$set": bson.M{"status": WaitlistExpired}})
+		if err != nil || result.ModifiedCount == 0 {
+			continue
+		}
+
+		_, err = setConnectorState(entry.Chargepoint, entry.OfferedConnector, "Offered", "Available", collections.Chargepoints)
+		if err != nil {
+			fmt.Println("Error releasing offered connector: ", err)
+		}
+	}
+
+	chargepointIDs, err := collections.Waitlist.Distinct(context.Background(), "chargepoint", bson.M{"status": WaitlistWaiting})
+	if err != nil {
+		fmt.Println("Error getting waitlists: ", err)
+		return
+	}
+
+	for _, id := range chargepointIDs {
+		chargepointID, ok := id.(string)
+		if !ok {
+			continue
+		}
+
+		if err := offerConnectors(chargepointID, collections); err != nil {
+			fmt.Println("Error offering connectors: ", err)
+		}
+	}
+}
+
+// withPositions sets the queue position of every waiting entry. Only the entries that joined earlier and could be offered the same connectors are ahead: an entry waiting for a plug type isn't passed by ones waiting for another.
+func withPositions(entries []models.WaitlistEntry, collection *mongo.Collection) ([]models.WaitlistEntry, error) {
+	for i, entry := range entries {
+		if entry.Status != WaitlistWaiting {
+			continue
+		}
+
+		filter := bson.M{"chargepoint": entry.Chargepoint, "status": WaitlistWaiting, "joinedAt": bson.M{"$lt": entry.JoinedAt}}
+		if entry.PlugType != "" {
+			filter["$or"] = bson.A{bson.M{"plugType": bson.M{"$exists": false}}, bson.M{"plugType": entry.PlugType}}
+		}
+
+		ahead, err := collection.CountDocuments(context.Background(), filter)
+		if err != nil {
+			return nil, err
+		}
+		entries[i].Position = int(ahead) + 1
+	}
+
+	return entries, nil
+}
+
+func findWaitlistEntries(filter bson.M, collection *mongo.Collection) ([]models.WaitlistEntry, error) {
+	cursor, err := collection.Find(context.Background(), filter, options.Find().SetSort(bson.M{"joinedAt": 1}))
+	if err != nil {
+		return []models.WaitlistEntry{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	entries := []models.WaitlistEntry{}
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return []models.WaitlistEntry{}, err
+	}
+
+	return withPositions(entries, collection)
+}
+
+// JoinWaitlist godoc
+// @Summary Join a chargepoint's waitlist
+// @Description Queues the user for the chargepoint when all of its connectors (with the plug type, if given) are taken. When one frees up, it's offered to the longest waiting user: the connector is held for them for WAITLIST_OFFER_MINUTES, during which they can reserve it as usual. Offers that aren't taken in time go to the next user in line.
+// @Tags Waitlist
+// @Accept json
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param body body JoinWaitlistRequest true "Request body"
+// @Success 200 {object} models.WaitlistEntry
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /waitlist/{chargepointID} [post]
+func JoinWaitlist(c *gin.Context, collections Collections) {
+	var req JoinWaitlistRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	_, err := FindUserByID(req.UserID, collections.Users)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch users"})
+		return
+	}
+
+	chargepoint, err := FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Chargepoint does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch chargepoints"})
+		return
+	}
+
+	suitable, available := 0, 0
+	for _, connector := range chargepoint.Connectors {
+		if req.PlugType != "" && connector.PlugType != req.PlugType {
+			continue
+		}
+
+		suitable++
+		if connector.State == "Available" {
+			available++
+		}
+	}
+
+	if suitable == 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "The chargepoint has no connector with that plug type"})
+		return
+	}
+
+	if available > 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "A connector is available, reserve it instead"})
+		return
+	}
+
+	waiting, err := collections.Waitlist.CountDocuments(context.Background(), bson.M{"chargepoint": chargepoint.ID, "userId": req.UserID, "status": bson.M{"$in": bson.A{WaitlistWaiting, WaitlistOffered}}})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the waitlist"})
+		return
+	}
+
+	if waiting > 0 {
+		c.JSON(http.StatusConflict, models.ErrorResponse{Error: "The user is already on the chargepoint's waitlist"})
+		return
+	}
+
+	entry := models.WaitlistEntry{
+		Chargepoint: chargepoint.ID,
+		UserID:      req.UserID,
+		PlugType:    req.PlugType,
+		Status:      WaitlistWaiting,
+		JoinedAt:    time.Now(),
+	}
+
+	result, err := collections.Waitlist.InsertOne(context.Background(), entry)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not join the waitlist"})
+		return
+	}
+
+	var entries []models.WaitlistEntry
+	err = collections.Waitlist.FindOne(context.Background(), bson.M{"_id": result.InsertedID}).Decode(&entry)
+	if err == nil {
+		entries, err = withPositions([]models.WaitlistEntry{entry}, collections.Waitlist)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the waitlist"})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries[0])
+}
+
+type JoinWaitlistRequest struct {
+	UserID string `json:"userId"`
+	// Optional, only connectors with this plug type are offered
+	PlugType string `json:"plugType"`
+}
+
+// LeaveWaitlist godoc
+// @Summary Leave a chargepoint's waitlist
+// @Description A connector that was offered to the user is offered to the next user in line.
+// @Tags Waitlist
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Param userId query string true "User ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /waitlist/{chargepointID} [delete]
+func LeaveWaitlist(c *gin.Context, collections Collections) {
+	var entry models.WaitlistEntry
+	filter := bson.M{"chargepoint": c.Param("cpID"), "userId": c.Query("userId"), "status": bson.M{"$in": bson.A{WaitlistWaiting, WaitlistOffered}}}
+
+	err := collections.Waitlist.FindOneAndUpdate(context.Background(), filter, bson.M{"$set": bson.M{"status": WaitlistLeft}}).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "The user isn't on the chargepoint's waitlist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not leave the waitlist"})
+		return
+	}
+
+	if entry.Status == WaitlistOffered {
+		_, err := setConnectorState(entry.Chargepoint, entry.OfferedConnector, "Offered", "Available", collections.Chargepoints)
+		if err == nil {
+			err = offerConnectors(entry.Chargepoint, collections)
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not offer the connector to the next user"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, models.MessageResponse{Message: "Left the waitlist"})
+}
+
+// GetWaitlist godoc
+// @Summary Get a chargepoint's waitlist
+// @Description Lists the users waiting for the chargepoint or holding an offer, in the order they joined.
+// @Tags Waitlist
+// @Produce json
+// @Param chargepointID path string true "Chargepoint ID"
+// @Success 200 {object} []models.WaitlistEntry
+// @Failure 500 {object} models.ErrorResponse
+// @Router /waitlist/{chargepointID} [get]
+func GetWaitlist(chargepointID string, collection *mongo.Collection) ([]models.WaitlistEntry, error) {
+	return findWaitlistEntries(bson.M{"chargepoint": chargepointID, "status": bson.M{"$in": bson.A{WaitlistWaiting, WaitlistOffered}}}, collection)
+}
+
+// GetUserWaitlists godoc
+// @Summary Get the waitlists a user is on
+// @Description Lists the user's places in queues, with their position while waiting, and the connectors offered to them.
+// @Tags Waitlist
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} []models.WaitlistEntry
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/waitlist [get]
+func GetUserWaitlists(userID string, collection *mongo.Collection) ([]models.WaitlistEntry, error) {
+	return findWaitlistEntries(bson.M{"userId": userID, "status": bson.M{"$in": bson.A{WaitlistWaiting, WaitlistOffered}}}, collection)
+}
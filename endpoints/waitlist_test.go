@@ -0,0 +1,153 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWaitlist(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/waitlist/:cpID", func(c *gin.Context) {
+		JoinWaitlist(c, collections)
+	})
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Waitlist} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "waitlistFirst", Name: "First in line"})
+	collections.Users.InsertOne(context.Background(), models.User{ID: "waitlistSecond", Name: "Second in line"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "waitlistChargepoint", Connectors: []models.Connector{
+		{ID: 1, State: "Charging", PlugType: "Type2"},
+	}})
+
+	request := func(endpoint string, body any) int {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	tests := []struct {
+		name string
+		body any
+		code int
+	}{
+		{name: "UnknownUser", body: map[string]any{"userId": "nobody"}, code: http.StatusBadRequest},
+		{name: "UnknownPlugType", body: map[string]any{"userId": "waitlistFirst", "plugType": "CHAdeMO"}, code: http.StatusBadRequest},
+		{name: "First", body: map[string]any{"userId": "waitlistFirst", "plugType": "Type2"}, code: http.StatusOK},
+		{name: "AlreadyQueued", body: map[string]any{"userId": "waitlistFirst"}, code: http.StatusConflict},
+		{name: "Second", body: map[string]any{"userId": "waitlistSecond"}, code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := request("/waitlist/waitlistChargepoint", test.body)
+			if code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, code)
+			}
+		})
+	}
+
+	t.Run("Positions", func(t *testing.T) {
+		entries, err := GetWaitlist("waitlistChargepoint", collections.Waitlist)
+		if err != nil {
+			t.Fatalf("Could not fetch the waitlist:\n%v", err)
+		}
+
+		if len(entries) != 2 || entries[0].UserID != "waitlistFirst" || entries[0].Position != 1 || entries[1].Position != 2 {
+			t.Errorf("Expected both users in the order they joined, but received %v", entries)
+		}
+	})
+
+	t.Run("PositionsByPlugType", func(t *testing.T) {
+		joined := time.Now().Add(-time.Hour)
+		for i, plugType := range []string{"CCS", "Type2", ""} {
+			collections.Waitlist.InsertOne(context.Background(), models.WaitlistEntry{Chargepoint: "waitlistMixedChargepoint", UserID: fmt.Sprintf("waitlistMixed%d", i), PlugType: plugType, Status: WaitlistWaiting, JoinedAt: joined.Add(time.Duration(i) * time.Minute)})
+		}
+
+		entries, err := GetWaitlist("waitlistMixedChargepoint", collections.Waitlist)
+		if err != nil {
+			t.Fatalf("Could not fetch the waitlist:\n%v", err)
+		}
+
+		if len(entries) != 3 || entries[0].Position != 1 || entries[1].Position != 1 || entries[2].Position != 3 {
+			t.Errorf("Expected the Type2 entry to be first for its plug type and the one without a plug type to be behind both, but received %v", entries)
+		}
+	})
+
+	t.Run("OfferFreedConnector", func(t *testing.T) {
+		collections.Chargepoints.UpdateOne(context.Background(), bson.M{"_id": "waitlistChargepoint"}, bson.M{"$set": bson.M{"connectors.0.state": "Available"}})
+
+		err := offerConnectors("waitlistChargepoint", collections)
+		if err != nil {
+			t.Fatalf("Could not offer the connector:\n%v", err)
+		}
+
+		chargepoint, _ := FindChargepointByID("waitlistChargepoint", collections.Chargepoints)
+		if chargepoint.Connectors[0].State != "Offered" {
+			t.Errorf("Expected the connector to be offered, but it's %s", chargepoint.Connectors[0].State)
+		}
+
+		// Only the user it's offered to can reserve it
+		if code := request("/reservations/waitlistChargepoint/1", map[string]any{"userId": "waitlistSecond", "minutes": 30}); code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, but received %d", http.StatusBadRequest, code)
+		}
+	})
+
+	t.Run("ExpiredOffer", func(t *testing.T) {
+		collections.Waitlist.UpdateOne(context.Background(), bson.M{"userId": "waitlistFirst"}, bson.M{"$set": bson.M{"offerExpiresAt": time.Now().Add(-time.Minute)}})
+
+		checkWaitlists(collections)
+
+		entries, _ := GetUserWaitlists("waitlistSecond", collections.Waitlist)
+		if len(entries) != 1 || entries[0].Status != WaitlistOffered || entries[0].OfferedConnector != 1 {
+			t.Fatalf("Expected the connector to be offered to the next user, but received %v", entries)
+		}
+
+		if code := request("/reservations/waitlistChargepoint/1", map[string]any{"userId": "waitlistSecond", "minutes": 30}); code != http.StatusOK {
+			t.Errorf("Expected code %d, but received %d", http.StatusOK, code)
+		}
+
+		entries, _ = GetUserWaitlists("waitlistSecond", collections.Waitlist)
+		if len(entries) != 0 {
+			t.Errorf("Expected the offer to be accepted, but received %v", entries)
+		}
+	})
+}
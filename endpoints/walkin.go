@@ -0,0 +1,187 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// How long a walk-in can charge for, in minutes
+const (
+	walkInMinMinutes = 10
+	walkInMaxMinutes = 180
+)
+
+// nextBooking returns the connector's earliest reservation that hasn't ended yet, or nil when there is none. A booking that already started but hasn't taken the connector yet is returned too, since its user can still show up.
+func nextBooking(chargepointID string, connector int, reservationsCollection *mongo.Collection) (*models.Reservation, error) {
+	filter := bson.M{
+		"chargepoint":         chargepointID,
+		"connector":           connector,
+		"hasFinishedCharging": false,
+		"chargingTime":        bson.M{"$gt": time.Now()},
+	}
+
+	var reservation models.Reservation
+	err := reservationsCollection.FindOne(context.Background(), filter, options.FindOne().SetSort(bson.M{"startTime": 1})).Decode(&reservation)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// walkIn records a reservation for a user charging on an available connector without reserving it first. The charging time is cut short when the connector is booked by someone else later on, and the walk-in is refused when the booking is too close.
+// The user needs access to the connector like for a reservation, and its estimated price is set aside like a reservation's. The response is already written when ok is false.
+func walkIn(c *gin.Context, user models.User, chargepoint models.Chargepoint, connectorNumber int, minutes int, paymentMethod string, collections Collections) (reservation models.Reservation, warnings []string, ok bool) {
+	if minutes == 0 {
+		minutes = walkInMaxMinutes
+	}
+
+	if minutes < walkInMinMinutes || minutes > walkInMaxMinutes {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("Charging without a reservation must last between %d and %d minutes", walkInMinMinutes, walkInMaxMinutes)})
+		return models.Reservation{}, nil, false
+	}
+
+	now := time.Now()
+
+	booking, err := nextBooking(chargepoint.ID, connectorNumber, collections.Reservations)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch reservations"})
+		return models.Reservation{}, nil, false
+	}
+
+	policy, err := resolveReservationPolicy(chargepoint, connectorNumber, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connector's reservation policy"})
+		return models.Reservation{}, nil, false
+	}
+
+	// The vehicle has to be gone the buffer before the booking starts
+	if booking != nil {
+		leaveBy := booking.StartTime.Add(-time.Duration(policy.BufferMinutes) * time.Minute)
+		if leaveBy.Before(now.Add(time.Duration(minutes) * time.Minute)) {
+			available := int(leaveBy.Sub(now).Minutes())
+			if available < walkInMinMinutes {
+				c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("The connector is reserved from %s, reserve another connector instead", booking.StartTime.Format("15:04"))})
+				return models.Reservation{}, nil, false
+			}
+
+			minutes = available
+			warnings = append(warnings, fmt.Sprintf("Charging is limited to %d minutes because the connector is reserved from %s", minutes, booking.StartTime.Format("15:04")))
+		}
+	}
+
+	maintenance, err := underMaintenance(chargepoint.ID, connectorNumber, now, now.Add(time.Duration(minutes)*time.Minute), collections.Maintenance)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch maintenance windows"})
+		return models.Reservation{}, nil, false
+	}
+
+	if maintenance != nil {
+		available := int(maintenance.Start.Sub(now).Minutes())
+		if available < walkInMinMinutes {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: fmt.Sprintf("The connector is under maintenance until %s", maintenance.End.Format("15:04"))})
+			return models.Reservation{}, nil, false
+		}
+
+		minutes = available
+		warnings = append(warnings, fmt.Sprintf("Charging is limited to %d minutes because the connector is under maintenance from %s", minutes, maintenance.Start.Format("15:04")))
+	}
+
+	class, _, accessErr := checkAccess(user, chargepoint, connectorNumber, policy, now, minutes, 0, true, collections)
+	if accessErr != nil {
+		c.JSON(accessErr.Status, accessErr.Body)
+		return models.Reservation{}, nil, false
+	}
+
+	tariff, err := resolveTariff(chargepoint, connectorNumber, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to fetch the connector's tariff"})
+		return models.Reservation{}, nil, false
+	}
+
+	powerLimit, limit, err := checkSiteCapacity(chargepoint, connectorNumber, chargepoint.Connectors[connectorNumber-1].MaxPower, now, now.Add(time.Duration(minutes)*time.Minute), collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not check the site's capacity"})
+		return models.Reservation{}, nil, false
+	}
+	if limit != nil {
+		c.JSON(http.StatusForbidden, limit)
+		return models.Reservation{}, nil, false
+	}
+	if powerLimit > 0 {
+		warnings = append(warnings, fmt.Sprintf("Charging is limited to %.1f kW because the site's grid connection is shared", powerLimit))
+	}
+
+	adjustments, err := dynamicAdjustments(chargepoint, now, now.Add(time.Duration(minutes)*time.Minute), collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Unable to apply the site's pricing policy"})
+		return models.Reservation{}, nil, false
+	}
+
+	reservation = models.Reservation{
+		ID:             primitive.NewObjectID(),
+		Chargepoint:    chargepoint.ID,
+		Connector:      connectorNumber,
+		UserID:         user.ID,
+		ExpiryTime:     now.Add(10 * time.Minute),
+		ChargingTime:   now.Add(time.Duration(minutes) * time.Minute),
+		Minutes:        minutes,
+		CreatedAt:      now,
+		StartTime:      now,
+		OrganizationID: user.OrganizationID,
+		TariffID:       tariff.ID,
+		WalkIn:         true,
+		Adjustments:    adjustments,
+		PowerLimit:     powerLimit,
+		Priority:       user.Priority,
+		PriorityClass:  class.ID,
+	}
+
+	reservation.PaymentMode, err = paymentModeOf(user, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch the user's wallet"})
+		return models.Reservation{}, nil, false
+	}
+
+	connector := chargepoint.Connectors[connectorNumber-1]
+	if powerLimit > 0 {
+		connector.MaxPower = powerLimit
+	}
+
+	estimate := ApplyAdjustments(ComputePrice(tariff, Usage{
+		Start:  reservation.StartTime,
+		End:    reservation.ChargingTime,
+		Energy: estimateEnergy(nil, connector, minutes),
+	}), adjustments)
+
+	if paymentErr := securePayment(user, reservation, estimate, paymentMethod, collections); paymentErr != nil {
+		c.JSON(paymentErr.Status, paymentErr.Body)
+		return models.Reservation{}, nil, false
+	}
+
+	err = insertReservation(&reservation, collections.Reservations)
+	if err != nil {
+		if err := releaseHold(reservation.ID, collections); err != nil {
+			fmt.Println("Error releasing wallet hold: ", err)
+		}
+		if err := voidPayment(reservation.ID, collections); err != nil {
+			fmt.Println("Error voiding payment: ", err)
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to record charging without a reservation"})
+		return models.Reservation{}, nil, false
+	}
+
+	return reservation, warnings, true
+}
@@ -0,0 +1,134 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWalkIn(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/charge/:cpID/:coID", func(c *gin.Context) {
+		Charge(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Sessions, collections.Tariffs, collections.Wallets, collections.Ledger} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "walkInUser", Name: "Walk-in user"})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "walkInChargepoint", Connectors: []models.Connector{
+		{ID: 1, State: "Available"},
+		{ID: 2, State: "Available"},
+		{ID: 3, State: "Available"},
+		{ID: 4, State: "Unavailable"},
+		{ID: 5, State: "Available"},
+	}})
+	// Charging on this chargepoint costs more than the prepaid user has left
+	collections.Users.InsertOne(context.Background(), models.User{ID: "walkInPrepaidUser", Name: "Prepaid walk-in user"})
+	collections.Wallets.InsertOne(context.Background(), models.Wallet{UserID: "walkInPrepaidUser", Currency: "EUR", Balance: 100})
+	collections.Tariffs.InsertOne(context.Background(), models.Tariff{ID: "walkInTariff", Name: "Paid", Currency: "EUR", PerMinute: 10, Bands: []models.TariffBand{}})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "walkInPaidChargepoint", TariffID: "walkInTariff", Connectors: []models.Connector{
+		{ID: 1, State: "Available"},
+	}})
+	// Only the fleet may use this chargepoint
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "walkInFleetChargepoint", ReservationPolicy: &models.ReservationPolicy{AllowedOrganizations: []string{"walkInFleet"}}, Connectors: []models.Connector{
+		{ID: 1, State: "Available"},
+	}})
+	// Someone else booked connector 2 in an hour and connector 3 in 5 minutes, and their booking of connector 5 started but wasn't activated yet
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "walkInChargepoint", Connector: 2, UserID: "someoneElse", StartTime: time.Now().Add(time.Hour), ExpiryTime: time.Now().Add(70 * time.Minute), ChargingTime: time.Now().Add(2 * time.Hour)})
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "walkInChargepoint", Connector: 3, UserID: "someoneElse", StartTime: time.Now().Add(5 * time.Minute), ExpiryTime: time.Now().Add(15 * time.Minute), ChargingTime: time.Now().Add(time.Hour)})
+	collections.Reservations.InsertOne(context.Background(), models.Reservation{ID: primitive.NewObjectID(), Chargepoint: "walkInChargepoint", Connector: 5, UserID: "someoneElse", StartTime: time.Now().Add(-2 * time.Minute), ExpiryTime: time.Now().Add(8 * time.Minute), ChargingTime: time.Now().Add(time.Hour)})
+
+	tests := []struct {
+		name      string
+		user      string
+		connector string
+		minutes   int
+		code      int
+	}{
+		{name: "TooShort", user: "walkInUser", connector: "walkInChargepoint/1", minutes: 5, code: http.StatusBadRequest},
+		{name: "WalkIn", user: "walkInUser", connector: "walkInChargepoint/1", code: http.StatusOK},
+		{name: "LimitedByBooking", user: "walkInUser", connector: "walkInChargepoint/2", minutes: 120, code: http.StatusOK},
+		{name: "BookedTooSoon", user: "walkInUser", connector: "walkInChargepoint/3", code: http.StatusBadRequest},
+		{name: "BookingStarted", user: "walkInUser", connector: "walkInChargepoint/5", code: http.StatusBadRequest},
+		{name: "ConnectorUnavailable", user: "walkInUser", connector: "walkInChargepoint/4", code: http.StatusBadRequest},
+		{name: "NotAllowed", user: "walkInUser", connector: "walkInFleetChargepoint/1", code: http.StatusForbidden},
+		{name: "InsufficientFunds", user: "walkInPrepaidUser", connector: "walkInPaidChargepoint/1", minutes: 60, code: http.StatusPaymentRequired},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]any{"userId": test.user, "minutes": test.minutes})
+			req, _ := http.NewRequest("POST", "/charge/"+test.connector, bytes.NewReader(body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			if recorder.Code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, recorder.Code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+
+	t.Run("WalkInRecorded", func(t *testing.T) {
+		var reservation models.Reservation
+		err := collections.Reservations.FindOne(context.Background(), bson.M{"connector": 2, "walkIn": true}).Decode(&reservation)
+		if err != nil {
+			t.Fatalf("Could not find the walk-in:\n%v", err)
+		}
+
+		if !reservation.HasStartedCharging || reservation.ChargingTime.After(time.Now().Add(time.Hour)) {
+			t.Errorf("Expected the walk-in to charge until the next booking at most, but it charges until %v", reservation.ChargingTime)
+		}
+
+		sessions, err := GetSessions(bson.M{"reservationId": reservation.ID}, collections.Sessions)
+		if err != nil {
+			t.Fatalf("Could not fetch sessions:\n%v", err)
+		}
+
+		if len(sessions) != 1 || !sessions[0].WalkIn {
+			t.Errorf("Expected one walk-in session, but received %v", sessions)
+		}
+
+		limit, err := checkQuotas("walkInUser", 30, primitive.NilObjectID, QuotaPolicy{MaxActiveReservations: 1}, collections.Reservations)
+		if err != nil {
+			t.Fatalf("Could not check quotas:\n%v", err)
+		}
+
+		if limit != nil {
+			t.Errorf("Expected walk-ins not to count towards quotas, but received %v", limit)
+		}
+	})
+}
@@ -0,0 +1,347 @@
+package endpoints
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reservations/models"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Ledger entry types
+const (
+	LedgerTopUp   = "TopUp"
+	LedgerHold    = "Hold"
+	LedgerRelease = "Release"
+	LedgerCapture = "Capture"
+	LedgerRefund  = "Refund"
+)
+
+// QuotaInsufficientFunds is returned when a prepaid user's available balance doesn't cover the reservation
+const QuotaInsufficientFunds = "INSUFFICIENT_FUNDS"
+
+var errInsufficientFunds = errors.New("insufficient funds")
+
+// applyLedgerEntry changes the user's wallet and records the change in the ledger. Holds fail with errInsufficientFunds when the available balance is too low.
+func applyLedgerEntry(entry models.LedgerEntry, collections Collections) (models.LedgerEntry, error) {
+	filter := bson.M{"_id": entry.UserID}
+	var change bson.M
+
+	switch entry.Type {
+	case LedgerTopUp, LedgerRefund:
+		change = bson.M{"balance": entry.Amount}
+	case LedgerCapture:
+		change = bson.M{"balance": -entry.Amount}
+	case LedgerHold:
+		change = bson.M{"held": entry.Amount}
+		filter["$expr"] = bson.M{"$gte": bson.A{bson.M{"$subtract": bson.A{"$balance", "$held"}}, entry.Amount}}
+	case LedgerRelease:
+		change = bson.M{"held": -entry.Amount}
+	default:
+		return models.LedgerEntry{}, fmt.Errorf("unknown ledger entry type %q", entry.Type)
+	}
+
+	// Top-ups open the wallet when the user doesn't have one yet
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetUpsert(entry.Type == LedgerTopUp)
+	update := bson.M{"$inc": change, "$setOnInsert": bson.M{"currency": defaultCurrency()}}
+
+	var wallet models.Wallet
+	err := collections.Wallets.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&wallet)
+	if err != nil {
+		if err == mongo.ErrNoDocuments && entry.Type == LedgerHold {
+			return models.LedgerEntry{}, errInsufficientFunds
+		}
+		return models.LedgerEntry{}, err
+	}
+
+	entry.ID = primitive.NewObjectID()
+	entry.Time = time.Now()
+	entry.Balance = wallet.Balance
+	entry.Held = wallet.Held
+
+	_, err = collections.Ledger.InsertOne(context.Background(), entry)
+	return entry, err
+}
+
+// hasWallet tells whether the user pays from a prepaid wallet. Members of an organization are billed through it instead.
+func hasWallet(user models.User, collection *mongo.Collection) (bool, error) {
+	if user.OrganizationID != "" {
+		return false, nil
+	}
+
+	count, err := collection.CountDocuments(context.Background(), bson.M{"_id": user.ID})
+	return count > 0, err
+}
+
+// placeHold sets the amount aside for the reservation, returning a response describing the shortfall when the available balance is too low
+func placeHold(userID string, reservationID primitive.ObjectID, amount int64, collections Collections) (*models.LimitErrorResponse, error) {
+	_, err := applyLedgerEntry(models.LedgerEntry{UserID: userID, Type: LedgerHold, Amount: amount, ReservationID: reservationID}, collections)
+	if err != errInsufficientFunds {
+		return nil, err
+	}
+
+	wallet, err := FindWalletByUserID(userID, collections.Wallets)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LimitErrorResponse{
+		Error:   fmt.Sprintf("The reservation needs %d cents of the wallet's balance, but only %d are available", amount, wallet.Available),
+		Code:    QuotaInsufficientFunds,
+		Limit:   int(wallet.Available),
+		Current: int(amount),
+	}, nil
+}
+
+// ledgerNet adds up the reservation's ledger entries of the first type and subtracts those of the second, like holds minus releases
+func ledgerNet(reservationID primitive.ObjectID, added, subtracted string, collection *mongo.Collection) (string, int64, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"reservationId": reservationID, "type": bson.M{"$in": bson.A{added, subtracted}}})
+	if err != nil {
+		return "", 0, err
+	}
+	defer cursor.Close(context.Background())
+
+	var entries []models.LedgerEntry
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return "", 0, err
+	}
+
+	userID, net := "", int64(0)
+	for _, entry := range entries {
+		userID = entry.UserID
+		if entry.Type == added {
+			net += entry.Amount
+		} else {
+			net -= entry.Amount
+		}
+	}
+
+	return userID, net, nil
+}
+
+// hasHold tells whether the wallet held anything for the reservation
+func hasHold(reservationID primitive.ObjectID, collection *mongo.Collection) (bool, error) {
+	if reservationID.IsZero() {
+		return false, nil
+	}
+
+	count, err := collection.CountDocuments(context.Background(), bson.M{"reservationId": reservationID, "type": LedgerHold})
+	return count > 0, err
+}
+
+// releaseHold gives back whatever is still held for the reservation
+func releaseHold(reservationID primitive.ObjectID, collections Collections) error {
+	userID, held, err := ledgerNet(reservationID, LedgerHold, LedgerRelease, collections.Ledger)
+	if err != nil || held <= 0 {
+		return err
+	}
+
+	_, err = applyLedgerEntry(models.LedgerEntry{UserID: userID, Type: LedgerRelease, Amount: held, ReservationID: reservationID}, collections)
+	return err
+}
+
+// resizeHold changes what is held for the reservation to the amount, returning what was held before
+func resizeHold(userID string, reservationID primitive.ObjectID, amount int64, collections Collections) (int64, *models.LimitErrorResponse, error) {
+	_, held, err := ledgerNet(reservationID, LedgerHold, LedgerRelease, collections.Ledger)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if amount > held {
+		limit, err := placeHold(userID, reservationID, amount-held, collections)
+		return held, limit, err
+	}
+
+	if amount < held {
+		_, err = applyLedgerEntry(models.LedgerEntry{UserID: userID, Type: LedgerRelease, Amount: held - amount, ReservationID: reservationID}, collections)
+	}
+	return held, nil, err
+}
+
+// releaseDeposit gives back the part of the reservation's hold that is its deposit, once the user showed up to charge
+func releaseDeposit(reservation models.Reservation, collections Collections) error {
+	userID, held, err := ledgerNet(reservation.ID, LedgerHold, LedgerRelease, collections.Ledger)
+	if err != nil || held <= 0 || reservation.Deposit <= 0 {
+		return err
+	}
+
+	amount := reservation.Deposit
+	if amount > held {
+		amount = held
+	}
+
+	_, err = applyLedgerEntry(models.LedgerEntry{UserID: userID, Type: LedgerRelease, Amount: amount, ReservationID: reservation.ID}, collections)
+	return err
+}
+
+// refundWallet gives back whatever was captured from the wallet for the reservation
+func refundWallet(reservationID primitive.ObjectID, collections Collections) error {
+	userID, captured, err := ledgerNet(reservationID, LedgerCapture, LedgerRefund, collections.Ledger)
+	if err != nil || captured <= 0 {
+		return err
+	}
+
+	_, err = applyLedgerEntry(models.LedgerEntry{UserID: userID, Type: LedgerRefund, Amount: captured, ReservationID: reservationID}, collections)
+	return err
+}
+
+// captureSession takes the session's cost from the user's wallet and releases the reservation's hold. The balance can go below zero when charging cost more than was held.
+// Reservations paid from the wallet have a hold in the ledger, which decides it whatever organization the user joined since.
+func captureSession(session models.ChargingSession, collections Collections) error {
+	held, err := hasHold(session.ReservationID, collections.Ledger)
+	if err != nil || !held {
+		return err
+	}
+
+	if err := releaseHold(session.ReservationID, collections); err != nil {
+		return err
+	}
+
+	price, err := GetSessionPrice(session, collections)
+	if err != nil || price.Total <= 0 {
+		return err
+	}
+
+	_, err = applyLedgerEntry(models.LedgerEntry{UserID: session.UserID, Type: LedgerCapture, Amount: price.Total, ReservationID: session.ReservationID, SessionID: session.ID}, collections)
+	return err
+}
+
+// TopUpWallet godoc
+// @Summary Top up a user's wallet
+// @Description Adds the amount (in cents) to the user's prepaid balance, opening the wallet on the first top-up. Once a user has a wallet, reservations hold their estimated price from it and completed sessions are paid from it. Members of an organization are billed through the organization instead.
+// @Tags Wallets
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param body body TopUpRequest true "Request body"
+// @Success 200 {object} models.Wallet
+// @Failure 500 {object} models.ErrorResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /users/{id}/wallet/topup [post]
+func TopUpWallet(c *gin.Context, collections Collections) {
+	var req TopUpRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Invalid request body"})
+		return
+	}
+
+	if req.Amount <= 0 {
+		c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "Amount must be a positive number of cents"})
+		return
+	}
+
+	user, err := FindUserByID(c.Param("id"), collections.Users)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			c.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "User does not exist"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch users"})
+		return
+	}
+
+	_, err = applyLedgerEntry(models.LedgerEntry{UserID: user.ID, Type: LedgerTopUp, Amount: req.Amount}, collections)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not top up the wallet"})
+		return
+	}
+
+	wallet, err := FindWalletByUserID(user.ID, collections.Wallets)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Could not fetch the wallet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, wallet)
+}
+
+type TopUpRequest struct {
+	// Amount in cents
+	Amount int64 `json:"amount"`
+}
+
+// FindWalletByUserID godoc
+// @Summary Get a user's wallet
+// @Tags Wallets
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.Wallet
+// @Failure 404 {object} models.ErrorResponse
+// @Router /users/{id}/wallet [get]
+func FindWalletByUserID(userID string, collection *mongo.Collection) (models.Wallet, error) {
+	var wallet models.Wallet
+
+	err := collection.FindOne(context.Background(), bson.M{"_id": userID}).Decode(&wallet)
+	if err != nil {
+		return models.Wallet{}, err
+	}
+
+	wallet.Available = wallet.Balance - wallet.Held
+	return wallet, nil
+}
+
+// GetWalletTransactions godoc
+// @Summary Get the transaction history of a user's wallet
+// @Description Lists the wallet's ledger entries, newest first.
+// @Tags Wallets
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} []models.LedgerEntry
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/wallet/transactions [get]
+func GetWalletTransactions(userID string, collection *mongo.Collection) ([]models.LedgerEntry, error) {
+	cursor, err := collection.Find(context.Background(), bson.M{"userId": userID}, options.Find().SetSort(bson.M{"time": -1}))
+	if err != nil {
+		return []models.LedgerEntry{}, err
+	}
+	defer cursor.Close(context.Background())
+
+	entries := []models.LedgerEntry{}
+	if err := cursor.All(context.Background(), &entries); err != nil {
+		return []models.LedgerEntry{}, err
+	}
+
+	return entries, nil
+}
+
+// ReconcileWallet godoc
+// @Summary Reconcile a user's wallet with its ledger
+// @Description Adds up every ledger entry of the wallet and compares the totals with its balance and held amount.
+// @Tags Wallets
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.WalletReconciliation
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 500 {object} models.ErrorResponse
+// @Router /users/{id}/wallet/reconcile [get]
+func ReconcileWallet(wallet models.Wallet, collections Collections) (models.WalletReconciliation, error) {
+	entries, err := GetWalletTransactions(wallet.UserID, collections.Ledger)
+	if err != nil {
+		return models.WalletReconciliation{}, err
+	}
+
+	reconciliation := models.WalletReconciliation{Wallet: wallet, Entries: len(entries)}
+	for _, entry := range entries {
+		switch entry.Type {
+		case LedgerTopUp, LedgerRefund:
+			reconciliation.LedgerBalance += entry.Amount
+		case LedgerCapture:
+			reconciliation.LedgerBalance -= entry.Amount
+		case LedgerHold:
+			reconciliation.LedgerHeld += entry.Amount
+		case LedgerRelease:
+			reconciliation.LedgerHeld -= entry.Amount
+		}
+	}
+
+	reconciliation.Consistent = reconciliation.LedgerBalance == wallet.Balance && reconciliation.LedgerHeld == wallet.Held
+	return reconciliation, nil
+}
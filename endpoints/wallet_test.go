@@ -0,0 +1,178 @@
+package endpoints
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reservations/db"
+	"reservations/models"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestWallet(t *testing.T) {
+	err := godotenv.Load("../.env")
+	if err != nil {
+		t.Fatalf("Unable to load environment variables:\n%v", err)
+	}
+
+	client, err := db.Connect()
+	if err != nil {
+		t.Fatalf("Failed to connect to MongoDB:\n%v", err)
+	}
+	collections := NewCollections(client.Database("TestDB"))
+
+	router := gin.Default()
+
+	router.POST("/users/:id/wallet/topup", func(c *gin.Context) {
+		TopUpWallet(c, collections)
+	})
+
+	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
+		CreateReservation(c, collections)
+	})
+
+	router.DELETE("/reservations/:id", func(c *gin.Context) {
+		CancelReservation(c, collections)
+	})
+
+	router.POST("/charge/:cpID/:coID", func(c *gin.Context) {
+		Charge(c, collections)
+	})
+
+	router.POST("/stop/:cpID/:coID", func(c *gin.Context) {
+		StopCharging(c, collections)
+	})
+
+	defer func() {
+		for _, collection := range []*mongo.Collection{collections.Users, collections.Chargepoints, collections.Reservations, collections.Tariffs, collections.Sessions, collections.Wallets, collections.Ledger, collections.NoShows} {
+			err := db.ClearCollection(collection)
+			if err != nil {
+				t.Fatalf("Failed to clear collection:\n%v", err)
+			}
+		}
+		client.Disconnect(context.Background())
+	}()
+
+	collections.Users.InsertOne(context.Background(), models.User{ID: "walletUser", Name: "Prepaid user"})
+	collections.Tariffs.InsertOne(context.Background(), models.Tariff{ID: "walletTariff", Name: "Per minute", Currency: "EUR", PerMinute: 10, SessionFee: 100})
+	collections.Chargepoints.InsertOne(context.Background(), models.Chargepoint{ID: "walletChargepoint", TariffID: "walletTariff", Connectors: []models.Connector{
+		{ID: 1, State: "Available"},
+	}})
+
+	request := func(method, endpoint string, body any) int {
+		encoded, _ := json.Marshal(body)
+		req, _ := http.NewRequest(method, endpoint, bytes.NewReader(encoded))
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	reservationID := func() string {
+		var reservation models.Reservation
+		err := collections.Reservations.FindOne(context.Background(), bson.M{"userId": "walletUser", "hasFinishedCharging": false}).Decode(&reservation)
+		if err != nil {
+			t.Fatalf("Could not find the reservation:\n%v", err)
+		}
+		return reservation.ID.Hex()
+	}
+
+	expectWallet := func(t *testing.T, balance, held int64) {
+		wallet, err := FindWalletByUserID("walletUser", collections.Wallets)
+		if err != nil {
+			t.Fatalf("Could not find the wallet:\n%v", err)
+		}
+
+		if wallet.Balance != balance || wallet.Held != held {
+			t.Errorf("Expected a balance of %d with %d held, but received %d with %d held", balance, held, wallet.Balance, wallet.Held)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		endpoint func() string
+		body     map[string]any
+		code     int
+	}{
+		{name: "TopUpNothing", method: "POST", endpoint: func() string { return "/users/walletUser/wallet/topup" }, body: map[string]any{"amount": 0}, code: http.StatusBadRequest},
+		{name: "TopUpUnknownUser", method: "POST", endpoint: func() string { return "/users/nobody/wallet/topup" }, body: map[string]any{"amount": 500}, code: http.StatusBadRequest},
+		{name: "TopUp", method: "POST", endpoint: func() string { return "/users/walletUser/wallet/topup" }, body: map[string]any{"amount": 500}, code: http.StatusOK},
+		// 60 minutes at 10 cents plus the session fee need 700 cents
+		{name: "InsufficientFunds", method: "POST", endpoint: func() string { return "/reservations/walletChargepoint/1" }, body: map[string]any{"userId": "walletUser", "minutes": 60}, code: http.StatusPaymentRequired},
+		{name: "TopUpMore", method: "POST", endpoint: func() string { return "/users/walletUser/wallet/topup" }, body: map[string]any{"amount": 1000}, code: http.StatusOK},
+		{name: "Reserve", method: "POST", endpoint: func() string { return "/reservations/walletChargepoint/1" }, body: map[string]any{"userId": "walletUser", "minutes": 60}, code: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			code := request(test.method, test.endpoint(), test.body)
+			if code != test.code {
+				t.Errorf("Expected code %d, but received %d", test.code, code)
+			} else {
+				t.Logf("Received the correct code %d", test.code)
+			}
+		})
+	}
+
+	t.Run("Held", func(t *testing.T) {
+		expectWallet(t, 1500, 700)
+	})
+
+	t.Run("CancelOtherUsersReservation", func(t *testing.T) {
+		code := request("DELETE", fmt.Sprintf("/reservations/%s?userId=someoneElse", reservationID()), nil)
+		if code != http.StatusBadRequest {
+			t.Errorf("Expected code %d, but received %d", http.StatusBadRequest, code)
+		}
+	})
+
+	t.Run("CancelReleasesHold", func(t *testing.T) {
+		code := request("DELETE", fmt.Sprintf("/reservations/%s?userId=walletUser", reservationID()), nil)
+		if code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, code)
+		}
+
+		expectWallet(t, 1500, 0)
+	})
+
+	t.Run("CaptureOnCompletion", func(t *testing.T) {
+		if code := request("POST", "/reservations/walletChargepoint/1", map[string]any{"userId": "walletUser", "minutes": 60}); code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, code)
+		}
+		if code := request("POST", "/charge/walletChargepoint/1", map[string]any{"userId": "walletUser"}); code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, code)
+		}
+		// Joining an organization doesn't leave the reservation's hold stuck
+		collections.Users.UpdateOne(context.Background(), bson.M{"_id": "walletUser"}, bson.M{"$set": bson.M{"organizationId": "walletOrganization"}})
+		if code := request("POST", "/stop/walletChargepoint/1", map[string]any{"userId": "walletUser"}); code != http.StatusOK {
+			t.Fatalf("Expected code %d, but received %d", http.StatusOK, code)
+		}
+
+		// Stopping right away only costs the session fee
+		expectWallet(t, 1400, 0)
+	})
+
+	t.Run("Reconcile", func(t *testing.T) {
+		wallet, err := FindWalletByUserID("walletUser", collections.Wallets)
+		if err != nil {
+			t.Fatalf("Could not find the wallet:\n%v", err)
+		}
+
+		reconciliation, err := ReconcileWallet(wallet, collections)
+		if err != nil {
+			t.Fatalf("Could not reconcile the wallet:\n%v", err)
+		}
+
+		// Two top-ups, two holds, two releases and a capture
+		if !reconciliation.Consistent || reconciliation.Entries != 7 {
+			t.Errorf("Expected 7 consistent ledger entries, but received %v", reconciliation)
+		}
+	})
+}
@@ -0,0 +1,90 @@
+package loadmanagement
+
+import (
+	"sort"
+	"time"
+)
+
+// Strategies for sharing a site's power between its charging sessions
+const (
+	// EqualShare splits the power evenly, sessions that can't use their share leave the rest to the others
+	EqualShare = "EqualShare"
+	// Priority serves higher priority sessions first, sessions of the same priority share equally
+	Priority = "Priority"
+	// FirstCome serves sessions in the order they started
+	FirstCome = "FirstCome"
+)
+
+// Valid tells whether the strategy is one of the supported ones
+func Valid(strategy string) bool {
+	return strategy == EqualShare || strategy == Priority || strategy == FirstCome
+}
+
+// Demand is a session asking for power. MaxPower (in kW) is the most its connector and vehicle can take.
+type Demand struct {
+	ID       string
+	MaxPower float64
+	Priority int
+	Since    time.Time
+}
+
+// Allocate shares the capacity (in kW) between the demands, returning each demand's power limit by ID. A capacity of 0 or less is unlimited. Unknown strategies fall back to an equal share.
+func Allocate(capacity float64, strategy string, demands []Demand) map[string]float64 {
+	limits := map[string]float64{}
+
+	if capacity <= 0 {
+		for _, demand := range demands {
+			limits[demand.ID] = demand.MaxPower
+		}
+		return limits
+	}
+
+	sorted := append([]Demand{}, demands...)
+	remaining := capacity
+
+	switch strategy {
+	case FirstCome:
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Since.Before(sorted[j].Since) })
+		for _, demand := range sorted {
+			limits[demand.ID] = minPower(demand.MaxPower, remaining)
+			remaining -= limits[demand.ID]
+		}
+	case Priority:
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Priority > sorted[j].Priority })
+		for start := 0; start < len(sorted); {
+			end := start
+			for end < len(sorted) && sorted[end].Priority == sorted[start].Priority {
+				end++
+			}
+			remaining = share(remaining, sorted[start:end], limits)
+			start = end
+		}
+	default:
+		share(remaining, sorted, limits)
+	}
+
+	return limits
+}
+
+// share splits the capacity evenly between the demands and returns what's left. Demands are served from the smallest, so whatever they can't use goes to the bigger ones.
+func share(capacity float64, demands []Demand, limits map[string]float64) float64 {
+	sorted := append([]Demand{}, demands...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].MaxPower < sorted[j].MaxPower })
+
+	for i, demand := range sorted {
+		limits[demand.ID] = minPower(demand.MaxPower, capacity/float64(len(sorted)-i))
+		capacity -= limits[demand.ID]
+	}
+
+	return capacity
+}
+
+func minPower(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	if b < 0 {
+		return 0
+	}
+	return b
+}
@@ -0,0 +1,89 @@
+package loadmanagement
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAllocate(t *testing.T) {
+	now := time.Now()
+	demands := []Demand{
+		{ID: "fast", MaxPower: 50, Priority: 0, Since: now},
+		{ID: "slow", MaxPower: 11, Priority: 0, Since: now.Add(-time.Hour)},
+		{ID: "fleet", MaxPower: 22, Priority: 1, Since: now.Add(time.Minute)},
+	}
+
+	tests := []struct {
+		name     string
+		capacity float64
+		strategy string
+		limits   map[string]float64
+	}{
+		{name: "Unlimited", capacity: 0, strategy: EqualShare, limits: map[string]float64{"fast": 50, "slow": 11, "fleet": 22}},
+		// The slow session can't use its 20 kW share, so the rest goes to the others
+		{name: "EqualShare", capacity: 60, strategy: EqualShare, limits: map[string]float64{"fast": 27, "slow": 11, "fleet": 22}},
+		{name: "Priority", capacity: 40, strategy: Priority, limits: map[string]float64{"fast": 9, "slow": 9, "fleet": 22}},
+		{name: "FirstCome", capacity: 40, strategy: FirstCome, limits: map[string]float64{"fast": 29, "slow": 11, "fleet": 0}},
+		{name: "Unknown", capacity: 30, strategy: "", limits: map[string]float64{"fast": 10, "slow": 10, "fleet": 10}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			limits := Allocate(test.capacity, test.strategy, demands)
+
+			total := 0.0
+			for id, expected := range test.limits {
+				if limits[id] != expected {
+					t.Errorf("Expected %s to get %.1f kW, but it got %.1f", id, expected, limits[id])
+				}
+				total += limits[id]
+			}
+
+			if test.capacity > 0 && total > test.capacity {
+				t.Errorf("Expected at most %.1f kW to be allocated, but %.1f was", test.capacity, total)
+			}
+		})
+	}
+
+	if Valid("Random") || !Valid(FirstCome) {
+		t.Errorf("Expected only the supported strategies to be valid")
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	start := time.Date(2023, 6, 1, 21, 0, 0, 0, time.Local)
+	slot := func(hour int, maxPower, price float64) Slot {
+		return Slot{Start: start.Add(time.Duration(hour) * time.Hour), Duration: time.Hour, MaxPower: maxPower, Price: price}
+	}
+
+	// Evening power is expensive, the night is cheap but the site is busy at midnight
+	slots := []Slot{slot(0, 11, 40), slot(1, 11, 20), slot(2, 5, 20), slot(3, 11, 20)}
+
+	tests := []struct {
+		name   string
+		target float64
+		power  []float64
+		energy float64
+	}{
+		{name: "Cheapest", target: 20, power: []float64{0, 11, 5, 4}, energy: 20},
+		{name: "Expensive", target: 30, power: []float64{3, 11, 5, 11}, energy: 30},
+		{name: "Short", target: 50, power: []float64{11, 11, 5, 11}, energy: 38},
+		{name: "FullPower", target: 0, power: []float64{11, 11, 5, 11}, energy: 38},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			power, energy := Schedule(slots, test.target)
+
+			for i := range power {
+				if power[i] != test.power[i] {
+					t.Errorf("Expected %.1f kW in slot %d, but received %.1f", test.power[i], i, power[i])
+				}
+			}
+
+			if energy != test.energy {
+				t.Errorf("Expected %.1f kWh to be delivered, but received %.1f", test.energy, energy)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package loadmanagement
+
+import (
+	"sort"
+	"time"
+)
+
+// Slot is a period a vehicle can charge in, at up to MaxPower (in kW) for Price (in cents per kWh)
+type Slot struct {
+	Start    time.Time
+	Duration time.Duration
+	MaxPower float64
+	Price    float64
+}
+
+// Schedule plans the power (in kW) of every slot to deliver the target energy (in kWh) at the lowest cost. The cheapest slots are filled first, earlier ones first when prices are equal. It returns the power per slot and the energy it delivers, which falls short of the target when the slots can't take it all. A target of 0 or less charges at full power in every slot.
+func Schedule(slots []Slot, target float64) ([]float64, float64) {
+	power := make([]float64, len(slots))
+	energy := 0.0
+
+	order := make([]int, len(slots))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return slots[order[a]].Price < slots[order[b]].Price })
+
+	for _, i := range order {
+		slot := slots[i]
+		hours := slot.Duration.Hours()
+		if slot.MaxPower <= 0 || hours <= 0 {
+			continue
+		}
+
+		if target <= 0 {
+			power[i] = slot.MaxPower
+			energy += slot.MaxPower * hours
+			continue
+		}
+
+		remaining := target - energy
+		if remaining <= 0 {
+			break
+		}
+
+		power[i] = slot.MaxPower
+		if slot.MaxPower*hours > remaining {
+			power[i] = remaining / hours
+		}
+		energy += power[i] * hours
+	}
+
+	return power, energy
+}
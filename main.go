@@ -54,15 +54,25 @@ func main() {
 }
 
 func RunEndpoints(address string, databaseName string, router *gin.Engine, client *mongo.Client) {
-	database := client.Database(databaseName)
-	usersCollection := database.Collection("users")
-	chargepointsCollection := database.Collection("chargepoints")
-	reservationsCollection := database.Collection("reservations")
+	collections := endpoints.NewCollections(client.Database(databaseName))
+	usersCollection := collections.Users
+	chargepointsCollection := collections.Chargepoints
+	reservationsCollection := collections.Reservations
+
+	// Databases created before an index was added get it too, the migration relies on the unique booking codes
+	if err := endpoints.CreateIndexes(collections); err != nil {
+		log.Println("Error creating indexes: ", err)
+	}
+
+	// Reservations made before they got ObjectIDs are migrated before anything else uses them
+	if err := endpoints.MigrateReservationIDs(collections); err != nil {
+		log.Println("Error migrating reservation IDs: ", err)
+	}
 
 	// Goroutine for checking all of the open reservations and closing any that are outdated
-	go endpoints.CheckReservations(reservationsCollection, chargepointsCollection)
+	go endpoints.CheckReservations(collections)
 
-	router.POST("/users/:id", func(c *gin.Context) {
+	router.POST("/users/:id", endpoints.Idempotent(collections.Idempotency), func(c *gin.Context) {
 		endpoints.CreateUser(c, usersCollection)
 	})
 
@@ -89,8 +99,316 @@ func RunEndpoints(address string, databaseName string, router *gin.Engine, clien
 		c.JSON(http.StatusOK, documents)
 	})
 
-	router.POST("/chargepoints/:id", func(c *gin.Context) {
-		endpoints.CreateChargepoint(c, chargepointsCollection)
+	router.GET("/users/:id/reliability", func(c *gin.Context) {
+		id := c.Param("id")
+
+		_, err := endpoints.FindUserByID(id, usersCollection)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "User not found"})
+			return
+		}
+
+		reliability, err := endpoints.GetUserReliability(id, collections)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch the user's reliability"})
+			return
+		}
+
+		c.JSON(http.StatusOK, reliability)
+	})
+
+	router.GET("/users/:id/noshows", func(c *gin.Context) {
+		noShows, err := endpoints.GetUserNoShows(c.Param("id"), collections.NoShows)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch no-shows"})
+			return
+		}
+
+		c.JSON(http.StatusOK, noShows)
+	})
+
+	router.POST("/noshows/:id/forgive", func(c *gin.Context) {
+		endpoints.ForgiveNoShow(c, collections.NoShows)
+	})
+
+	router.POST("/users/:id/wallet/topup", func(c *gin.Context) {
+		endpoints.TopUpWallet(c, collections)
+	})
+
+	router.GET("/users/:id/wallet", func(c *gin.Context) {
+		wallet, err := endpoints.FindWalletByUserID(c.Param("id"), collections.Wallets)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Wallet not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, wallet)
+	})
+
+	router.GET("/users/:id/wallet/transactions", func(c *gin.Context) {
+		entries, err := endpoints.GetWalletTransactions(c.Param("id"), collections.Ledger)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch wallet transactions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, entries)
+	})
+
+	router.GET("/users/:id/wallet/reconcile", func(c *gin.Context) {
+		wallet, err := endpoints.FindWalletByUserID(c.Param("id"), collections.Wallets)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Wallet not found"})
+			return
+		}
+
+		reconciliation, err := endpoints.ReconcileWallet(wallet, collections)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to reconcile the wallet"})
+			return
+		}
+
+		c.JSON(http.StatusOK, reconciliation)
+	})
+
+	router.GET("/users/:id/waitlist", func(c *gin.Context) {
+		entries, err := endpoints.GetUserWaitlists(c.Param("id"), collections.Waitlist)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch the user's waitlists"})
+			return
+		}
+
+		c.JSON(http.StatusOK, entries)
+	})
+
+	router.GET("/users/:id/notifications", func(c *gin.Context) {
+		notifications, err := endpoints.GetUserNotifications(c.Param("id"), collections.Notifications)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch the user's notifications"})
+			return
+		}
+
+		c.JSON(http.StatusOK, notifications)
+	})
+
+	router.GET("/users/:id/series", func(c *gin.Context) {
+		series, err := endpoints.GetUserSeries(c.Param("id"), collections.Series)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch the user's series"})
+			return
+		}
+
+		c.JSON(http.StatusOK, series)
+	})
+
+	router.POST("/users/:id/vehicles", func(c *gin.Context) {
+		endpoints.CreateVehicle(c, collections)
+	})
+
+	router.GET("/users/:id/vehicles", func(c *gin.Context) {
+		vehicles, err := endpoints.GetUserVehicles(c.Param("id"), collections.Vehicles)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch vehicles"})
+			return
+		}
+
+		c.JSON(http.StatusOK, vehicles)
+	})
+
+	router.GET("/vehicles/:id", func(c *gin.Context) {
+		vehicle, err := endpoints.FindVehicleByID(c.Param("id"), collections.Vehicles)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Vehicle not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, vehicle)
+	})
+
+	router.GET("/vehicles/:id/connectors", func(c *gin.Context) {
+		vehicle, err := endpoints.FindVehicleByID(c.Param("id"), collections.Vehicles)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Vehicle not found"})
+			return
+		}
+
+		suggestions, err := endpoints.SuggestConnectors(vehicle, chargepointsCollection)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch compatible connectors"})
+			return
+		}
+
+		c.JSON(http.StatusOK, suggestions)
+	})
+
+	router.POST("/organizations/:id", func(c *gin.Context) {
+		endpoints.CreateOrganization(c, collections)
+	})
+
+	router.GET("/organizations/:id", func(c *gin.Context) {
+		organization, err := endpoints.FindOrganizationByID(c.Param("id"), collections.Organizations)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Organization not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, organization)
+	})
+
+	router.GET("/organizations", func(c *gin.Context) {
+		documents, err := endpoints.GetAllOrganizations(collections.Organizations)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch organizations"})
+			return
+		}
+
+		c.JSON(http.StatusOK, documents)
+	})
+
+	router.POST("/organizations/:id/members/:userID", func(c *gin.Context) {
+		endpoints.AddOrganizationMember(c, collections)
+	})
+
+	router.GET("/organizations/:id/reservations", func(c *gin.Context) {
+		endpoints.GetOrganizationReservations(c, collections)
+	})
+
+	router.GET("/organizations/:id/sessions", func(c *gin.Context) {
+		endpoints.GetOrganizationSessions(c, collections)
+	})
+
+	router.POST("/sites/:id", func(c *gin.Context) {
+		endpoints.CreateSite(c, collections.Sites)
+	})
+
+	router.GET("/sites/:id", func(c *gin.Context) {
+		site, err := endpoints.FindSiteByID(c.Param("id"), collections.Sites)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Site not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, site)
+	})
+
+	router.GET("/sites", func(c *gin.Context) {
+		documents, err := endpoints.GetAllSites(collections.Sites)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch sites"})
+			return
+		}
+
+		c.JSON(http.StatusOK, documents)
+	})
+
+	router.POST("/sites/:id/pricing", func(c *gin.Context) {
+		endpoints.SetPricingPolicy(c, collections)
+	})
+
+	router.POST("/sites/:id/capacity", func(c *gin.Context) {
+		endpoints.SetSiteCapacity(c, collections)
+	})
+
+	router.POST("/sites/:id/reservations", endpoints.Idempotent(collections.Idempotency), func(c *gin.Context) {
+		endpoints.AutoReserveSite(c, collections)
+	})
+
+	router.GET("/sites/:id/load", func(c *gin.Context) {
+		site, err := endpoints.FindSiteByID(c.Param("id"), collections.Sites)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Site not found"})
+			return
+		}
+
+		load, err := endpoints.GetSiteLoad(site, collections)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to compute the site's load"})
+			return
+		}
+
+		c.JSON(http.StatusOK, load)
+	})
+
+	router.POST("/tariffs/:id", func(c *gin.Context) {
+		endpoints.CreateTariff(c, collections.Tariffs)
+	})
+
+	router.GET("/tariffs/:id", func(c *gin.Context) {
+		tariff, err := endpoints.FindTariffByID(c.Param("id"), collections.Tariffs)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Tariff not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, tariff)
+	})
+
+	router.GET("/tariffs", func(c *gin.Context) {
+		documents, err := endpoints.GetAllTariffs(collections.Tariffs)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch tariffs"})
+			return
+		}
+
+		c.JSON(http.StatusOK, documents)
+	})
+
+	router.POST("/tariffs/:id/attach", func(c *gin.Context) {
+		endpoints.AttachTariff(c, collections)
+	})
+
+	router.POST("/priorityclasses/:id", func(c *gin.Context) {
+		endpoints.CreatePriorityClass(c, collections.PriorityClasses)
+	})
+
+	router.GET("/priorityclasses/:id", func(c *gin.Context) {
+		class, err := endpoints.FindPriorityClassByID(c.Param("id"), collections.PriorityClasses)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Priority class not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, class)
+	})
+
+	router.GET("/priorityclasses", func(c *gin.Context) {
+		documents, err := endpoints.GetAllPriorityClasses(collections.PriorityClasses)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch priority classes"})
+			return
+		}
+
+		c.JSON(http.StatusOK, documents)
+	})
+
+	router.POST("/priorityclasses/:id/assign", func(c *gin.Context) {
+		endpoints.AssignPriorityClass(c, collections)
+	})
+
+	router.DELETE("/priorityclasses/:id/assign", func(c *gin.Context) {
+		endpoints.UnassignPriorityClass(c, collections)
+	})
+
+	router.GET("/compensations", func(c *gin.Context) {
+		compensations, err := endpoints.GetCompensations(c.Query("userId"), collections.Compensations)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch compensations"})
+			return
+		}
+
+		c.JSON(http.StatusOK, compensations)
+	})
+
+	router.POST("/policies", func(c *gin.Context) {
+		endpoints.SetReservationPolicy(c, collections)
+	})
+
+	router.GET("/policies/:cpID/:coID", func(c *gin.Context) {
+		endpoints.GetReservationPolicy(c, collections)
+	})
+
+	router.POST("/chargepoints/:id", endpoints.Idempotent(collections.Idempotency), func(c *gin.Context) {
+		endpoints.CreateChargepoint(c, collections)
 	})
 
 	router.GET("/chargepoints/:id", func(c *gin.Context) {
@@ -113,16 +431,292 @@ func RunEndpoints(address string, databaseName string, router *gin.Engine, clien
 		c.JSON(http.StatusOK, documents)
 	})
 
-	router.POST("/charge/:cpID/:coID", func(c *gin.Context) {
-		endpoints.Charge(c, reservationsCollection, chargepointsCollection, usersCollection)
+	router.POST("/chargepoints/:id/maintenance", func(c *gin.Context) {
+		endpoints.ScheduleMaintenance(c, collections)
+	})
+
+	router.POST("/chargepoints/:id/holds", func(c *gin.Context) {
+		endpoints.SetConnectorHold(c, collections)
+	})
+
+	router.GET("/chargepoints/:id/maintenance", func(c *gin.Context) {
+		windows, err := endpoints.GetMaintenanceWindows(c.Param("id"), collections.Maintenance)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch maintenance windows"})
+			return
+		}
+
+		c.JSON(http.StatusOK, windows)
+	})
+
+	router.GET("/availability", func(c *gin.Context) {
+		endpoints.SearchAvailability(c, collections)
+	})
+
+	router.POST("/charge/:cpID/:coID", endpoints.Idempotent(collections.Idempotency), func(c *gin.Context) {
+		endpoints.Charge(c, collections)
+	})
+
+	router.POST("/stop/:cpID/:coID", func(c *gin.Context) {
+		endpoints.StopCharging(c, collections)
+	})
+
+	router.GET("/sessions", func(c *gin.Context) {
+		sessions, err := endpoints.GetSessions(endpoints.SessionsFilter(c), collections.Sessions)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch sessions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, sessions)
+	})
+
+	router.GET("/sessions/:id", func(c *gin.Context) {
+		session, err := endpoints.FindSessionByID(c.Param("id"), collections.Sessions)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Session not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, session)
+	})
+
+	router.GET("/sessions/:id/price", func(c *gin.Context) {
+		session, err := endpoints.FindSessionByID(c.Param("id"), collections.Sessions)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Session not found"})
+			return
+		}
+
+		price, err := endpoints.GetSessionPrice(session, collections)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to price the session"})
+			return
+		}
+
+		c.JSON(http.StatusOK, price)
+	})
+
+	router.GET("/sessions/:id/powercurve", func(c *gin.Context) {
+		session, err := endpoints.FindSessionByID(c.Param("id"), collections.Sessions)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Session not found"})
+			return
+		}
+
+		values, err := endpoints.GetSessionPowerCurve(session, collections.MeterValues)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch meter values"})
+			return
+		}
+
+		c.JSON(http.StatusOK, values)
+	})
+
+	router.POST("/metervalues/:cpID/:coID", func(c *gin.Context) {
+		endpoints.PushMeterValues(c, collections)
+	})
+
+	router.POST("/unplug/:cpID/:coID", func(c *gin.Context) {
+		endpoints.Unplug(c, collections)
+	})
+
+	router.GET("/fees", func(c *gin.Context) {
+		fees, err := endpoints.GetFees(c.Query("userId"), c.Query("reservationId"), collections.Fees)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch fees"})
+			return
+		}
+
+		c.JSON(http.StatusOK, fees)
 	})
 
 	router.POST("/changestate/:cpID/:coID", func(c *gin.Context) {
 		endpoints.ChangeConnectorState(c, chargepointsCollection)
 	})
 
-	router.POST("/reservations/:cpID/:coID", func(c *gin.Context) {
-		endpoints.CreateReservation(c, reservationsCollection, chargepointsCollection, usersCollection)
+	router.POST("/reservations/:cpID/:coID", endpoints.Idempotent(collections.Idempotency), func(c *gin.Context) {
+		endpoints.CreateReservation(c, collections)
+	})
+
+	router.POST("/reservations/:cpID", endpoints.Idempotent(collections.Idempotency), func(c *gin.Context) {
+		endpoints.AutoReserveChargepoint(c, collections)
+	})
+
+	router.POST("/series/:cpID/:coID", func(c *gin.Context) {
+		endpoints.CreateSeries(c, collections)
+	})
+
+	router.GET("/series/:id", func(c *gin.Context) {
+		series, err := endpoints.FindSeriesByID(c.Param("id"), collections.Series)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Series not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, series)
+	})
+
+	router.PUT("/series/:id", func(c *gin.Context) {
+		endpoints.UpdateSeries(c, collections)
+	})
+
+	router.DELETE("/series/:id", func(c *gin.Context) {
+		endpoints.CancelSeries(c, collections)
+	})
+
+	router.PUT("/series/:id/occurrences/:reservationID", func(c *gin.Context) {
+		endpoints.UpdateOccurrence(c, collections)
+	})
+
+	router.POST("/quotes/:cpID/:coID", func(c *gin.Context) {
+		endpoints.QuotePrice(c, collections)
+	})
+
+	router.POST("/waitlist/:cpID", func(c *gin.Context) {
+		endpoints.JoinWaitlist(c, collections)
+	})
+
+	router.GET("/waitlist/:cpID", func(c *gin.Context) {
+		_, err := endpoints.FindChargepointByID(c.Param("cpID"), collections.Chargepoints)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Chargepoint not found"})
+			return
+		}
+
+		entries, err := endpoints.GetWaitlist(c.Param("cpID"), collections.Waitlist)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch the waitlist"})
+			return
+		}
+
+		c.JSON(http.StatusOK, entries)
+	})
+
+	router.DELETE("/waitlist/:cpID", func(c *gin.Context) {
+		endpoints.LeaveWaitlist(c, collections)
+	})
+
+	router.GET("/reservations/:id", func(c *gin.Context) {
+		reservation, err := endpoints.FindReservationByID(c.Param("id"), reservationsCollection)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Reservation not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, reservation)
+	})
+
+	router.PUT("/reservations/:id", func(c *gin.Context) {
+		endpoints.ModifyReservation(c, collections)
+	})
+
+	router.GET("/reservations/code/:code", func(c *gin.Context) {
+		reservation, err := endpoints.FindReservationByCode(c.Param("code"), reservationsCollection)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Reservation not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, reservation)
+	})
+
+	router.DELETE("/reservations/:id", func(c *gin.Context) {
+		endpoints.CancelReservation(c, collections)
+	})
+
+	router.POST("/cancel/:id", func(c *gin.Context) {
+		endpoints.OperatorCancelReservation(c, collections)
+	})
+
+	router.GET("/reservations/:id/schedule", func(c *gin.Context) {
+		reservation, err := endpoints.FindReservationByID(c.Param("id"), collections.Reservations)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Reservation not found"})
+			return
+		}
+
+		schedule, err := endpoints.GetChargingSchedule(reservation, collections)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to plan the charging schedule"})
+			return
+		}
+
+		c.JSON(http.StatusOK, schedule)
+	})
+
+	router.GET("/reservations/:id/payment", func(c *gin.Context) {
+		payment, err := endpoints.GetReservationPayment(c.Param("id"), collections.Payments)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Payment not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, payment)
+	})
+
+	router.POST("/payments/webhook", func(c *gin.Context) {
+		endpoints.PaymentWebhook(c, collections)
+	})
+
+	router.POST("/invoices/run", func(c *gin.Context) {
+		endpoints.RunBilling(c, collections)
+	})
+
+	router.GET("/invoices", func(c *gin.Context) {
+		invoices, err := endpoints.GetInvoices(c.Query("userId"), c.Query("organizationId"), collections.Invoices)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to fetch invoices"})
+			return
+		}
+
+		c.JSON(http.StatusOK, invoices)
+	})
+
+	router.GET("/invoices/:id", func(c *gin.Context) {
+		invoice, err := endpoints.FindInvoiceByID(c.Param("id"), collections.Invoices)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Invoice not found"})
+			return
+		}
+
+		c.JSON(http.StatusOK, invoice)
+	})
+
+	router.GET("/invoices/:id/csv", func(c *gin.Context) {
+		invoice, err := endpoints.FindInvoiceByID(c.Param("id"), collections.Invoices)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Invoice not found"})
+			return
+		}
+
+		endpoints.GetInvoiceCSV(c, invoice)
+	})
+
+	router.GET("/invoices/:id/pdf", func(c *gin.Context) {
+		invoice, err := endpoints.FindInvoiceByID(c.Param("id"), collections.Invoices)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Invoice not found"})
+			return
+		}
+
+		endpoints.GetInvoicePDF(c, invoice)
+	})
+
+	router.GET("/reservations/:id/price", func(c *gin.Context) {
+		reservation, err := endpoints.FindReservationByID(c.Param("id"), reservationsCollection)
+		if err != nil {
+			c.JSON(http.StatusNotFound, models.ErrorResponse{Error: "Reservation not found"})
+			return
+		}
+
+		price, err := endpoints.GetReservationPrice(reservation, collections)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, models.ErrorResponse{Error: "Failed to price the reservation"})
+			return
+		}
+
+		c.JSON(http.StatusOK, price)
 	})
 
 	router.GET("/reservations", func(c *gin.Context) {
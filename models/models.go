@@ -2,26 +2,261 @@ package models
 
 import (
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type User struct {
+	ID             string `bson:"_id" json:"id"`
+	Name           string `bson:"name" json:"name"`
+	OrganizationID string `bson:"organizationId,omitempty" json:"organizationId,omitempty"`
+	// Sessions of users with a higher priority get power first at sites that share it by priority
+	Priority int `bson:"priority,omitempty" json:"priority,omitempty"`
+	// Overrides the priority class of the user's organization
+	PriorityClass string `bson:"priorityClass,omitempty" json:"priorityClass,omitempty"`
+}
+
+// Organization groups users (like a fleet's drivers) that share a monthly budget and are billed together
+type Organization struct {
+	ID     string   `bson:"_id" json:"id"`
+	Name   string   `bson:"name" json:"name"`
+	Admins []string `bson:"admins" json:"admins"`
+	// Minutes all members can reserve together per calendar month, 0 means unlimited
+	MonthlyMinutes   int                `bson:"monthlyMinutes" json:"monthlyMinutes"`
+	ReservedCapacity []ReservedCapacity `bson:"reservedCapacity" json:"reservedCapacity"`
+	// Applies to members without a priority class of their own
+	PriorityClass string `bson:"priorityClass,omitempty" json:"priorityClass,omitempty"`
+}
+
+// PriorityClass ranks users, like emergency services above fleets above everyone else. Users without a class have rank 0.
+type PriorityClass struct {
 	ID   string `bson:"_id" json:"id"`
 	Name string `bson:"name" json:"name"`
+	// Higher ranks come first
+	Rank int `bson:"rank" json:"rank"`
+	// Whether members can bump reservations of users with a lower rank that haven't started charging
+	CanBump bool `bson:"canBump" json:"canBump"`
+	// Only reservations starting at least this many minutes later can be bumped
+	BumpNoticeMinutes int `bson:"bumpNoticeMinutes" json:"bumpNoticeMinutes"`
+	// A reservation that already holds its connector is taken over when its user hasn't plugged in this many minutes after it started, 0 never takes one over
+	TakeOverMinutes int `bson:"takeOverMinutes" json:"takeOverMinutes"`
+	// Recorded for every user whose reservation is bumped, in cents
+	Compensation int64  `bson:"compensation" json:"compensation"`
+	Currency     string `bson:"currency" json:"currency"`
+}
+
+// ConnectorHold keeps a connector for users of a priority class, or a higher ranked one, until shortly before each slot
+type ConnectorHold struct {
+	PriorityClass string `bson:"priorityClass" json:"priorityClass"`
+	// Other users can only reserve slots starting within this many minutes
+	ReleaseMinutes int `bson:"releaseMinutes" json:"releaseMinutes"`
+}
+
+// ReservedCapacity holds a number of a chargepoint's connectors for an organization's members
+type ReservedCapacity struct {
+	Chargepoint string `bson:"chargepoint" json:"chargepoint"`
+	Connectors  int    `bson:"connectors" json:"connectors"`
+}
+
+// Site is a location with one or more chargepoints
+type Site struct {
+	ID            string         `bson:"_id" json:"id"`
+	Name          string         `bson:"name" json:"name"`
+	TariffID      string         `bson:"tariffId,omitempty" json:"tariffId,omitempty"`
+	PricingPolicy *PricingPolicy `bson:"pricingPolicy,omitempty" json:"pricingPolicy,omitempty"`
+	// Power (in kW) the site's grid connection can deliver to all connectors together, 0 means unlimited
+	MaxPower float64 `bson:"maxPower,omitempty" json:"maxPower,omitempty"`
+	// How the power is shared between charging sessions, either "EqualShare", "Priority" or "FirstCome"
+	LoadStrategy      string             `bson:"loadStrategy,omitempty" json:"loadStrategy,omitempty"`
+	Location          *Location          `bson:"location,omitempty" json:"location,omitempty"`
+	ReservationPolicy *ReservationPolicy `bson:"reservationPolicy,omitempty" json:"reservationPolicy,omitempty"`
+}
+
+// ReservationPolicy holds the rules for reserving connectors. It can be set on a site, a chargepoint and a connector: the fields a connector's policy sets take precedence over its chargepoint's, which take precedence over its site's. Fields that are left out are inherited, 0 is a value like any other.
+type ReservationPolicy struct {
+	// How long after the start the user can start charging before the reservation expires, in minutes
+	GraceMinutes *int `bson:"graceMinutes,omitempty" json:"graceMinutes,omitempty"`
+	// Shortest and longest reservation time, in minutes
+	MinMinutes *int `bson:"minMinutes,omitempty" json:"minMinutes,omitempty"`
+	MaxMinutes *int `bson:"maxMinutes,omitempty" json:"maxMinutes,omitempty"`
+	// How far ahead reservations can be booked, in days
+	HorizonDays *int `bson:"horizonDays,omitempty" json:"horizonDays,omitempty"`
+	// Time kept free between two reservations of a connector, in minutes
+	BufferMinutes *int `bson:"bufferMinutes,omitempty" json:"bufferMinutes,omitempty"`
+	// Only members of these organizations can reserve, everyone can when empty
+	AllowedOrganizations []string `bson:"allowedOrganizations,omitempty" json:"allowedOrganizations,omitempty"`
+}
+
+// ConnectorPolicy is the rules that apply to reserving a connector: the policies of the connector, its chargepoint and its site combined with the defaults, so every field is filled in
+type ConnectorPolicy struct {
+	GraceMinutes         int      `json:"graceMinutes"`
+	MinMinutes           int      `json:"minMinutes"`
+	MaxMinutes           int      `json:"maxMinutes"`
+	HorizonDays          int      `json:"horizonDays"`
+	BufferMinutes        int      `json:"bufferMinutes"`
+	AllowedOrganizations []string `json:"allowedOrganizations,omitempty"`
+}
+
+// Location is a point on the map, in degrees
+type Location struct {
+	Latitude  float64 `bson:"latitude" json:"latitude"`
+	Longitude float64 `bson:"longitude" json:"longitude"`
+}
+
+// SiteLoad is how a site's power is currently shared between its connectors
+type SiteLoad struct {
+	SiteID       string          `json:"siteId"`
+	MaxPower     float64         `json:"maxPower"`
+	LoadStrategy string          `json:"loadStrategy"`
+	Allocated    float64         `json:"allocated"`
+	Connectors   []ConnectorLoad `json:"connectors"`
+}
+
+// ConnectorLoad is the power limit (in kW) of a connector, only charging connectors get power
+type ConnectorLoad struct {
+	Chargepoint string  `json:"chargepoint"`
+	Connector   int     `json:"connector"`
+	State       string  `json:"state"`
+	MaxPower    float64 `json:"maxPower"`
+	Limit       float64 `json:"limit"`
+	SessionID   string  `json:"sessionId,omitempty"`
+}
+
+// PricingPolicy adjusts the price of reservations at a site to push demand off peak hours. Percentages are surcharges, negative ones are discounts.
+type PricingPolicy struct {
+	PeakWindows      []PeakWindow      `bson:"peakWindows" json:"peakWindows"`
+	UtilizationTiers []UtilizationTier `bson:"utilizationTiers" json:"utilizationTiers"`
+}
+
+// PeakWindow is part of the day, as "HH:MM" times in the server's time zone. A window can wrap past midnight.
+type PeakWindow struct {
+	Name    string `bson:"name" json:"name"`
+	Start   string `bson:"start" json:"start"`
+	End     string `bson:"end" json:"end"`
+	Percent int    `bson:"percent" json:"percent"`
+}
+
+// UtilizationTier applies from the given share (in percent) of the site's connectors being in use, the highest tier reached applies
+type UtilizationTier struct {
+	MinUtilization int `bson:"minUtilization" json:"minUtilization"`
+	Percent        int `bson:"percent" json:"percent"`
+}
+
+// PriceAdjustment is a pricing policy adjustment locked in when a reservation is quoted or made. It applies to the share of the charging price that falls in it.
+type PriceAdjustment struct {
+	// Either "Peak" or "Utilization"
+	Type        string  `bson:"type" json:"type"`
+	Description string  `bson:"description" json:"description"`
+	Percent     int     `bson:"percent" json:"percent"`
+	Share       float64 `bson:"share" json:"share"`
+}
+
+// Quote is a price offered to a user for a reservation, its adjustments are honoured when the user makes the reservation before the quote expires. A quote can be used once.
+type Quote struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	UserID      string             `bson:"userId" json:"userId"`
+	Chargepoint string             `bson:"chargepoint" json:"chargepoint"`
+	Connector   int                `bson:"connector" json:"connector"`
+	Minutes     int                `bson:"minutes" json:"minutes"`
+	// Only set for reservations booked ahead, quotes without it are for reservations starting right away
+	StartTime   time.Time         `bson:"startTime,omitempty" json:"startTime,omitempty"`
+	Adjustments []PriceAdjustment `bson:"adjustments" json:"adjustments"`
+	CreatedAt   time.Time         `bson:"createdAt" json:"createdAt"`
+	ExpiresAt   time.Time         `bson:"expiresAt" json:"expiresAt"`
+	// Set once a reservation is made with the quote
+	ReservationID primitive.ObjectID `bson:"reservationId,omitempty" json:"reservationId,omitempty" swaggertype:"string"`
+	Price         `bson:",inline"`
 }
 
 type Chargepoint struct {
-	ID         string      `bson:"_id" json:"id"`
-	Connectors []Connector `bson:"connectors" json:"connectors"`
+	ID                string             `bson:"_id" json:"id"`
+	Connectors        []Connector        `bson:"connectors" json:"connectors"`
+	SiteID            string             `bson:"siteId,omitempty" json:"siteId,omitempty"`
+	TariffID          string             `bson:"tariffId,omitempty" json:"tariffId,omitempty"`
+	ReservationPolicy *ReservationPolicy `bson:"reservationPolicy,omitempty" json:"reservationPolicy,omitempty"`
 }
 
 type Connector struct {
 	ID    int    `bson:"_id" json:"id"`
 	State string `bson:"state" json:"state"`
+	// Empty when the chargepoint was created without connector details
+	PlugType          string             `bson:"plugType,omitempty" json:"plugType,omitempty"`
+	CurrentType       string             `bson:"currentType,omitempty" json:"currentType,omitempty"`
+	MaxPower          float64            `bson:"maxPower,omitempty" json:"maxPower,omitempty"`
+	TariffID          string             `bson:"tariffId,omitempty" json:"tariffId,omitempty"`
+	ReservationPolicy *ReservationPolicy `bson:"reservationPolicy,omitempty" json:"reservationPolicy,omitempty"`
+	Hold              *ConnectorHold     `bson:"hold,omitempty" json:"hold,omitempty"`
+}
+
+// Tariff describes how charging is priced. All prices are in cents of the tariff's currency.
+type Tariff struct {
+	ID             string       `bson:"_id" json:"id"`
+	Name           string       `bson:"name" json:"name"`
+	Currency       string       `bson:"currency" json:"currency"`
+	PerMinute      int64        `bson:"perMinute" json:"perMinute"`
+	PerKWh         int64        `bson:"perKWh" json:"perKWh"`
+	SessionFee     int64        `bson:"sessionFee" json:"sessionFee"`
+	ReservationFee int64        `bson:"reservationFee" json:"reservationFee"`
+	IdleFee        int64        `bson:"idleFee" json:"idleFee"`
+	NoShowFee      int64        `bson:"noShowFee" json:"noShowFee"`
+	Bands          []TariffBand `bson:"bands" json:"bands"`
+}
+
+// TariffBand overrides the tariff's time and energy prices during part of the day. Times are "HH:MM" in the server's time zone, and a band can wrap past midnight.
+type TariffBand struct {
+	Name      string `bson:"name" json:"name"`
+	Start     string `bson:"start" json:"start"`
+	End       string `bson:"end" json:"end"`
+	PerMinute int64  `bson:"perMinute" json:"perMinute"`
+	PerKWh    int64  `bson:"perKWh" json:"perKWh"`
+}
+
+// Price is the cost of a reservation, broken down into the components that produced it
+type Price struct {
+	Currency   string           `json:"currency"`
+	Total      int64            `json:"total"`
+	Components []PriceComponent `json:"components"`
+}
+
+type PriceComponent struct {
+	// Either "Reservation", "Session", "Time", "Energy", "Idle", "Peak" or "Utilization"
+	Type        string  `json:"type"`
+	Description string  `json:"description"`
+	Quantity    float64 `json:"quantity"`
+	Unit        string  `json:"unit"`
+	UnitPrice   int64   `json:"unitPrice"`
+	Amount      int64   `json:"amount"`
+}
+
+type Vehicle struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	UserID    string             `bson:"userId" json:"userId"`
+	Make      string             `bson:"make" json:"make"`
+	Model     string             `bson:"model" json:"model"`
+	PlugTypes []string           `bson:"plugTypes" json:"plugTypes"`
+	// Charge rates in kW and battery capacity in kWh
+	MaxACPower      float64 `bson:"maxACPower" json:"maxACPower"`
+	MaxDCPower      float64 `bson:"maxDCPower" json:"maxDCPower"`
+	BatteryCapacity float64 `bson:"batteryCapacity" json:"batteryCapacity"`
+}
+
+// CompatibleConnector is an available connector a vehicle can charge on
+type CompatibleConnector struct {
+	Chargepoint string  `json:"chargepoint"`
+	Connector   int     `json:"connector"`
+	PlugType    string  `json:"plugType"`
+	CurrentType string  `json:"currentType"`
+	MaxPower    float64 `json:"maxPower"`
+	// The power the vehicle can actually draw from the connector, in kW
+	Power float64 `json:"power"`
 }
 
 type Reservation struct {
-	// Suggestion for IDs: currently, we use UnixNano() for the ID because for the demonstration, it is sufficient, but I would recommend swapping to something like Mongo ObjectIDs because they're less likely to conflict. For the demo, it's fine!
-	ID                  int       `bson:"_id"`
+	ID primitive.ObjectID `bson:"_id" swaggertype:"string"`
+	// Short code like "7KQ-4MZ" to give to the customer, see GET /reservations/code/{code}
+	Code string `bson:"code,omitempty" json:"code,omitempty"`
+	// The numeric ID the reservation had before reservations got ObjectIDs, it can still be looked up by it
+	LegacyID            int       `bson:"legacyId,omitempty" json:"legacyId,omitempty"`
 	Chargepoint         string    `bson:"chargepoint"`
 	Connector           int       `bson:"connector"`
 	UserID              string    `bson:"userId" json:"userId"`
@@ -29,12 +264,392 @@ type Reservation struct {
 	HasStartedCharging  bool      `bson:"hasStartedCharging"`
 	ChargingTime        time.Time `bson:"chargingTime"`
 	HasFinishedCharging bool      `bson:"hasFinishedCharging"`
+	Minutes             int       `bson:"minutes" json:"minutes"`
+	CreatedAt           time.Time `bson:"createdAt" json:"createdAt"`
+	// Deposit (in cents) held or pre-authorized with the reservation, required from users with too many no-shows. It is kept on a no-show and released once the user charges.
+	Deposit   int64  `bson:"deposit" json:"deposit"`
+	VehicleID string `bson:"vehicleId,omitempty" json:"vehicleId,omitempty"`
+	// The organization billed for the reservation, if the user belongs to one
+	OrganizationID string `bson:"organizationId,omitempty" json:"organizationId,omitempty"`
+	// How the reservation is paid for, decided when it's made: "Wallet", "Card" or "Invoice"
+	PaymentMode string `bson:"paymentMode,omitempty" json:"paymentMode,omitempty"`
+	// The tariff that applied to the connector when the reservation was made
+	TariffID string `bson:"tariffId,omitempty" json:"tariffId,omitempty"`
+	// When the connector is held from, reservations made through the API start right away
+	StartTime time.Time `bson:"startTime" json:"startTime"`
+	// Walk-ins are recorded when a user charges without reserving first. They don't count towards quotas and aren't charged the reservation fee.
+	WalkIn    bool `bson:"walkIn" json:"walkIn"`
+	Cancelled bool `bson:"cancelled" json:"cancelled"`
+	// Only set when an operator cancelled the reservation, or it was bumped
+	CancelReason string `bson:"cancelReason,omitempty" json:"cancelReason,omitempty"`
+	// The site's pricing policy adjustments, locked in when the reservation was made
+	Adjustments []PriceAdjustment `bson:"adjustments,omitempty" json:"adjustments,omitempty"`
+	// Set when the reservation is an occurrence of a recurring reservation
+	SeriesID string `bson:"seriesId,omitempty" json:"seriesId,omitempty"`
+	// Set when the site's capacity couldn't cover the connector's full power (in kW) during the reservation
+	PowerLimit float64 `bson:"powerLimit,omitempty" json:"powerLimit,omitempty"`
+	Priority   int     `bson:"priority,omitempty" json:"priority,omitempty"`
+	// The user's priority class when the reservation was made
+	PriorityClass string `bson:"priorityClass,omitempty" json:"priorityClass,omitempty"`
+	// Set when the reservation was cancelled for a priority reservation
+	BumpedBy primitive.ObjectID `bson:"bumpedBy,omitempty" json:"bumpedBy,omitempty" swaggertype:"string"`
+	// Smart charging: the energy (in kWh) the vehicle needs by its departure, charged when it's cheapest
+	TargetEnergy float64   `bson:"targetEnergy,omitempty" json:"targetEnergy,omitempty"`
+	Departure    time.Time `bson:"departure,omitempty" json:"departure,omitempty"`
+}
+
+// ChargingSchedule is the power a connector should deliver over time, shaped like an OCPP charging profile's schedule. Periods start at the given number of seconds after the start of the schedule, their limit is in W.
+type ChargingSchedule struct {
+	ReservationID    primitive.ObjectID       `json:"reservationId" swaggertype:"string"`
+	StartSchedule    time.Time                `json:"startSchedule"`
+	Duration         int                      `json:"duration"`
+	ChargingRateUnit string                   `json:"chargingRateUnit"`
+	Periods          []ChargingSchedulePeriod `json:"chargingSchedulePeriod"`
+	// Energy (in kWh) the vehicle still needs and the energy the schedule delivers
+	TargetEnergy  float64 `json:"targetEnergy"`
+	PlannedEnergy float64 `json:"plannedEnergy"`
+	// Estimated price of the planned energy, in cents
+	EnergyCost int64    `json:"energyCost"`
+	Currency   string   `json:"currency"`
+	Warnings   []string `json:"warnings,omitempty"`
+}
+
+type ChargingSchedulePeriod struct {
+	StartPeriod int     `json:"startPeriod"`
+	Limit       float64 `json:"limit"`
+	// Price of the energy during the period, in cents per kWh
+	PricePerKWh float64 `json:"pricePerKWh"`
+}
+
+// ChargingSession records what actually happened while a vehicle was charging, as opposed to the reservation which describes what was intended. Meter values are in Wh.
+type ChargingSession struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	ReservationID  primitive.ObjectID `bson:"reservationId" json:"reservationId" swaggertype:"string"`
+	UserID         string             `bson:"userId" json:"userId"`
+	OrganizationID string             `bson:"organizationId,omitempty" json:"organizationId,omitempty"`
+	Chargepoint    string             `bson:"chargepoint" json:"chargepoint"`
+	Connector      int                `bson:"connector" json:"connector"`
+	TariffID       string             `bson:"tariffId,omitempty" json:"tariffId,omitempty"`
+	WalkIn         bool               `bson:"walkIn" json:"walkIn"`
+	// Copied from the reservation, so the session is priced as it was quoted
+	Adjustments []PriceAdjustment `bson:"adjustments,omitempty" json:"adjustments,omitempty"`
+	// Copied from the reservation for the site's load management
+	PowerLimit float64 `bson:"powerLimit,omitempty" json:"powerLimit,omitempty"`
+	Priority   int     `bson:"priority,omitempty" json:"priority,omitempty"`
+	// Either "Charging" or "Completed"
+	Status     string    `bson:"status" json:"status"`
+	StartTime  time.Time `bson:"startTime" json:"startTime"`
+	StopTime   time.Time `bson:"stopTime,omitempty" json:"stopTime,omitempty"`
+	MeterStart float64   `bson:"meterStart" json:"meterStart"`
+	// The last known meter value while charging
+	MeterStop float64 `bson:"meterStop" json:"meterStop"`
+	// Energy delivered in kWh, computed when the session stops
+	Energy float64 `bson:"energy" json:"energy"`
+	// Either "Local" (stopped by the user), "ReservationEnded" or "Cancelled" (by an operator)
+	StopReason string `bson:"stopReason,omitempty" json:"stopReason,omitempty"`
+	// When the vehicle starts idling until it's unplugged: the end of the reservation's charging time, or when charging stopped after it. Not set when an operator cancelled the session.
+	IdleSince   time.Time `bson:"idleSince,omitempty" json:"idleSince,omitempty"`
+	UnpluggedAt time.Time `bson:"unpluggedAt,omitempty" json:"unpluggedAt,omitempty"`
+	IdleBilled  bool      `bson:"idleBilled,omitempty" json:"idleBilled,omitempty"`
+	// Set once the holder of the connector's next reservation was warned that the vehicle is still plugged in
+	LateWarned bool `bson:"lateWarned,omitempty" json:"lateWarned,omitempty"`
+}
+
+// MeterValue is a reading pushed by a connector while a session is charging. Energy is the meter's register in Wh, power is in kW, the state of charge in percent and voltage in V.
+type MeterValue struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	SessionID   primitive.ObjectID `bson:"sessionId" json:"sessionId" swaggertype:"string"`
+	Chargepoint string             `bson:"chargepoint" json:"chargepoint"`
+	Connector   int                `bson:"connector" json:"connector"`
+	Timestamp   time.Time          `bson:"timestamp" json:"timestamp"`
+	Energy      float64            `bson:"energy,omitempty" json:"energy,omitempty"`
+	Power       float64            `bson:"power,omitempty" json:"power,omitempty"`
+	SoC         float64            `bson:"soc,omitempty" json:"soc,omitempty"`
+	Voltage     float64            `bson:"voltage,omitempty" json:"voltage,omitempty"`
+}
+
+// Wallet is a user's prepaid balance in cents. Held is the part of the balance set aside for open reservations.
+type Wallet struct {
+	UserID    string `bson:"_id" json:"userId"`
+	Currency  string `bson:"currency" json:"currency"`
+	Balance   int64  `bson:"balance" json:"balance"`
+	Held      int64  `bson:"held" json:"held"`
+	Available int64  `bson:"-" json:"available"`
+}
+
+// LedgerEntry records a single change of a wallet. Amounts are always positive, the type decides whether the balance or held amount goes up or down.
+type LedgerEntry struct {
+	ID     primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	UserID string             `bson:"userId" json:"userId"`
+	// Either "TopUp", "Hold", "Release", "Capture" or "Refund"
+	Type          string             `bson:"type" json:"type"`
+	Amount        int64              `bson:"amount" json:"amount"`
+	ReservationID primitive.ObjectID `bson:"reservationId,omitempty" json:"reservationId,omitempty" swaggertype:"string"`
+	SessionID     primitive.ObjectID `bson:"sessionId,omitempty" json:"sessionId,omitempty" swaggertype:"string"`
+	Time          time.Time          `bson:"time" json:"time"`
+	// The wallet right after the entry was applied
+	Balance int64 `bson:"balance" json:"balance"`
+	Held    int64 `bson:"held" json:"held"`
+}
+
+// Payment is a card payment for a reservation, made through a payment gateway. Amounts are in cents.
+type Payment struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	ReservationID primitive.ObjectID `bson:"reservationId" json:"reservationId" swaggertype:"string"`
+	UserID        string             `bson:"userId" json:"userId"`
+	Gateway       string             `bson:"gateway" json:"gateway"`
+	// The gateway's ID of the authorization
+	AuthorizationID string `bson:"authorizationId" json:"authorizationId"`
+	// The gateway's payment method, used again when the reservation's change needs a new authorization
+	PaymentMethod string `bson:"paymentMethod" json:"paymentMethod"`
+	Currency      string `bson:"currency" json:"currency"`
+	Authorized    int64  `bson:"authorized" json:"authorized"`
+	// The part of the authorization that is the reservation's deposit, only captured on a no-show
+	Deposit  int64 `bson:"deposit" json:"deposit"`
+	Captured int64 `bson:"captured" json:"captured"`
+	Refunded int64 `bson:"refunded" json:"refunded"`
+	// Whether it pays for fees charged after the reservation's own payment was captured
+	Fee bool `bson:"fee,omitempty" json:"fee,omitempty"`
+	// Either "Authorized", "Captured", "Voided", "Refunded" or "Failed"
+	Status string `bson:"status" json:"status"`
+	// When the captured and refunded amounts were taken and given back, used to credit them on invoices
+	CapturedAt time.Time `bson:"capturedAt,omitempty" json:"capturedAt,omitempty"`
+	RefundedAt time.Time `bson:"refundedAt,omitempty" json:"refundedAt,omitempty"`
+	CreatedAt  time.Time `bson:"createdAt" json:"createdAt"`
+	UpdatedAt  time.Time `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Invoice bills a user or an organization for a calendar month. Invoices are never changed once issued. Amounts are in cents.
+type Invoice struct {
+	ID primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	// Numbered sequentially, like "INV-2026-000042"
+	Number string `bson:"number" json:"number"`
+	// Exactly one of them is set
+	UserID         string        `bson:"userId,omitempty" json:"userId,omitempty"`
+	OrganizationID string        `bson:"organizationId,omitempty" json:"organizationId,omitempty"`
+	PeriodStart    time.Time     `bson:"periodStart" json:"periodStart"`
+	PeriodEnd      time.Time     `bson:"periodEnd" json:"periodEnd"`
+	Currency       string        `bson:"currency" json:"currency"`
+	Lines          []InvoiceLine `bson:"lines" json:"lines"`
+	Subtotal       int64         `bson:"subtotal" json:"subtotal"`
+	// Tax rate in percent
+	TaxRate  float64   `bson:"taxRate" json:"taxRate"`
+	Tax      int64     `bson:"tax" json:"tax"`
+	Total    int64     `bson:"total" json:"total"`
+	IssuedAt time.Time `bson:"issuedAt" json:"issuedAt"`
+	// What the user already paid in the period from their wallet or by card, and what was refunded to them as negative amounts. They don't change the total, only what is still due.
+	Payments   []InvoiceLine `bson:"payments,omitempty" json:"payments,omitempty"`
+	AmountPaid int64         `bson:"amountPaid" json:"amountPaid"`
+	BalanceDue int64         `bson:"balanceDue" json:"balanceDue"`
+}
+
+type InvoiceLine struct {
+	Number      int       `bson:"number" json:"number"`
+	Date        time.Time `bson:"date" json:"date"`
+	Description string    `bson:"description" json:"description"`
+	Quantity    float64   `bson:"quantity" json:"quantity"`
+	Unit        string    `bson:"unit" json:"unit"`
+	UnitPrice   int64     `bson:"unitPrice" json:"unitPrice"`
+	Amount      int64     `bson:"amount" json:"amount"`
+	// What the line bills for
+	ReservationID primitive.ObjectID `bson:"reservationId,omitempty" json:"reservationId,omitempty" swaggertype:"string"`
+	SessionID     primitive.ObjectID `bson:"sessionId,omitempty" json:"sessionId,omitempty" swaggertype:"string"`
+}
+
+// WalletReconciliation compares a wallet against the totals of its ledger
+type WalletReconciliation struct {
+	Wallet        Wallet `json:"wallet"`
+	LedgerBalance int64  `json:"ledgerBalance"`
+	LedgerHeld    int64  `json:"ledgerHeld"`
+	Entries       int    `json:"entries"`
+	Consistent    bool   `json:"consistent"`
+}
+
+// WaitlistEntry queues a user for the next free connector of a chargepoint. It's either "Waiting", "Offered" (a connector is held for the user until the offer expires), "Accepted", "Expired" or "Left".
+type WaitlistEntry struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	Chargepoint string             `bson:"chargepoint" json:"chargepoint"`
+	UserID      string             `bson:"userId" json:"userId"`
+	// Optional, only connectors with this plug type are offered
+	PlugType string    `bson:"plugType,omitempty" json:"plugType,omitempty"`
+	Status   string    `bson:"status" json:"status"`
+	JoinedAt time.Time `bson:"joinedAt" json:"joinedAt"`
+	// Set while a connector is offered
+	OfferedConnector int       `bson:"offeredConnector,omitempty" json:"offeredConnector,omitempty"`
+	OfferExpiresAt   time.Time `bson:"offerExpiresAt,omitempty" json:"offerExpiresAt,omitempty"`
+	// Place in the chargepoint's queue while waiting, starting at 1
+	Position int `bson:"-" json:"position,omitempty"`
+}
+
+// ReservationSeries reserves a connector repeatedly, following an iCalendar RRULE. Its occurrences are ordinary reservations, booked ahead as they come within the booking horizon.
+type ReservationSeries struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	UserID      string             `bson:"userId" json:"userId"`
+	Chargepoint string             `bson:"chargepoint" json:"chargepoint"`
+	Connector   int                `bson:"connector" json:"connector"`
+	// For example "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=20"
+	Rule string `bson:"rule" json:"rule"`
+	// When the first occurrence starts, the others start at the same time of day
+	Start         time.Time `bson:"start" json:"start"`
+	Minutes       int       `bson:"minutes" json:"minutes"`
+	VehicleID     string    `bson:"vehicleId,omitempty" json:"vehicleId,omitempty"`
+	PaymentMethod string    `bson:"paymentMethod,omitempty" json:"-"`
+	Cancelled     bool      `bson:"cancelled" json:"cancelled"`
+	CreatedAt     time.Time `bson:"createdAt" json:"createdAt"`
+	// Occurrences starting before this time have been booked
+	GeneratedUntil time.Time          `bson:"generatedUntil" json:"generatedUntil"`
+	Occurrences    []SeriesOccurrence `bson:"occurrences" json:"occurrences"`
+}
+
+// SeriesOccurrence is either booked as a reservation, or has the reason it couldn't be
+type SeriesOccurrence struct {
+	Start         time.Time          `bson:"start" json:"start"`
+	ReservationID primitive.ObjectID `bson:"reservationId,omitempty" json:"reservationId,omitempty" swaggertype:"string"`
+	Error         string             `bson:"error,omitempty" json:"error,omitempty"`
+}
+
+// IdempotencyRecord remembers the response to a request made with an Idempotency-Key header, so a retry gets the same response
+type IdempotencyRecord struct {
+	// Hash of the key with the path and user it was sent for, so clients using the same key don't share responses
+	ID     string `bson:"_id" json:"id"`
+	Key    string `bson:"key" json:"key"`
+	Path   string `bson:"path" json:"path"`
+	UserID string `bson:"userId,omitempty" json:"userId,omitempty"`
+	// Hash of the request's method, path and body, a key can't be reused for another request
+	RequestHash string `bson:"requestHash" json:"requestHash"`
+	// False while the first request is still being handled
+	Completed bool      `bson:"completed" json:"completed"`
+	Status    int       `bson:"status,omitempty" json:"status,omitempty"`
+	Body      []byte    `bson:"body,omitempty" json:"-"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+	ExpiresAt time.Time `bson:"expiresAt" json:"expiresAt"`
+}
+
+// MaintenanceWindow takes a connector, or all of a chargepoint's connectors, out of service for a period
+type MaintenanceWindow struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	Chargepoint string             `bson:"chargepoint" json:"chargepoint"`
+	// 0 for all of the chargepoint's connectors
+	Connector int       `bson:"connector" json:"connector"`
+	Start     time.Time `bson:"start" json:"start"`
+	End       time.Time `bson:"end" json:"end"`
+	Reason    string    `bson:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// ConnectorAvailability lists the periods a connector is free during a searched time window
+type ConnectorAvailability struct {
+	Chargepoint string  `json:"chargepoint"`
+	SiteID      string  `json:"siteId,omitempty"`
+	Connector   int     `json:"connector"`
+	State       string  `json:"state"`
+	PlugType    string  `json:"plugType,omitempty"`
+	CurrentType string  `json:"currentType,omitempty"`
+	MaxPower    float64 `json:"maxPower,omitempty"`
+	// Time kept free before and after the connector's reservations, in minutes
+	BufferMinutes int `json:"bufferMinutes,omitempty"`
+	// From the searched location, in km
+	Distance float64    `json:"distance,omitempty"`
+	Slots    []TimeSlot `json:"slots"`
+}
+
+// TimeSlot is a period a connector is free for
+type TimeSlot struct {
+	Start   time.Time `json:"start"`
+	End     time.Time `json:"end"`
+	Minutes int       `json:"minutes"`
+}
+
+// Notification tells a user about something that happened to one of their reservations
+type Notification struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	UserID        string             `bson:"userId" json:"userId"`
+	ReservationID primitive.ObjectID `bson:"reservationId,omitempty" json:"reservationId,omitempty" swaggertype:"string"`
+	// Either "LateSession" or "Bumped"
+	Type      string    `bson:"type" json:"type"`
+	Message   string    `bson:"message" json:"message"`
+	CreatedAt time.Time `bson:"createdAt" json:"createdAt"`
+}
+
+// Compensation is owed to a user whose reservation was bumped by a priority user, in cents
+type Compensation struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	UserID        string             `bson:"userId" json:"userId"`
+	ReservationID primitive.ObjectID `bson:"reservationId" json:"reservationId" swaggertype:"string"`
+	// The priority reservation that took its place
+	BumpedBy      primitive.ObjectID `bson:"bumpedBy" json:"bumpedBy" swaggertype:"string"`
+	PriorityClass string             `bson:"priorityClass" json:"priorityClass"`
+	Amount        int64              `bson:"amount" json:"amount"`
+	Currency      string             `bson:"currency" json:"currency"`
+	CreatedAt     time.Time          `bson:"createdAt" json:"createdAt"`
+}
+
+// NoShow is recorded whenever a reservation expires without the user ever starting to charge
+type NoShow struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	UserID         string             `bson:"userId" json:"userId"`
+	ReservationID  primitive.ObjectID `bson:"reservationId" json:"reservationId" swaggertype:"string"`
+	Chargepoint    string             `bson:"chargepoint" json:"chargepoint"`
+	Connector      int                `bson:"connector" json:"connector"`
+	Time           time.Time          `bson:"time" json:"time"`
+	Forgiven       bool               `bson:"forgiven" json:"forgiven"`
+	ForgivenReason string             `bson:"forgivenReason,omitempty" json:"forgivenReason,omitempty"`
+}
+
+// Fee is a billable penalty applied by the background worker, either "NoShow" when a reservation expires unused or "Idle" for every minute a vehicle stays plugged in after its charging time
+type Fee struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string"`
+	Type           string             `bson:"type" json:"type"`
+	UserID         string             `bson:"userId" json:"userId"`
+	OrganizationID string             `bson:"organizationId,omitempty" json:"organizationId,omitempty"`
+	ReservationID  primitive.ObjectID `bson:"reservationId" json:"reservationId" swaggertype:"string"`
+	SessionID      primitive.ObjectID `bson:"sessionId,omitempty" json:"sessionId,omitempty" swaggertype:"string"`
+	Chargepoint    string             `bson:"chargepoint" json:"chargepoint"`
+	Connector      int                `bson:"connector" json:"connector"`
+	Quantity       float64            `bson:"quantity" json:"quantity"`
+	Unit           string             `bson:"unit" json:"unit"`
+	UnitPrice      int64              `bson:"unitPrice" json:"unitPrice"`
+	Amount         int64              `bson:"amount" json:"amount"`
+	Currency       string             `bson:"currency" json:"currency"`
+	Time           time.Time          `bson:"time" json:"time"`
+}
+
+// Reliability summarizes a user's no-show history and the penalty currently applied to them
+type Reliability struct {
+	UserID string `json:"userId"`
+	// Percentage of finished reservations the user actually charged on
+	Score        float64    `json:"score"`
+	Reservations int        `json:"reservations"`
+	NoShows      int        `json:"noShows"`
+	Penalty      string     `json:"penalty"`
+	BannedUntil  *time.Time `json:"bannedUntil,omitempty"`
+	Deposit      int64      `json:"deposit,omitempty"`
 }
 
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+// LimitErrorResponse is returned when a request is refused because of a configured limit. Code identifies which limit was hit, so clients don't have to parse the error message.
+type LimitErrorResponse struct {
+	Error      string     `json:"error"`
+	Code       string     `json:"code"`
+	Limit      int        `json:"limit"`
+	Current    int        `json:"current"`
+	RetryAfter *time.Time `json:"retryAfter,omitempty"`
+}
+
 type MessageResponse struct {
-	Message string `json:"message"`
+	Message  string   `json:"message"`
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// ReservationResponse is returned when a reservation is made, with the connector that was reserved when the server picked it
+type ReservationResponse struct {
+	Message       string             `json:"message"`
+	Warnings      []string           `json:"warnings,omitempty"`
+	ReservationID primitive.ObjectID `json:"reservationId" swaggertype:"string"`
+	// Booking code to give to the customer
+	Code        string `json:"code"`
+	Chargepoint string `json:"chargepoint"`
+	Connector   int    `json:"connector"`
 }
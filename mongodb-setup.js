@@ -5,4 +5,39 @@ function setupCollections(database) {
     database.createCollection("users");
     database.createCollection("chargepoints");
     database.createCollection("reservations");
+    database.createCollection("noshows");
+    database.createCollection("vehicles");
+    database.createCollection("organizations");
+    database.createCollection("sites");
+    database.createCollection("tariffs");
+    database.createCollection("sessions");
+    database.createCollection("metervalues");
+    database.createCollection("wallets");
+    database.createCollection("ledger");
+    database.createCollection("payments");
+    database.createCollection("paymentevents");
+    database.createCollection("invoices");
+    database.createCollection("counters");
+    database.createCollection("fees");
+    database.createCollection("quotes");
+    database.createCollection("waitlist");
+    database.createCollection("maintenance");
+    database.createCollection("series");
+    database.createCollection("idempotency");
+    database.createCollection("notifications");
+    database.createCollection("priorityclasses");
+    database.createCollection("compensations");
+
+    // The API creates these indexes on start as well, see CreateIndexes in endpoints/collections.go
+
+    // Invoices are numbered uniquely, and a user or organization is invoiced once per month
+    database.invoices.createIndex({ number: 1 }, { unique: true });
+    database.invoices.createIndex({ userId: 1, organizationId: 1, periodStart: 1 }, { unique: true });
+
+    // Booking codes are given to customers, so no two reservations can share one
+    database.reservations.createIndex({ code: 1 }, { unique: true, sparse: true });
+    database.reservations.createIndex({ legacyId: 1 }, { sparse: true });
+
+    // Idempotency keys are forgotten once they expire
+    database.idempotency.createIndex({ expiresAt: 1 }, { expireAfterSeconds: 0 });
 }
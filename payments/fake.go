@@ -0,0 +1,142 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// DeclinedPaymentMethod is declined by the fake gateway, every other payment method is accepted
+const DeclinedPaymentMethod = "pm_card_declined"
+
+// FakeGateway keeps payments in memory, for tests and local development. Its webhooks are JSON encoded events signed with an HMAC-SHA256 of the secret.
+type FakeGateway struct {
+	Secret string
+
+	mutex    sync.Mutex
+	payments map[string]*fakePayment
+	// Authorization IDs by reference
+	references map[string]string
+}
+
+type fakePayment struct {
+	authorized int64
+	captured   int64
+	refunded   int64
+	voided     bool
+}
+
+func NewFakeGateway(secret string) *FakeGateway {
+	return &FakeGateway{Secret: secret, payments: map[string]*fakePayment{}, references: map[string]string{}}
+}
+
+func (g *FakeGateway) Name() string {
+	return "fake"
+}
+
+func (g *FakeGateway) Authorize(amount int64, currency, paymentMethod, reference string) (Authorization, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	if id, ok := g.references[reference]; ok {
+		return Authorization{ID: id, Amount: g.payments[id].authorized}, nil
+	}
+
+	if paymentMethod == DeclinedPaymentMethod {
+		return Authorization{}, ErrDeclined
+	}
+
+	id := fmt.Sprintf("fake_%d", len(g.payments)+1)
+	g.payments[id] = &fakePayment{authorized: amount}
+	g.references[reference] = id
+
+	return Authorization{ID: id, Amount: amount}, nil
+}
+
+func (g *FakeGateway) find(authorizationID string) (*fakePayment, error) {
+	payment, ok := g.payments[authorizationID]
+	if !ok {
+		return nil, fmt.Errorf("authorization %s does not exist", authorizationID)
+	}
+
+	return payment, nil
+}
+
+func (g *FakeGateway) Capture(authorizationID string, amount int64) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	payment, err := g.find(authorizationID)
+	if err != nil {
+		return err
+	}
+
+	if payment.voided || payment.captured > 0 || amount > payment.authorized {
+		return fmt.Errorf("authorization %s can't be captured for %d", authorizationID, amount)
+	}
+
+	payment.captured = amount
+	return nil
+}
+
+func (g *FakeGateway) Void(authorizationID string) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	payment, err := g.find(authorizationID)
+	if err != nil {
+		return err
+	}
+
+	if payment.captured > 0 {
+		return fmt.Errorf("authorization %s was already captured", authorizationID)
+	}
+
+	payment.voided = true
+	return nil
+}
+
+func (g *FakeGateway) Refund(authorizationID string, amount int64) error {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	payment, err := g.find(authorizationID)
+	if err != nil {
+		return err
+	}
+
+	if payment.refunded+amount > payment.captured {
+		return fmt.Errorf("authorization %s can't be refunded for more than was captured", authorizationID)
+	}
+
+	payment.refunded += amount
+	return nil
+}
+
+// Sign returns the signature the fake gateway expects for the payload
+func (g *FakeGateway) Sign(payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(g.Secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (g *FakeGateway) VerifyWebhook(payload []byte, signature string) (Event, error) {
+	if !hmac.Equal([]byte(g.Sign(payload)), []byte(signature)) {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var event Event
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return Event{}, err
+	}
+
+	switch event.Type {
+	case EventCaptured, EventVoided, EventRefunded, EventFailed:
+		return event, nil
+	default:
+		return Event{}, ErrUnknownEvent
+	}
+}
@@ -0,0 +1,46 @@
+package payments
+
+import "errors"
+
+// Gateway is a card payment provider. Amounts are in cents.
+type Gateway interface {
+	Name() string
+	// Authorize reserves the amount on the payment method without charging it. The reference identifies what is paid for and keeps retries from authorizing twice.
+	Authorize(amount int64, currency, paymentMethod, reference string) (Authorization, error)
+	// Capture charges up to the authorized amount
+	Capture(authorizationID string, amount int64) error
+	// Void releases an authorization that was never captured
+	Void(authorizationID string) error
+	// Refund gives back up to the captured amount
+	Refund(authorizationID string, amount int64) error
+	// VerifyWebhook checks the signature of a webhook callback and parses its event
+	VerifyWebhook(payload []byte, signature string) (Event, error)
+}
+
+type Authorization struct {
+	ID     string
+	Amount int64
+}
+
+// Event types, normalized across providers
+const (
+	EventCaptured = "Captured"
+	EventVoided   = "Voided"
+	EventRefunded = "Refunded"
+	EventFailed   = "Failed"
+)
+
+// Event is a webhook callback about an authorization
+type Event struct {
+	// The provider's event ID, the same event can be delivered more than once
+	ID              string
+	Type            string
+	AuthorizationID string
+	Amount          int64
+}
+
+var (
+	ErrDeclined         = errors.New("the payment method was declined")
+	ErrInvalidSignature = errors.New("the webhook signature is invalid")
+	ErrUnknownEvent     = errors.New("the webhook event type is not handled")
+)
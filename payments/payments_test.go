@@ -0,0 +1,143 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFakeGateway(t *testing.T) {
+	gateway := NewFakeGateway("secret")
+
+	if _, err := gateway.Authorize(1000, "EUR", DeclinedPaymentMethod, "declined"); err != ErrDeclined {
+		t.Errorf("Expected the payment method to be declined, but received %v", err)
+	}
+
+	authorization, err := gateway.Authorize(1000, "EUR", "pm_card_visa", "reservation-1")
+	if err != nil {
+		t.Fatalf("Could not authorize:\n%v", err)
+	}
+
+	retried, _ := gateway.Authorize(1000, "EUR", "pm_card_visa", "reservation-1")
+	if retried.ID != authorization.ID {
+		t.Errorf("Expected a retry to return authorization %s, but received %s", authorization.ID, retried.ID)
+	}
+
+	if err := gateway.Capture(authorization.ID, 1200); err == nil {
+		t.Errorf("Expected capturing more than was authorized to fail")
+	}
+	if err := gateway.Capture(authorization.ID, 800); err != nil {
+		t.Errorf("Could not capture:\n%v", err)
+	}
+	if err := gateway.Refund(authorization.ID, 900); err == nil {
+		t.Errorf("Expected refunding more than was captured to fail")
+	}
+	if err := gateway.Refund(authorization.ID, 800); err != nil {
+		t.Errorf("Could not refund:\n%v", err)
+	}
+
+	payload := []byte(`{"ID":"evt_1","Type":"Captured","AuthorizationID":"fake_1","Amount":800}`)
+	if _, err := gateway.VerifyWebhook(payload, "forged"); err != ErrInvalidSignature {
+		t.Errorf("Expected a forged signature to be rejected, but received %v", err)
+	}
+
+	event, err := gateway.VerifyWebhook(payload, gateway.Sign(payload))
+	if err != nil || event.Type != EventCaptured || event.AuthorizationID != "fake_1" {
+		t.Errorf("Expected a captured event of fake_1, but received %v (%v)", event, err)
+	}
+}
+
+// stripeStub answers like the parts of Stripe's API the adapter uses
+func stripeStub(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer sk_test" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		r.ParseForm()
+
+		switch {
+		case r.URL.Path == "/v1/payment_intents" && r.Form.Get("payment_method") == "pm_card_chargeDeclined":
+			w.WriteHeader(http.StatusPaymentRequired)
+			fmt.Fprint(w, `{"error":{"code":"card_declined","message":"Your card was declined."}}`)
+		case r.URL.Path == "/v1/payment_intents":
+			if r.Form.Get("capture_method") != "manual" || r.Header.Get("Idempotency-Key") == "" {
+				t.Errorf("Expected a manually captured payment intent with an idempotency key, but received %v", r.Form)
+			}
+			fmt.Fprintf(w, `{"id":"pi_1","status":"requires_capture","amount":%s}`, r.Form.Get("amount"))
+		case r.URL.Path == "/v1/payment_intents/pi_1/capture":
+			fmt.Fprint(w, `{"id":"pi_1","status":"succeeded"}`)
+		case r.URL.Path == "/v1/payment_intents/pi_1/cancel":
+			fmt.Fprint(w, `{"id":"pi_1","status":"canceled"}`)
+		case r.URL.Path == "/v1/refunds" && r.Form.Get("payment_intent") == "pi_1":
+			fmt.Fprint(w, `{"id":"re_1","status":"succeeded"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":{"code":"resource_missing","message":"No such resource"}}`)
+		}
+	}))
+}
+
+func TestStripeGateway(t *testing.T) {
+	server := stripeStub(t)
+	defer server.Close()
+
+	gateway := NewStripeGateway(server.URL, "sk_test", "whsec_test")
+
+	if _, err := gateway.Authorize(1000, "EUR", "pm_card_chargeDeclined", "reservation-1"); err != ErrDeclined {
+		t.Errorf("Expected the card to be declined, but received %v", err)
+	}
+
+	authorization, err := gateway.Authorize(1000, "EUR", "pm_card_visa", "reservation-2")
+	if err != nil || authorization.ID != "pi_1" || authorization.Amount != 1000 {
+		t.Fatalf("Expected pi_1 to be authorized for 1000, but received %v (%v)", authorization, err)
+	}
+
+	if err := gateway.Capture("pi_1", 800); err != nil {
+		t.Errorf("Could not capture:\n%v", err)
+	}
+	if err := gateway.Refund("pi_1", 800); err != nil {
+		t.Errorf("Could not refund:\n%v", err)
+	}
+	if err := gateway.Void("pi_unknown"); err == nil {
+		t.Errorf("Expected voiding an unknown payment intent to fail")
+	}
+
+	sign := func(timestamp int64, payload string) string {
+		mac := hmac.New(sha256.New, []byte("whsec_test"))
+		mac.Write([]byte(fmt.Sprintf("%d.%s", timestamp, payload)))
+		return fmt.Sprintf("t=%d,v1=%s", timestamp, hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	payload := `{"id":"evt_1","type":"charge.refunded","data":{"object":{"id":"ch_1","payment_intent":"pi_1","amount_refunded":800}}}`
+
+	tests := []struct {
+		name      string
+		signature string
+		err       error
+	}{
+		{name: "Valid", signature: sign(time.Now().Unix(), payload)},
+		{name: "Forged", signature: sign(time.Now().Unix(), strings.Replace(payload, "800", "8000", 1)), err: ErrInvalidSignature},
+		{name: "Expired", signature: sign(time.Now().Add(-time.Hour).Unix(), payload), err: ErrInvalidSignature},
+		{name: "Missing", signature: "", err: ErrInvalidSignature},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event, err := gateway.VerifyWebhook([]byte(payload), test.signature)
+			if err != test.err {
+				t.Fatalf("Expected error %v, but received %v", test.err, err)
+			}
+
+			if err == nil && (event.Type != EventRefunded || event.AuthorizationID != "pi_1" || event.Amount != 800) {
+				t.Errorf("Expected a refund of 800 on pi_1, but received %v", event)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StripeGateway authorizes payments as Stripe PaymentIntents with manual capture. BaseURL can point at a local stub server instead of https://api.stripe.com.
+type StripeGateway struct {
+	BaseURL       string
+	SecretKey     string
+	WebhookSecret string
+	// How old a webhook's signature timestamp can be
+	WebhookTolerance time.Duration
+	Client           *http.Client
+}
+
+func NewStripeGateway(baseURL, secretKey, webhookSecret string) *StripeGateway {
+	if baseURL == "" {
+		baseURL = "https://api.stripe.com"
+	}
+
+	return &StripeGateway{
+		BaseURL:          strings.TrimSuffix(baseURL, "/"),
+		SecretKey:        secretKey,
+		WebhookSecret:    webhookSecret,
+		WebhookTolerance: 5 * time.Minute,
+		Client:           &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (g *StripeGateway) Name() string {
+	return "stripe"
+}
+
+type stripeObject struct {
+	ID             string `json:"id"`
+	Status         string `json:"status"`
+	Amount         int64  `json:"amount"`
+	AmountReceived int64  `json:"amount_received"`
+	AmountRefunded int64  `json:"amount_refunded"`
+	PaymentIntent  string `json:"payment_intent"`
+}
+
+type stripeError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// post sends a form encoded request, Stripe returns the affected object or an error
+func (g *StripeGateway) post(path string, form url.Values, idempotencyKey string) (stripeObject, error) {
+	req, err := http.NewRequest(http.MethodPost, g.BaseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return stripeObject{}, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+g.SecretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	res, err := g.Client.Do(req)
+	if err != nil {
+		return stripeObject{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		var body stripeError
+		json.NewDecoder(res.Body).Decode(&body)
+
+		if res.StatusCode == http.StatusPaymentRequired || body.Error.Code == "card_declined" {
+			return stripeObject{}, ErrDeclined
+		}
+		return stripeObject{}, fmt.Errorf("stripe responded with %d: %s", res.StatusCode, body.Error.Message)
+	}
+
+	var object stripeObject
+	err = json.NewDecoder(res.Body).Decode(&object)
+	return object, err
+}
+
+func (g *StripeGateway) Authorize(amount int64, currency, paymentMethod, reference string) (Authorization, error) {
+	form := url.Values{
+		"amount":              {strconv.FormatInt(amount, 10)},
+		"currency":            {strings.ToLower(currency)},
+		"payment_method":      {paymentMethod},
+		"capture_method":      {"manual"},
+		"confirm":             {"true"},
+		"metadata[reference]": {reference},
+	}
+
+	intent, err := g.post("/v1/payment_intents", form, "authorize-"+reference)
+	if err != nil {
+		return Authorization{}, err
+	}
+
+	if intent.Status != "requires_capture" {
+		return Authorization{}, ErrDeclined
+	}
+
+	return Authorization{ID: intent.ID, Amount: intent.Amount}, nil
+}
+
+func (g *StripeGateway) Capture(authorizationID string, amount int64) error {
+	_, err := g.post("/v1/payment_intents/"+authorizationID+"/capture", url.Values{"amount_to_capture": {strconv.FormatInt(amount, 10)}}, "capture-"+authorizationID)
+	return err
+}
+
+func (g *StripeGateway) Void(authorizationID string) error {
+	_, err := g.post("/v1/payment_intents/"+authorizationID+"/cancel", url.Values{}, "cancel-"+authorizationID)
+	return err
+}
+
+func (g *StripeGateway) Refund(authorizationID string, amount int64) error {
+	form := url.Values{"payment_intent": {authorizationID}, "amount": {strconv.FormatInt(amount, 10)}}
+	_, err := g.post("/v1/refunds", form, "refund-"+authorizationID)
+	return err
+}
+
+// VerifyWebhook checks a Stripe-Signature header ("t=timestamp,v1=signature"), where the signature is an HMAC-SHA256 of "timestamp.payload"
+func (g *StripeGateway) VerifyWebhook(payload []byte, signature string) (Event, error) {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(seconds, 0)) > g.WebhookTolerance {
+		return Event{}, ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(g.WebhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(payload)
+	expected := hex.EncodeToString(mac.Sum(nil))
+
+	valid := false
+	for _, s := range signatures {
+		if hmac.Equal([]byte(s), []byte(expected)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return Event{}, ErrInvalidSignature
+	}
+
+	var body struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object stripeObject `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return Event{}, err
+	}
+
+	object := body.Data.Object
+	event := Event{ID: body.ID, AuthorizationID: object.ID}
+
+	switch body.Type {
+	case "payment_intent.succeeded":
+		event.Type, event.Amount = EventCaptured, object.AmountReceived
+	case "payment_intent.canceled":
+		event.Type = EventVoided
+	case "payment_intent.payment_failed":
+		event.Type = EventFailed
+	case "charge.refunded":
+		event.Type, event.AuthorizationID, event.Amount = EventRefunded, object.PaymentIntent, object.AmountRefunded
+	default:
+		return Event{}, ErrUnknownEvent
+	}
+
+	return event, nil
+}
@@ -0,0 +1,166 @@
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported frequencies
+const (
+	Daily  = "DAILY"
+	Weekly = "WEEKLY"
+)
+
+// Occurrences are generated up to this many days after the first one when a rule has no end
+const maxSpanDays = 5 * 365
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Rule is a subset of an iCalendar RRULE: a daily or weekly frequency with an interval, the days of the week and an end date or count
+type Rule struct {
+	Frequency string
+	// Every how many days or weeks, at least 1
+	Interval int
+	// Daily rules only repeat on these days, weekly rules repeat on each of them. Weekly rules without days repeat on the day of the first occurrence.
+	ByDay []time.Weekday
+	// The last day occurrences can start on, zero when the rule doesn't end on a date
+	Until time.Time
+	// How many occurrences there are, 0 when the rule doesn't end after a count
+	Count int
+}
+
+// Parse reads a rule like "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=10", with or without the "RRULE:" prefix. UNTIL is a date (20230630) or a UTC time (20230630T235959Z).
+func Parse(rrule string) (Rule, error) {
+	rule := Rule{Interval: 1}
+
+	for _, part := range strings.Split(strings.TrimPrefix(strings.TrimSpace(rrule), "RRULE:"), ";") {
+		if part == "" {
+			continue
+		}
+
+		key, value, found := strings.Cut(part, "=")
+		if !found {
+			return Rule{}, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			rule.Frequency = strings.ToUpper(value)
+		case "INTERVAL":
+			interval, err := strconv.Atoi(value)
+			if err != nil || interval < 1 {
+				return Rule{}, fmt.Errorf("invalid interval %q", value)
+			}
+			rule.Interval = interval
+		case "BYDAY":
+			for _, day := range strings.Split(value, ",") {
+				weekday, ok := weekdays[strings.ToUpper(day)]
+				if !ok {
+					return Rule{}, fmt.Errorf("invalid day %q", day)
+				}
+				rule.ByDay = append(rule.ByDay, weekday)
+			}
+		case "UNTIL":
+			until, err := time.ParseInLocation("20060102", value, time.Local)
+			if err == nil {
+				until = until.Add(24*time.Hour - time.Second)
+			} else {
+				until, err = time.Parse("20060102T150405Z", value)
+			}
+			if err != nil {
+				return Rule{}, fmt.Errorf("invalid end date %q", value)
+			}
+			rule.Until = until
+		case "COUNT":
+			count, err := strconv.Atoi(value)
+			if err != nil || count < 1 {
+				return Rule{}, fmt.Errorf("invalid count %q", value)
+			}
+			rule.Count = count
+		default:
+			return Rule{}, fmt.Errorf("unsupported rule part %q", key)
+		}
+	}
+
+	if rule.Frequency != Daily && rule.Frequency != Weekly {
+		return Rule{}, errors.New("the frequency must be either DAILY or WEEKLY")
+	}
+
+	if !rule.Until.IsZero() && rule.Count > 0 {
+		return Rule{}, errors.New("a rule can't end both on a date and after a count")
+	}
+
+	return rule, nil
+}
+
+// repeatsOn tells whether the rule repeats on the day, given the first occurrence
+func (r Rule) repeatsOn(day, first time.Time) bool {
+	days := int(day.Sub(first).Hours()/24 + 0.5)
+
+	if len(r.ByDay) > 0 {
+		matches := false
+		for _, weekday := range r.ByDay {
+			if day.Weekday() == weekday {
+				matches = true
+			}
+		}
+		if !matches {
+			return false
+		}
+	} else if r.Frequency == Weekly && day.Weekday() != first.Weekday() {
+		return false
+	}
+
+	if r.Frequency == Daily {
+		return days%r.Interval == 0
+	}
+
+	// Weeks start on Monday, and are counted from the first occurrence's week
+	weekStart := first.AddDate(0, 0, -(int(first.Weekday())+6)%7)
+	weeks := int(day.Sub(weekStart).Hours()/24+0.5) / 7
+	return weeks%r.Interval == 0
+}
+
+// Between returns the start times of the occurrences from the first one on that start in [from, to). The first occurrence is always included when it's in the window.
+func (r Rule) Between(first, from, to time.Time) []time.Time {
+	starts := []time.Time{}
+	if r.Interval < 1 {
+		r.Interval = 1
+	}
+
+	count := 0
+	for day := first; day.Before(to); day = day.AddDate(0, 0, 1) {
+		if !r.Until.IsZero() && day.After(r.Until) {
+			break
+		}
+		if day.After(first.AddDate(0, 0, maxSpanDays)) {
+			break
+		}
+
+		if !day.Equal(first) && !r.repeatsOn(day, first) {
+			continue
+		}
+
+		count++
+		if r.Count > 0 && count > r.Count {
+			break
+		}
+
+		if !day.Before(from) {
+			starts = append(starts, day)
+		}
+	}
+
+	return starts
+}
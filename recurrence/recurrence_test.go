@@ -0,0 +1,69 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		rrule string
+		valid bool
+	}{
+		{name: "Weekdays", rrule: "RRULE:FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=10", valid: true},
+		{name: "Until", rrule: "FREQ=DAILY;INTERVAL=2;UNTIL=20230630", valid: true},
+		{name: "Monthly", rrule: "FREQ=MONTHLY", valid: false},
+		{name: "UnknownDay", rrule: "FREQ=WEEKLY;BYDAY=XX", valid: false},
+		{name: "UntilAndCount", rrule: "FREQ=DAILY;UNTIL=20230630;COUNT=3", valid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Parse(test.rrule)
+			if (err == nil) != test.valid {
+				t.Errorf("Expected the rule to be valid: %t, but received %v", test.valid, err)
+			}
+		})
+	}
+}
+
+func TestBetween(t *testing.T) {
+	// A Thursday morning
+	first := time.Date(2023, 6, 1, 8, 0, 0, 0, time.Local)
+	day := func(days int) time.Time { return first.AddDate(0, 0, days) }
+	end := day(30)
+
+	tests := []struct {
+		name   string
+		rrule  string
+		from   time.Time
+		to     time.Time
+		starts []time.Time
+	}{
+		{name: "Weekdays", rrule: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR;COUNT=4", from: first, to: end, starts: []time.Time{day(0), day(1), day(4), day(5)}},
+		{name: "EveryOtherDay", rrule: "FREQ=DAILY;INTERVAL=2;UNTIL=20230606", from: first, to: end, starts: []time.Time{day(0), day(2), day(4)}},
+		{name: "EveryOtherWeek", rrule: "FREQ=WEEKLY;INTERVAL=2;COUNT=3", from: first, to: end, starts: []time.Time{day(0), day(14), day(28)}},
+		{name: "Window", rrule: "FREQ=DAILY;COUNT=5", from: day(3), to: day(10), starts: []time.Time{day(3), day(4)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rule, err := Parse(test.rrule)
+			if err != nil {
+				t.Fatalf("Could not parse the rule:\n%v", err)
+			}
+
+			starts := rule.Between(first, test.from, test.to)
+			if len(starts) != len(test.starts) {
+				t.Fatalf("Expected %d occurrences, but received %v", len(test.starts), starts)
+			}
+
+			for i := range starts {
+				if !starts[i].Equal(test.starts[i]) {
+					t.Errorf("Expected occurrence %d to start at %v, but received %v", i, test.starts[i], starts[i])
+				}
+			}
+		})
+	}
+}